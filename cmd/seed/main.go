@@ -28,18 +28,22 @@ func main() {
 
 	// ==================== CATEGORIES ====================
 	// Based on screenshots: AI, Игры (Games), Трейдинг (Trading), DePIN, DeFi, NFT, Стейкинг (Staking), Сервисы (Services)
+	ru := func(name string) []models.CategoryTranslation {
+		return []models.CategoryTranslation{{Language: "ru", Name: name}}
+	}
 	categories := []models.Category{
 		{Name: "AI", Slug: "ai", Description: "AI-powered applications", Icon: "🤖", Order: 1},
-		{Name: "Games", Slug: "games", Description: "Play-to-earn games and entertainment", Icon: "🎮", Order: 2},
-		{Name: "Trading", Slug: "trading", Description: "Trading and market analysis tools", Icon: "📊", Order: 3},
+		{Name: "Games", Slug: "games", Description: "Play-to-earn games and entertainment", Icon: "🎮", Order: 2, Translations: ru("Игры")},
+		{Name: "Trading", Slug: "trading", Description: "Trading and market analysis tools", Icon: "📊", Order: 3, Translations: ru("Трейдинг")},
 		{Name: "DePIN", Slug: "depin", Description: "Decentralized Physical Infrastructure", Icon: "🌐", Order: 4},
 		{Name: "DeFi", Slug: "defi", Description: "Decentralized Finance applications", Icon: "💎", Order: 5},
 		{Name: "NFT", Slug: "nft", Description: "NFT marketplaces and collections", Icon: "🖼️", Order: 6},
-		{Name: "Staking", Slug: "staking", Description: "Staking and yield farming", Icon: "🔒", Order: 7},
-		{Name: "Services", Slug: "services", Description: "Various utility services", Icon: "🛠️", Order: 8},
+		{Name: "Staking", Slug: "staking", Description: "Staking and yield farming", Icon: "🔒", Order: 7, Translations: ru("Стейкинг")},
+		{Name: "Services", Slug: "services", Description: "Various utility services", Icon: "🛠️", Order: 8, Translations: ru("Сервисы")},
 	}
 
 	for _, category := range categories {
+		// Translations are only created together with a new category
 		db.FirstOrCreate(&category, models.Category{Slug: category.Slug})
 	}
 	log.Println("✓ Categories seeded")
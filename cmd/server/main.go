@@ -6,6 +6,7 @@ import (
 
 	"github.com/fasad/solanafon-back/internal/config"
 	"github.com/fasad/solanafon-back/internal/database"
+	"github.com/fasad/solanafon-back/internal/jobs"
 	"github.com/fasad/solanafon-back/internal/routes"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -34,6 +35,9 @@ func main() {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	// Background jobs (analytics rollups)
+	jobs.Start(db, cfg)
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName: "Solafon API v1.0",
@@ -59,6 +63,9 @@ func main() {
 	// Serve uploaded files
 	app.Static("/uploads", cfg.UploadDir)
 
+	// Share link landing pages (/s/:code)
+	routes.SetupLinks(app, db, cfg)
+
 	// Setup v1 routes (legacy)
 	v1 := app.Group("/api/v1")
 	routes.Setup(v1, db, cfg)
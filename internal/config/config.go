@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -20,6 +21,15 @@ type Config struct {
 	SolanaRPCURL       string
 	UploadDir          string
 	BaseURL            string
+	AdminEmails        []string
+	PlatformFeePercent int
+	EarningsHoldDays   int
+	MinPayoutAmount    int
+	JobsEnabled        bool
+	DomainResolver     string // "dns", or "local" for the file-backed stand-in
+	DomainRecordsFile  string
+	BannedTerms        []string // Terms that get a submitted listing rejected automatically
+	FCMCredentialsFile string   // Firebase service account key; push notifications are sent only when set
 }
 
 func Load() *Config {
@@ -27,6 +37,9 @@ func Load() *Config {
 	otpExpiry, _ := strconv.Atoi(getEnv("OTP_EXPIRY_MINUTES", "10"))
 	rateLimitReqs, _ := strconv.Atoi(getEnv("RATE_LIMIT_REQUESTS", "100"))
 	rateLimitWindow, _ := strconv.Atoi(getEnv("RATE_LIMIT_WINDOW", "60"))
+	platformFee, _ := strconv.Atoi(getEnv("PLATFORM_FEE_PERCENT", "20"))
+	holdDays, _ := strconv.Atoi(getEnv("EARNINGS_HOLD_DAYS", "7"))
+	minPayout, _ := strconv.Atoi(getEnv("MIN_PAYOUT_MP", "1000"))
 
 	// Support both DATABASE_URL and individual DB_* env vars
 	dbURL := getEnv("DATABASE_URL", "")
@@ -55,6 +68,15 @@ func Load() *Config {
 		SolanaRPCURL:       getEnv("SOLANA_RPC_URL", "https://api.mainnet-beta.solana.com"),
 		UploadDir:          getEnv("STORAGE_PATH", getEnv("UPLOAD_DIR", "./uploads")),
 		BaseURL:            getEnv("APP_URL", getEnv("BASE_URL", "https://api.solafon.com")),
+		AdminEmails:        splitList(getEnv("ADMIN_EMAILS", "")),
+		PlatformFeePercent: platformFee,
+		EarningsHoldDays:   holdDays,
+		MinPayoutAmount:    minPayout,
+		JobsEnabled:        getEnv("JOBS_ENABLED", "true") == "true",
+		DomainResolver:     getEnv("DOMAIN_RESOLVER", "dns"),
+		DomainRecordsFile:  getEnv("DOMAIN_RECORDS_FILE", "./domain-records.json"),
+		BannedTerms:        splitList(getEnv("MODERATION_BANNED_TERMS", "")),
+		FCMCredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
 	}
 }
 
@@ -64,3 +86,13 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,29 @@
+package database
+
+import "gorm.io/gorm"
+
+// auditAppendOnlySQL makes audit_logs reject UPDATE and DELETE, so entries can't
+// be altered through the application even by code that means to
+const auditAppendOnlySQL = `
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END
+$$ LANGUAGE plpgsql`
+
+// SetupAuditLog installs the append-only trigger on audit_logs
+func SetupAuditLog(db *gorm.DB) error {
+	statements := []string{
+		auditAppendOnlySQL,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only
+			BEFORE UPDATE OR DELETE ON audit_logs
+			FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -19,7 +19,7 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		// Users & Auth
 		&models.User{},
 		&models.OTP{},
@@ -29,15 +29,72 @@ func AutoMigrate(db *gorm.DB) error {
 
 		// Apps
 		&models.Category{},
+		&models.CategoryTranslation{},
 		&models.MiniApp{},
 		&models.AppUser{},
 		&models.AppMessage{},
+		&models.AppFavorite{},
 
 		// Bot system
 		&models.BotCommand{},
 		&models.WebhookLog{},
 		&models.ConversationState{},
 
+		// Permissions
+		&models.AppPermissionGrant{},
+
+		// In-app payments
+		&models.Invoice{},
+		&models.BotEvent{},
+
+		// Organizations & app ownership
+		&models.Organization{},
+		&models.OrgMember{},
+		&models.OrgInvite{},
+		&models.AppTransfer{},
+		&models.AppTransferStatusChange{},
+
+		// Developer verification
+		&models.DeveloperVerification{},
+
+		// Developer earnings
+		&models.EarningEntry{},
+		&models.PayoutRequest{},
+		&models.PayoutStatusChange{},
+
+		// App analytics
+		&models.AppLaunch{},
+		&models.AppDailyStats{},
+		&models.AppRetentionCohort{},
+		&models.AppCommandStats{},
+
+		// Reviews
+		&models.AppReview{},
+		&models.AppReviewRevision{},
+		&models.ReviewHelpfulVote{},
+
+		// Abuse reports
+		&models.ContentReport{},
+
+		// Audit log
+		&models.AuditLog{},
+
+		// Recommendations
+		&models.AppBlock{},
+		&models.AppRecommendation{},
+
+		// Releases & moderation
+		&models.AppRelease{},
+		&models.ModerationAction{},
+
+		// Listing assets
+		&models.Upload{},
+		&models.Tag{},
+
+		// Share links
+		&models.ShareLink{},
+		&models.ShareClick{},
+
 		// Secret Login
 		&models.SecretNumber{},
 		&models.SecretAccess{},
@@ -73,5 +130,31 @@ func AutoMigrate(db *gorm.DB) error {
 		// Mana Points
 		&models.ManaPointTariff{},
 		&models.WalletNetwork{},
-	)
+	); err != nil {
+		return err
+	}
+	if err := migrateReviewReports(db); err != nil {
+		return err
+	}
+	if err := SetupAuditLog(db); err != nil {
+		return err
+	}
+
+	return SetupSearch(db)
+}
+
+// migrateReviewReports moves reports from the retired review_reports table into
+// content_reports, then drops it
+func migrateReviewReports(db *gorm.DB) error {
+	if !db.Migrator().HasTable("review_reports") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO content_reports (target_type, target_id, reporter_id, reason, details, status, created_at)
+			SELECT ?, review_id, reporter_id, reason, details, ?, created_at FROM review_reports
+			ON CONFLICT DO NOTHING`, models.ReportTargetReview, models.ReportOpen).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable("review_reports")
+	})
 }
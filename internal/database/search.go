@@ -0,0 +1,62 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// searchVectorSQL builds mini_apps.search_vector: title (A), subtitle and tags (B),
+// description (C). Text is indexed with the english and russian configs so both
+// languages get stemming; title and tags also go through 'simple' for exact tokens.
+const searchVectorSQL = `
+CREATE OR REPLACE FUNCTION mini_apps_search_vector() RETURNS trigger AS $$
+DECLARE
+	tags_text text;
+BEGIN
+	SELECT coalesce(string_agg(t, ' '), '') INTO tags_text
+	FROM jsonb_array_elements_text(
+		CASE WHEN jsonb_typeof(NEW.tags) = 'array' THEN NEW.tags ELSE '[]'::jsonb END
+	) AS t;
+
+	NEW.search_vector :=
+		setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+		setweight(to_tsvector('russian', coalesce(NEW.title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(NEW.subtitle, '')), 'B') ||
+		setweight(to_tsvector('russian', coalesce(NEW.subtitle, '')), 'B') ||
+		setweight(to_tsvector('simple', tags_text), 'B') ||
+		setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C') ||
+		setweight(to_tsvector('russian', coalesce(NEW.description, '')), 'C');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql`
+
+// SetupSearch creates the full-text search column, its trigger and indexes on mini_apps.
+// pg_trgm is optional: without it the fuzzy (typo-tolerant) fallback is unavailable.
+func SetupSearch(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE mini_apps ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+		searchVectorSQL,
+		`DROP TRIGGER IF EXISTS mini_apps_search_vector_update ON mini_apps`,
+		`CREATE TRIGGER mini_apps_search_vector_update
+			BEFORE INSERT OR UPDATE OF title, subtitle, description, tags ON mini_apps
+			FOR EACH ROW EXECUTE FUNCTION mini_apps_search_vector()`,
+		`CREATE INDEX IF NOT EXISTS idx_mini_apps_search_vector ON mini_apps USING GIN (search_vector)`,
+		// Tag filters in ListApps (tags @> '["games"]')
+		`CREATE INDEX IF NOT EXISTS idx_mini_apps_tags ON mini_apps USING GIN (tags)`,
+		// Backfill rows created before the trigger existed
+		`UPDATE mini_apps SET title = title WHERE search_vector IS NULL`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error; err != nil {
+		log.Println("pg_trgm not available, fuzzy app search disabled:", err)
+		return nil
+	}
+	return db.Exec(`CREATE INDEX IF NOT EXISTS idx_mini_apps_title_trgm ON mini_apps USING GIN (title gin_trgm_ops)`).Error
+}
@@ -0,0 +1,171 @@
+// Package domainproof checks that a developer controls a domain, either through
+// a DNS TXT record or a token file under /.well-known/.
+package domainproof
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fasad/solanafon-back/internal/precheck"
+)
+
+// WellKnownPath is where the token file is served on the developer's domain
+const WellKnownPath = "/.well-known/solafon-verification.txt"
+
+// TXTPrefix starts the TXT record value: "solafon-verification=<token>"
+const TXTPrefix = "solafon-verification="
+
+// ErrNotFound means the domain has no matching record or file
+var ErrNotFound = errors.New("verification token not found")
+
+// ErrUnreachable means the token file couldn't be fetched. The cause is not
+// passed on, so the check can't be used to probe hosts.
+var ErrUnreachable = errors.New("verification file could not be fetched")
+
+// Resolver looks up the records a domain publishes
+type Resolver interface {
+	LookupTXT(ctx context.Context, domain string) ([]string, error)
+	FetchWellKnown(ctx context.Context, domain string) (string, error)
+}
+
+// CheckTXT reports whether domain has a TXT record carrying token
+func CheckTXT(ctx context.Context, r Resolver, domain, token string) error {
+	records, err := r.LookupTXT(ctx, domain)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if strings.TrimSpace(rec) == TXTPrefix+token {
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// CheckWellKnown reports whether domain serves token at WellKnownPath
+func CheckWellKnown(ctx context.Context, r Resolver, domain, token string) error {
+	body, err := r.FetchWellKnown(ctx, domain)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(body) != token {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// New returns the resolver selected by name: "dns" queries the internet,
+// anything else uses the local stand-in backed by recordsFile.
+func New(name, recordsFile string) Resolver {
+	if name == "dns" {
+		return NewNetResolver(NewHTTPClient())
+	}
+	return NewLocalResolver(recordsFile)
+}
+
+// NewHTTPClient returns a client that only connects to public addresses and
+// doesn't follow redirects; the token file must be served at the domain itself
+func NewHTTPClient() *http.Client {
+	client := precheck.NewHTTPClient()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// NetResolver uses the system DNS resolver and HTTPS
+type NetResolver struct {
+	client *http.Client
+}
+
+func NewNetResolver(client *http.Client) *NetResolver {
+	return &NetResolver{client: client}
+}
+
+func (r *NetResolver) LookupTXT(ctx context.Context, domain string) ([]string, error) {
+	records, err := net.DefaultResolver.LookupTXT(ctx, domain)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, ErrNotFound
+	}
+	return records, err
+}
+
+func (r *NetResolver) FetchWellKnown(ctx context.Context, domain string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+domain+WellKnownPath, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", ErrUnreachable
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", ErrNotFound
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", ErrUnreachable
+	}
+	return string(body), nil
+}
+
+// LocalResolver is a stand-in for development and tests. It answers from a JSON
+// file, re-read on every lookup:
+//
+//	{"example.com": {"txt": ["solafon-verification=..."], "wellKnown": "..."}}
+type LocalResolver struct {
+	path string
+}
+
+func NewLocalResolver(path string) *LocalResolver {
+	return &LocalResolver{path: path}
+}
+
+type localRecords struct {
+	TXT       []string `json:"txt"`
+	WellKnown *string  `json:"wellKnown"`
+}
+
+func (r *LocalResolver) load(domain string) (localRecords, error) {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return localRecords{}, ErrNotFound
+	}
+	if err != nil {
+		return localRecords{}, err
+	}
+	var all map[string]localRecords
+	if err := json.Unmarshal(data, &all); err != nil {
+		return localRecords{}, fmt.Errorf("parse %s: %w", r.path, err)
+	}
+	records, ok := all[strings.ToLower(domain)]
+	if !ok {
+		return localRecords{}, ErrNotFound
+	}
+	return records, nil
+}
+
+func (r *LocalResolver) LookupTXT(_ context.Context, domain string) ([]string, error) {
+	records, err := r.load(domain)
+	return records.TXT, err
+}
+
+func (r *LocalResolver) FetchWellKnown(_ context.Context, domain string) (string, error) {
+	records, err := r.load(domain)
+	if err != nil {
+		return "", err
+	}
+	if records.WellKnown == nil {
+		return "", ErrNotFound
+	}
+	return *records.WellKnown, nil
+}
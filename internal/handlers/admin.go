@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AdminHandler handles platform roles and account sanctions. Accounts in
+// ADMIN_EMAILS are admins without a role and can grant it to others.
+type AdminHandler struct {
+	db *gorm.DB
+}
+
+func NewAdminHandler(db *gorm.DB) *AdminHandler {
+	return &AdminHandler{db: db}
+}
+
+// AdminListAdmins — GET /api/admin/admins
+func (h *AdminHandler) AdminListAdmins(c *fiber.Ctx) error {
+	var users []models.User
+	h.db.Where("role = ?", models.UserRoleAdmin).Order("created_at ASC").Find(&users)
+
+	result := make([]fiber.Map, len(users))
+	for i, u := range users {
+		result[i] = fiber.Map{
+			"id": publicid.Format(publicid.User, u.ID), "email": u.Email,
+			"name": u.GetDisplayName(), "role": u.Role,
+		}
+	}
+	return c.JSON(fiber.Map{"success": true, "admins": result})
+}
+
+// AdminSetUserRole — PUT /api/admin/users/:userId/role
+func (h *AdminHandler) AdminSetUserRole(c *fiber.Ctx) error {
+	adminID := c.Locals("userID").(uint)
+	userID, err := paramID(c, "userId", publicid.User)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var input struct {
+		Role string `json:"role"`
+	}
+	if err := c.BodyParser(&input); err != nil || (input.Role != models.UserRoleUser && input.Role != models.UserRoleAdmin) {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "role must be user or admin"}})
+	}
+	if userID == adminID && input.Role != models.UserRoleAdmin {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "CANNOT_DEMOTE_SELF", "message": "Ask another admin to remove your admin role"}})
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "User not found"}})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to load user"}})
+	}
+	before := user.Role
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", input.Role).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditLog{
+			ActorType: models.AuditActorAdmin, Action: models.AuditRoleChanged,
+			TargetType: models.AuditTargetUser, TargetID: user.ID, UserID: &user.ID,
+		}, map[string]interface{}{"role": before}, map[string]interface{}{"role": input.Role})
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update role"}})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "user": fiber.Map{
+		"id": publicid.Format(publicid.User, user.ID), "email": user.Email,
+		"name": user.GetDisplayName(), "role": user.Role,
+	}})
+}
+
+// AdminListSanctionedUsers — GET /api/admin/users/sanctioned?status=suspended|banned
+// Suspensions that already ran out are left out.
+func (h *AdminHandler) AdminListSanctionedUsers(c *fiber.Ctx) error {
+	now := time.Now()
+	query := h.db.Where("status = ? OR (status = ? AND (suspended_until IS NULL OR suspended_until > ?))",
+		models.AccountBanned, models.AccountSuspended, now)
+	switch status := c.Query("status"); status {
+	case "":
+	case models.AccountSuspended, models.AccountBanned:
+		query = query.Where("status = ?", status)
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "status must be suspended or banned"}})
+	}
+
+	var users []models.User
+	query.Order("updated_at DESC").Limit(pageLimit(c)).Find(&users)
+
+	result := make([]fiber.Map, len(users))
+	for i, u := range users {
+		result[i] = formatAccountStatus(u)
+	}
+	return c.JSON(fiber.Map{"success": true, "users": result})
+}
+
+// AdminSanctionUser — POST /api/admin/users/:userId/sanction
+// { "status": "suspended|banned", "reason": "...", "until": "2026-01-31T00:00:00Z", "revokeSessions": true }
+// until is required for a suspension. Sessions are revoked unless revokeSessions
+// is false; either way the account is locked out on its next request.
+func (h *AdminHandler) AdminSanctionUser(c *fiber.Ctx) error {
+	userID, err := paramID(c, "userId", publicid.User)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var input struct {
+		Status         string     `json:"status"`
+		Reason         string     `json:"reason"`
+		Until          *time.Time `json:"until"`
+		RevokeSessions *bool      `json:"revokeSessions"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	switch {
+	case input.Status != models.AccountSuspended && input.Status != models.AccountBanned:
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "status must be suspended or banned"}})
+	case input.Reason == "":
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "reason is required"}})
+	case input.Status == models.AccountSuspended && (input.Until == nil || !input.Until.After(time.Now())):
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "until must be a future time for a suspension"}})
+	case input.Status == models.AccountBanned:
+		input.Until = nil
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "User not found"}})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to load user"}})
+	}
+	revoke := input.RevokeSessions == nil || *input.RevokeSessions
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		return sanctionUser(tx, c, user, input.Status, input.Reason, input.Until, revoke)
+	}); err != nil {
+		return sanctionError(c, err)
+	}
+
+	h.db.First(&user, user.ID)
+	return c.JSON(fiber.Map{"success": true, "user": formatAccountStatus(user), "sessionsRevoked": revoke})
+}
+
+// AdminLiftSanction — DELETE /api/admin/users/:userId/sanction
+func (h *AdminHandler) AdminLiftSanction(c *fiber.Ctx) error {
+	userID, err := paramID(c, "userId", publicid.User)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "User not found"}})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to load user"}})
+	}
+	if user.Status == models.AccountActive {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_STATUS", "message": "This account is not suspended or banned"}})
+	}
+
+	before := sanctionAudit(user)
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"status": models.AccountActive, "status_reason": "", "suspended_until": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditLog{
+			ActorType: models.AuditActorAdmin, Action: models.AuditAccountReinstate,
+			TargetType: models.AuditTargetUser, TargetID: user.ID, UserID: &user.ID,
+		}, before, sanctionAudit(models.User{Status: models.AccountActive}))
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update account status"}})
+	}
+
+	h.db.First(&user, user.ID)
+	return c.JSON(fiber.Map{"success": true, "user": formatAccountStatus(user)})
+}
+
+// AdminRevokeUserSessions — DELETE /api/admin/users/:userId/sessions
+func (h *AdminHandler) AdminRevokeUserSessions(c *fiber.Ctx) error {
+	userID, err := paramID(c, "userId", publicid.User)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var count int64
+	h.db.Model(&models.User{}).Where("id = ?", userID).Count(&count)
+	if count == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "User not found"}})
+	}
+
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		return revokeAllSessions(tx, c, userID, models.AuditActorAdmin)
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to revoke sessions"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+var (
+	errSanctionSelf  = errors.New("you cannot suspend or ban your own account")
+	errSanctionAdmin = errors.New("remove the admin role before suspending or banning this account")
+)
+
+// sanctionUser suspends or bans an account on an admin's behalf, optionally
+// signing it out everywhere. Admins can't sanction themselves or other admins.
+func sanctionUser(tx *gorm.DB, c *fiber.Ctx, user models.User, status, reason string, until *time.Time, revoke bool) error {
+	if user.ID == c.Locals("userID").(uint) {
+		return errSanctionSelf
+	}
+	if user.Role == models.UserRoleAdmin {
+		return errSanctionAdmin
+	}
+	before := sanctionAudit(user)
+	user.Status, user.StatusReason, user.SuspendedUntil = status, reason, until
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"status": status, "status_reason": reason, "suspended_until": until,
+	}).Error; err != nil {
+		return err
+	}
+	if err := recordAudit(tx, c, models.AuditLog{
+		ActorType: models.AuditActorAdmin, Action: models.AuditAccountSanction,
+		TargetType: models.AuditTargetUser, TargetID: user.ID, UserID: &user.ID,
+	}, before, sanctionAudit(user)); err != nil {
+		return err
+	}
+	if !revoke {
+		return nil
+	}
+	return revokeAllSessions(tx, c, user.ID, models.AuditActorAdmin)
+}
+
+// revokeAllSessions deletes the user's sessions and refresh tokens, invalidates
+// the access tokens issued so far and audits it; actorType is empty when users
+// sign themselves out.
+func revokeAllSessions(tx *gorm.DB, c *fiber.Ctx, userID uint, actorType string) error {
+	// JWT iat has second precision, so tokens are compared by the second
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).
+		Update("sessions_revoked_at", time.Now().Truncate(time.Second)).Error; err != nil {
+		return err
+	}
+	res := tx.Where("user_id = ?", userID).Delete(&models.Session{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	return recordAudit(tx, c, models.AuditLog{
+		ActorType: actorType, Action: models.AuditSessionsRevoked,
+		TargetType: models.AuditTargetUser, TargetID: userID, UserID: &userID,
+	}, map[string]interface{}{"sessions": res.RowsAffected}, map[string]interface{}{"sessions": 0})
+}
+
+func sanctionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errSanctionSelf):
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "CANNOT_SANCTION_SELF", "message": "You cannot suspend or ban your own account"}})
+	case errors.Is(err, errSanctionAdmin):
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "CANNOT_SANCTION_ADMIN", "message": "Remove the admin role before suspending or banning this account"}})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update account status"}})
+}
+
+// sanctionAudit returns the audited fields of an account's standing
+func sanctionAudit(u models.User) map[string]interface{} {
+	return map[string]interface{}{"status": u.Status, "reason": u.StatusReason, "suspendedUntil": u.SuspendedUntil}
+}
+
+func formatAccountStatus(u models.User) fiber.Map {
+	status := u.Sanction(time.Now())
+	if status == "" {
+		status = models.AccountActive
+	}
+	account := fiber.Map{
+		"id": publicid.Format(publicid.User, u.ID), "email": u.Email,
+		"name": u.GetDisplayName(), "status": status,
+	}
+	if status != models.AccountActive {
+		account["reason"], account["suspendedUntil"] = u.StatusReason, u.SuspendedUntil
+	}
+	return account
+}
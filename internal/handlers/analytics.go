@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Longest range served by the analytics endpoint
+const maxAnalyticsDays = 366
+
+// AnalyticsHandler serves per-app analytics aggregated by the rollup job
+type AnalyticsHandler struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsHandler(db *gorm.DB) *AnalyticsHandler {
+	return &AnalyticsHandler{db: db}
+}
+
+// GetAppAnalytics — GET /api/developer/apps/:appId/analytics?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AnalyticsHandler) GetAppAnalytics(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAnalyst)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "from must be YYYY-MM-DD"}})
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "to must be YYYY-MM-DD"}})
+		}
+		to = t
+	}
+	if to.Before(from) || to.Sub(from) > maxAnalyticsDays*24*time.Hour {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid date range"}})
+	}
+
+	var days []models.AppDailyStats
+	h.db.Where("app_id = ? AND date >= ? AND date <= ?", app.ID, from, to).Order("date ASC").Find(&days)
+
+	daily := make([]fiber.Map, len(days))
+	var newUsers, launches, messagesIn, messagesOut, shareClicks, peakDAU, sumDAU, mau int
+	var lastRollup time.Time
+	for i, d := range days {
+		daily[i] = fiber.Map{
+			"date": d.Date.Format("2006-01-02"), "dau": d.ActiveUsers, "mau": d.MonthlyActiveUsers,
+			"newUsers": d.NewUsers, "returningUsers": d.ReturningUsers, "launches": d.Launches,
+			"messagesIn": d.MessagesIn, "messagesOut": d.MessagesOut,
+			"shareClicks": d.ShareClicks,
+		}
+		newUsers += d.NewUsers
+		launches += d.Launches
+		messagesIn += d.MessagesIn
+		messagesOut += d.MessagesOut
+		shareClicks += d.ShareClicks
+		sumDAU += d.ActiveUsers
+		if d.ActiveUsers > peakDAU {
+			peakDAU = d.ActiveUsers
+		}
+		mau = d.MonthlyActiveUsers
+		if d.UpdatedAt.After(lastRollup) {
+			lastRollup = d.UpdatedAt
+		}
+	}
+	var avgDAU float64
+	if len(days) > 0 {
+		avgDAU = float64(sumDAU) / float64(len(days))
+	}
+
+	var commands []struct {
+		Command string
+		Count   int
+	}
+	h.db.Model(&models.AppCommandStats{}).
+		Select("command, SUM(count) AS count").
+		Where("app_id = ? AND date >= ? AND date <= ?", app.ID, from, to).
+		Group("command").Order("count DESC").Limit(50).Scan(&commands)
+
+	commandList := make([]fiber.Map, len(commands))
+	for i, cmd := range commands {
+		commandList[i] = fiber.Map{"command": cmd.Command, "count": cmd.Count}
+	}
+
+	return c.JSON(fiber.Map{
+		"range": fiber.Map{"from": from.Format("2006-01-02"), "to": to.Format("2006-01-02")},
+		"summary": fiber.Map{
+			"avgDau": avgDAU, "peakDau": peakDAU, "mau": mau,
+			"newUsers": newUsers, "launches": launches,
+			"messagesIn": messagesIn, "messagesOut": messagesOut,
+			"shareClicks": shareClicks, "totalUsers": app.UsersCount,
+		},
+		"daily":     daily,
+		"retention": h.retention(app.ID, from, to),
+		"commands":  commandList,
+		"updatedAt": lastRollup,
+	})
+}
+
+// retention returns the weekly cohorts that started inside the range
+func (h *AnalyticsHandler) retention(appID uint, from, to time.Time) []fiber.Map {
+	var rows []models.AppRetentionCohort
+	h.db.Where("app_id = ? AND cohort_week >= ? AND cohort_week <= ?", appID, from.AddDate(0, 0, -6), to).
+		Order("cohort_week ASC, week_offset ASC").Find(&rows)
+
+	cohorts := []fiber.Map{}
+	var current fiber.Map
+	var currentWeek time.Time
+	for _, r := range rows {
+		if current == nil || !r.CohortWeek.Equal(currentWeek) {
+			currentWeek = r.CohortWeek
+			current = fiber.Map{
+				"cohortWeek": r.CohortWeek.Format("2006-01-02"), "cohortSize": r.CohortSize,
+				"weeks": []fiber.Map{},
+			}
+			cohorts = append(cohorts, current)
+		}
+		var rate float64
+		if r.CohortSize > 0 {
+			rate = float64(r.Users) / float64(r.CohortSize)
+		}
+		current["weeks"] = append(current["weeks"].([]fiber.Map), fiber.Map{
+			"week": r.WeekOffset, "users": r.Users, "rate": rate,
+		})
+	}
+	return cohorts
+}
+
+// recordAppLaunch stores a launch event for the analytics rollup
+func recordAppLaunch(db *gorm.DB, userID, appID uint) {
+	if userID == 0 {
+		return
+	}
+	db.Create(&models.AppLaunch{AppID: appID, UserID: userID})
+}
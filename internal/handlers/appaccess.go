@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var errRoleTooLow = errors.New("role does not allow this action")
+
+// appRole returns the user's role on an app. A personal app has its creator as
+// the only owner; an organization's app follows the organization's membership.
+func appRole(db *gorm.DB, app models.MiniApp, userID uint) (models.OrgRole, bool) {
+	if app.OrganizationID == nil {
+		if app.CreatorID == userID {
+			return models.OrgRoleOwner, true
+		}
+		return "", false
+	}
+	return orgRole(db, *app.OrganizationID, userID)
+}
+
+// orgRole returns the user's role in an organization
+func orgRole(db *gorm.DB, orgID, userID uint) (models.OrgRole, bool) {
+	var member models.OrgMember
+	if err := db.Where("organization_id = ? AND user_id = ?", orgID, userID).Take(&member).Error; err != nil {
+		return "", false
+	}
+	return member.Role, true
+}
+
+// hasAppRole reports whether the user holds at least min on the app
+func hasAppRole(db *gorm.DB, app models.MiniApp, userID uint, min models.OrgRole) bool {
+	role, ok := appRole(db, app, userID)
+	return ok && role.AtLeast(min)
+}
+
+// authorizeApp loads an app the user holds at least min on. Apps the user has no
+// role on come back as gorm.ErrRecordNotFound so their existence isn't revealed;
+// errRoleTooLow means the user is on the app's team but their role falls short.
+func authorizeApp(db *gorm.DB, appID, userID uint, min models.OrgRole) (models.MiniApp, error) {
+	var app models.MiniApp
+	if err := db.First(&app, appID).Error; err != nil {
+		return app, err
+	}
+	role, ok := appRole(db, app, userID)
+	if !ok {
+		return app, gorm.ErrRecordNotFound
+	}
+	if !role.AtLeast(min) {
+		return app, errRoleTooLow
+	}
+	return app, nil
+}
+
+// appAccessError responds to an authorizeApp failure
+func appAccessError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errRoleTooLow) {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "Your role on this app doesn't allow this"}})
+	}
+	return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+}
+
+// teamApps narrows a MiniApp query to the apps the user holds at least min on
+func teamApps(db *gorm.DB, userID uint, min models.OrgRole) *gorm.DB {
+	orgs := db.Session(&gorm.Session{NewDB: true}).Model(&models.OrgMember{}).
+		Select("organization_id").Where("user_id = ? AND role IN ?", userID, models.OrgRolesAtLeast(min))
+	return db.Where("(organization_id IS NULL AND creator_id = ?) OR organization_id IN (?)", userID, orgs)
+}
+
+// teamRoles maps each app to the user's role on it, for apps loaded via teamApps
+func teamRoles(db *gorm.DB, apps []models.MiniApp, userID uint) map[uint]models.OrgRole {
+	var members []models.OrgMember
+	db.Where("user_id = ?", userID).Find(&members)
+	byOrg := map[uint]models.OrgRole{}
+	for _, m := range members {
+		byOrg[m.OrganizationID] = m.Role
+	}
+
+	roles := map[uint]models.OrgRole{}
+	for _, app := range apps {
+		if app.OrganizationID == nil {
+			roles[app.ID] = models.OrgRoleOwner
+		} else if role, ok := byOrg[*app.OrganizationID]; ok {
+			roles[app.ID] = role
+		}
+	}
+	return roles
+}
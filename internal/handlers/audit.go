@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Public ID kind of each audit target type
+var auditTargetKinds = map[string]string{
+	models.AuditTargetUser:    publicid.User,
+	models.AuditTargetApp:     publicid.App,
+	models.AuditTargetSession: publicid.Session,
+}
+
+// AuditHandler serves the audit log to admins and each user's own security activity
+type AuditHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewAuditHandler(db *gorm.DB, cfg *config.Config) *AuditHandler {
+	return &AuditHandler{db: db, cfg: cfg}
+}
+
+// AdminListAuditLogs — GET /api/admin/audit?actorType=&actorId=&userId=&action=&targetType=&targetId=&from=&to=
+// actorId is a user ID, or an app ID with actorType=app. from and to are RFC 3339 times.
+func (h *AuditHandler) AdminListAuditLogs(c *fiber.Ctx) error {
+	query := h.db.Model(&models.AuditLog{})
+	scope := "audit"
+
+	actorType := c.Query("actorType")
+	if actorType != "" {
+		query = query.Where("actor_type = ?", actorType)
+		scope += ":actorType:" + actorType
+	}
+	if raw := c.Query("actorId"); raw != "" {
+		kind := publicid.User
+		if actorType == models.AuditActorApp {
+			kind = publicid.App
+		}
+		actorID, err := publicid.Parse(raw, kind)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		query = query.Where("actor_id = ?", actorID)
+		scope += ":actor:" + raw
+	}
+	if raw := c.Query("userId"); raw != "" {
+		userID, err := publicid.Parse(raw, publicid.User)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		query = query.Where("user_id = ?", userID)
+		scope += ":user:" + raw
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+		scope += ":action:" + action
+	}
+	targetType := c.Query("targetType")
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+		scope += ":targetType:" + targetType
+	}
+	if raw := c.Query("targetId"); raw != "" {
+		kind, ok := auditTargetKinds[targetType]
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "targetId needs targetType user, app or session"}})
+		}
+		targetID, err := publicid.Parse(raw, kind)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		query = query.Where("target_id = ?", targetID)
+		scope += ":target:" + raw
+	}
+	for _, bound := range []struct{ param, cond string }{{"from", "created_at >= ?"}, {"to", "created_at < ?"}} {
+		raw := c.Query(bound.param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": bound.param + " must be an RFC 3339 time"}})
+		}
+		query = query.Where(bound.cond, t)
+		scope += ":" + bound.param + ":" + raw
+	}
+
+	entries, pagination, err := h.page(c, query, scope)
+	if err != nil {
+		return invalidCursorError(c)
+	}
+	result := make([]fiber.Map, len(entries))
+	for i, e := range entries {
+		result[i] = formatAuditLog(e, true)
+	}
+	return c.JSON(fiber.Map{"success": true, "entries": result, "pagination": pagination})
+}
+
+// SecurityActivity — GET /api/users/me/security-activity
+// The account's audit entries, including what admins did to it. Admins acting on
+// the account aren't identified.
+func (h *AuditHandler) SecurityActivity(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	entries, pagination, err := h.page(c, h.db.Model(&models.AuditLog{}).Where("user_id = ?", userID), "security-activity")
+	if err != nil {
+		return invalidCursorError(c)
+	}
+	result := make([]fiber.Map, len(entries))
+	for i, e := range entries {
+		result[i] = formatAuditLog(e, false)
+	}
+	return c.JSON(fiber.Map{"success": true, "activity": result, "pagination": pagination})
+}
+
+// page loads one page of query, newest first
+func (h *AuditHandler) page(c *fiber.Ctx, query *gorm.DB, scope string) ([]models.AuditLog, fiber.Map, error) {
+	cursor, err := decodeCursor(h.cfg.JWTSecret, c.Query("cursor"), scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit := pageLimit(c)
+
+	var entries []models.AuditLog
+	afterTimeCursor(query, "created_at", cursor).Order("created_at DESC, id DESC").Limit(limit + 1).Find(&entries)
+
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
+	var nextCursor interface{}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		nextCursor = nextTimeCursor(h.cfg.JWTSecret, scope, hasMore, last.CreatedAt, last.ID)
+	}
+	return entries, fiber.Map{"limit": limit, "hasMore": hasMore, "nextCursor": nextCursor}, nil
+}
+
+// helpers
+
+// recordAudit appends an entry to the audit log. With a request context the IP,
+// user agent and, unless set, the signed-in user as actor are filled in; without
+// one the actor is the system. A user's action concerns their own account unless
+// UserID says otherwise. Only the fields that differ between before and after
+// are stored. Secrets must be passed through maskSecret.
+func recordAudit(db *gorm.DB, c *fiber.Ctx, entry models.AuditLog, before, after map[string]interface{}) error {
+	if c != nil {
+		entry.IP, entry.UserAgent = c.IP(), c.Get(fiber.HeaderUserAgent)
+		if userID, ok := c.Locals("userID").(uint); ok && entry.ActorID == nil {
+			entry.ActorID = &userID
+		}
+	}
+	if entry.ActorType == "" {
+		entry.ActorType = models.AuditActorUser
+		if entry.ActorID == nil {
+			entry.ActorType = models.AuditActorSystem
+		}
+	}
+	if entry.UserID == nil && entry.ActorType == models.AuditActorUser {
+		entry.UserID = entry.ActorID
+	}
+
+	changes := map[string]models.AuditChange{}
+	for field, from := range before {
+		if to := after[field]; !reflect.DeepEqual(from, to) {
+			changes[field] = models.AuditChange{From: from, To: to}
+		}
+	}
+	for field, to := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = models.AuditChange{To: to}
+		}
+	}
+	if len(changes) > 0 {
+		encoded, _ := json.Marshal(changes)
+		entry.Changes = string(encoded)
+	}
+	return db.Create(&entry).Error
+}
+
+// recordAppAudit audits a change to an app's credentials or webhook. Changes made
+// by the bot itself or by the system show up in the creator's security activity.
+func recordAppAudit(db *gorm.DB, c *fiber.Ctx, app models.MiniApp, action string, before, after map[string]interface{}) error {
+	entry := models.AuditLog{Action: action, TargetType: models.AuditTargetApp, TargetID: app.ID}
+	if c == nil || c.Locals("userID") == nil {
+		entry.UserID = &app.CreatorID
+		if c != nil {
+			entry.ActorType, entry.ActorID = models.AuditActorApp, &app.ID
+		}
+	}
+	return recordAudit(db, c, entry, before, after)
+}
+
+// credentialAudit returns the audited fields of an app's bot credentials
+func credentialAudit(app models.MiniApp) map[string]interface{} {
+	apiKey := ""
+	if app.HasAPIToken() {
+		apiKey = maskSecret(app.APIToken)
+	}
+	return map[string]interface{}{
+		"apiKey": apiKey, "webhookSecret": maskSecret(app.WebhookSecret),
+		"apiKeyExpiresAt": app.APITokenExpiresAt,
+	}
+}
+
+// webhookAudit returns the audited fields of an app's webhook
+func webhookAudit(app models.MiniApp) map[string]interface{} {
+	return map[string]interface{}{"webhookUrl": app.WebhookURL, "webhookDisabled": app.WebhookDisabledAt != nil}
+}
+
+// balanceAudit starts the audit entry for a change to userID's Mana Points. The
+// actor defaults to the signed-in user as with recordAudit.
+func balanceAudit(userID uint, note string) models.AuditLog {
+	return models.AuditLog{
+		Action: models.AuditBalanceChanged, TargetType: models.AuditTargetUser, TargetID: userID,
+		UserID: &userID, Note: note,
+	}
+}
+
+// recordBalanceChange audits a Mana Points change by delta. after is the balance
+// once the change is applied.
+func recordBalanceChange(db *gorm.DB, c *fiber.Ctx, entry models.AuditLog, after, delta int) error {
+	return recordAudit(db, c, entry,
+		map[string]interface{}{"manaPoints": after - delta}, map[string]interface{}{"manaPoints": after})
+}
+
+// manaBalance reads a user's current Mana Points, for changes applied with SQL
+// expressions
+func manaBalance(db *gorm.DB, userID uint) (int, error) {
+	var balance int
+	err := db.Model(&models.User{}).Where("id = ?", userID).Select("mana_points").Scan(&balance).Error
+	return balance, err
+}
+
+// maskSecret keeps the last four characters of a token so entries can tell
+// tokens apart without storing them
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return secret
+	}
+	return "…" + secret[len(secret)-4:]
+}
+
+func formatAuditLog(e models.AuditLog, admin bool) fiber.Map {
+	entry := fiber.Map{
+		"id": publicid.Format(publicid.Audit, e.ID), "action": e.Action,
+		"actorType": e.ActorType, "actorId": nil,
+		"targetType": e.TargetType, "targetId": nil,
+		"changes": e.ChangeSet(), "note": e.Note,
+		"ip": e.IP, "userAgent": e.UserAgent, "createdAt": e.CreatedAt,
+	}
+	if kind, ok := auditTargetKinds[e.TargetType]; ok {
+		entry["targetId"] = publicid.Format(kind, e.TargetID)
+	}
+	switch {
+	case e.ActorID == nil:
+	case e.ActorType == models.AuditActorApp:
+		entry["actorId"] = publicid.Format(publicid.App, *e.ActorID)
+	case e.ActorType == models.AuditActorAdmin && !admin:
+		// Users see that an admin acted, not which one or from where
+		entry["ip"], entry["userAgent"] = "", ""
+	default:
+		entry["actorId"] = publicid.Format(publicid.User, *e.ActorID)
+	}
+	if admin {
+		entry["userId"] = formatOptionalID(publicid.User, e.UserID)
+	}
+	return entry
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/middleware"
 	"github.com/fasad/solanafon-back/internal/models"
 	"github.com/fasad/solanafon-back/internal/utils"
 	"github.com/gofiber/fiber/v2"
@@ -164,6 +165,10 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 		h.db.Create(&welcomeTransaction)
 	}
 
+	if user.Sanction(time.Now()) != "" {
+		return middleware.AccountSanctionError(c, user)
+	}
+
 	// Generate JWT token
 	token, err := utils.GenerateJWT(user.ID, user.Email, h.cfg.JWTSecret)
 	if err != nil {
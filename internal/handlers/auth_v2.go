@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/middleware"
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/fasad/solanafon-back/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -101,6 +103,7 @@ func (h *AuthV2Handler) VerifyCode(c *fiber.Ctx) error {
 				// Bonus for referrer
 				referrer.ManaPoints += 100
 				h.db.Save(&referrer)
+				recordBalanceChange(h.db, nil, balanceAudit(referrer.ID, "Referral bonus"), referrer.ManaPoints, 100)
 				h.db.Create(&models.ManaTransaction{
 					UserID: referrer.ID, Amount: 100, Type: "reward", Description: "Referral bonus",
 				})
@@ -108,6 +111,10 @@ func (h *AuthV2Handler) VerifyCode(c *fiber.Ctx) error {
 		}
 	}
 
+	if user.Sanction(time.Now()) != "" {
+		return middleware.AccountSanctionError(c, user)
+	}
+
 	// Generate tokens
 	token, _ := utils.GenerateJWT(user.ID, user.Email, h.cfg.JWTSecret)
 	refreshTokenStr := models.GenerateRefreshToken()
@@ -127,7 +134,7 @@ func (h *AuthV2Handler) VerifyCode(c *fiber.Ctx) error {
 		"token":        token,
 		"refreshToken": refreshTokenStr,
 		"user": fiber.Map{
-			"id":          fmt.Sprintf("user_%d", user.ID),
+			"id":          publicid.Format(publicid.User, user.ID),
 			"email":       user.Email,
 			"displayName": user.GetDisplayName(),
 			"avatarUrl":   user.GetAvatarURL(),
@@ -151,6 +158,9 @@ func (h *AuthV2Handler) RefreshToken(c *fiber.Ctx) error {
 
 	var user models.User
 	h.db.First(&user, rt.UserID)
+	if user.Sanction(time.Now()) != "" {
+		return middleware.AccountSanctionError(c, user)
+	}
 
 	// Delete old, create new
 	h.db.Delete(&rt)
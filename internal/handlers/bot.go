@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -99,6 +100,16 @@ func (h *BotHandler) SendMessage(c *fiber.Ctx) error {
 		})
 	}
 
+	// Bots may only notify users who granted them notifications
+	notified := hasPermission(h.db, user.ID, app.ID, models.PermissionNotifications)
+	if notified {
+		h.db.Create(&models.Notification{
+			UserID: user.ID, Type: "app_message",
+			Title: app.Title, Body: truncateRunes(input.Text, 200),
+			ActionURL: shareDeepLink(models.ShareTargetApp, app.ID),
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"ok": true,
 		"result": fiber.Map{
@@ -107,8 +118,9 @@ func (h *BotHandler) SendMessage(c *fiber.Ctx) error {
 				"id":   user.ID,
 				"type": "private",
 			},
-			"date": msg.CreatedAt.Unix(),
-			"text": msg.Content,
+			"date":     msg.CreatedAt.Unix(),
+			"text":     msg.Content,
+			"notified": notified,
 		},
 	})
 }
@@ -158,12 +170,7 @@ func (h *BotHandler) GetUpdates(c *fiber.Ctx) error {
 			"update_id": msg.ID,
 			"message": fiber.Map{
 				"message_id": msg.ID,
-				"from": fiber.Map{
-					"id":       user.ID,
-					"email":    user.Email,
-					"name":     user.Name,
-					"language": user.Language,
-				},
+				"from":       botUserPayload(h.db, user, app.ID),
 				"chat": fiber.Map{
 					"id":   user.ID,
 					"type": "private",
@@ -174,12 +181,191 @@ func (h *BotHandler) GetUpdates(c *fiber.Ctx) error {
 		})
 	}
 
+	// Queued events (payments etc.) for bots without a webhook
+	var events []models.BotEvent
+	h.db.Where("app_id = ? AND delivered = ?", app.ID, false).Order("created_at ASC").Limit(100).Find(&events)
+	if len(events) > 0 {
+		var ids []uint
+		for _, ev := range events {
+			ids = append(ids, ev.ID)
+			updates = append(updates, fiber.Map{
+				"update_id": ev.ID,
+				"event": fiber.Map{
+					"type": ev.Event,
+					"date": ev.CreatedAt.Unix(),
+					"data": json.RawMessage(ev.Payload),
+				},
+			})
+		}
+		h.db.Model(&models.BotEvent{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"delivered": true, "delivered_at": time.Now()})
+	}
+
 	return c.JSON(fiber.Map{
 		"ok":     true,
 		"result": updates,
 	})
 }
 
+// SendInvoiceInput - input for sending an invoice via Bot API
+type SendInvoiceInput struct {
+	ChatID      uint   `json:"chat_id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Amount      int    `json:"amount"`            // Mana Points
+	Payload     string `json:"payload,omitempty"` // Returned in payment events
+}
+
+// SendInvoice - send a Mana Points invoice to a user
+// POST /bot/sendInvoice
+func (h *BotHandler) SendInvoice(c *fiber.Ctx) error {
+	app, err := h.getAppFromToken(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  401,
+			"description": "Unauthorized: invalid API token",
+		})
+	}
+
+	var input SendInvoiceInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  400,
+			"description": "Bad Request: invalid request body",
+		})
+	}
+
+	if input.ChatID == 0 || strings.TrimSpace(input.Title) == "" || input.Amount <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  400,
+			"description": "Bad Request: chat_id, title and positive amount are required",
+		})
+	}
+
+	var user models.User
+	if err := h.db.First(&user, input.ChatID).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  400,
+			"description": "Bad Request: chat not found",
+		})
+	}
+
+	if !hasPermission(h.db, user.ID, app.ID, models.PermissionPayments) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  403,
+			"description": "Forbidden: user has not granted the payments permission",
+		})
+	}
+
+	invoice := models.Invoice{
+		AppID: app.ID, UserID: user.ID, Title: input.Title, Description: input.Description,
+		Amount: input.Amount, Payload: input.Payload, Status: models.InvoicePending,
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invoice).Error; err != nil {
+			return err
+		}
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"invoice_id": invoice.ID, "title": invoice.Title,
+			"description": invoice.Description, "amount": invoice.Amount, "currency": "MP",
+		})
+		msg := models.AppMessage{
+			AppID:       app.ID,
+			UserID:      user.ID,
+			Content:     invoice.Title,
+			IsFromBot:   true,
+			MessageType: "invoice",
+			Metadata:    string(metadata),
+			CreatedAt:   time.Now(),
+		}
+		if err := tx.Create(&msg).Error; err != nil {
+			return err
+		}
+		invoice.MessageID = msg.ID
+		return tx.Model(&invoice).Update("message_id", msg.ID).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  500,
+			"description": "Internal Server Error: failed to send invoice",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"ok": true,
+		"result": fiber.Map{
+			"invoice_id": invoice.ID,
+			"message_id": invoice.MessageID,
+			"chat":       fiber.Map{"id": user.ID, "type": "private"},
+			"amount":     invoice.Amount,
+			"currency":   "MP",
+			"status":     invoice.Status,
+		},
+	})
+}
+
+// RefundPayment - refund a paid invoice
+// POST /bot/refundPayment
+func (h *BotHandler) RefundPayment(c *fiber.Ctx) error {
+	app, err := h.getAppFromToken(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  401,
+			"description": "Unauthorized: invalid API token",
+		})
+	}
+
+	var input struct {
+		InvoiceID uint   `json:"invoice_id"`
+		Reason    string `json:"reason"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.InvoiceID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  400,
+			"description": "Bad Request: invoice_id is required",
+		})
+	}
+
+	var invoice models.Invoice
+	if err := h.db.Where("id = ? AND app_id = ?", input.InvoiceID, app.ID).First(&invoice).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  400,
+			"description": "Bad Request: invoice not found",
+		})
+	}
+	invoice.App = *app
+
+	if err := refundInvoice(h.db, c, &invoice, input.Reason); err != nil {
+		if err == errInvoiceNotPaid {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"ok":          false,
+				"error_code":  400,
+				"description": "Bad Request: invoice is not paid",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"ok":          false,
+			"error_code":  500,
+			"description": "Internal Server Error: failed to refund payment",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"ok":     true,
+		"result": invoiceEventData(invoice),
+	})
+}
+
 // SetWebhook - set webhook URL for the bot
 // POST /bot/setWebhook
 func (h *BotHandler) SetWebhook(c *fiber.Ctx) error {
@@ -203,8 +389,15 @@ func (h *BotHandler) SetWebhook(c *fiber.Ctx) error {
 		})
 	}
 
+	before := webhookAudit(*app)
 	app.WebhookURL = input.URL
-	if err := h.db.Save(&app).Error; err != nil {
+	app.WebhookFailures, app.WebhookDisabledAt = 0, nil
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&app).Error; err != nil {
+			return err
+		}
+		return recordAppAudit(tx, c, *app, models.AuditWebhookUpdated, before, webhookAudit(*app))
+	}); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"ok":          false,
 			"error_code":  500,
@@ -231,8 +424,15 @@ func (h *BotHandler) DeleteWebhook(c *fiber.Ctx) error {
 		})
 	}
 
+	before := webhookAudit(*app)
 	app.WebhookURL = ""
-	if err := h.db.Save(&app).Error; err != nil {
+	app.WebhookFailures, app.WebhookDisabledAt = 0, nil
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&app).Error; err != nil {
+			return err
+		}
+		return recordAppAudit(tx, c, *app, models.AuditWebhookDeleted, before, webhookAudit(*app))
+	}); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"ok":          false,
 			"error_code":  500,
@@ -271,6 +471,7 @@ func (h *BotHandler) GetWebhookInfo(c *fiber.Ctx) error {
 			"url":                  app.WebhookURL,
 			"has_custom_certificate": false,
 			"pending_update_count": pendingCount,
+			"is_disabled":          app.WebhookDisabledAt != nil, // Too many failed deliveries; set the webhook again to resume
 		},
 	})
 }
@@ -393,12 +594,15 @@ func (h *BotHandler) getAppFromToken(c *fiber.Ctx) (*models.MiniApp, error) {
 	}
 
 	var app models.MiniApp
-	if err := h.db.Where("api_token = ?", token).First(&app).Error; err != nil {
+	if err := h.db.Where("api_token = ?", token).First(&app).Error; err != nil || !app.HasAPIToken() {
+		return nil, fiber.ErrUnauthorized
+	}
+	if app.APITokenExpiresAt != nil && time.Now().After(*app.APITokenExpiresAt) {
 		return nil, fiber.ErrUnauthorized
 	}
 
-	// Check if app is approved
-	if app.ModerationStatus != models.ModerationApproved {
+	// Check if app is approved and not suspended
+	if app.ModerationStatus != models.ModerationApproved || app.SuspendedAt != nil {
 		return nil, fiber.ErrUnauthorized
 	}
 
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/notify"
+	"gorm.io/gorm"
+)
+
+// dispatchBotEvent queues an event for the bot and pushes it to the webhook if one is set.
+// Bots without a webhook receive the event through getUpdates.
+func dispatchBotEvent(db *gorm.DB, app models.MiniApp, event string, data map[string]interface{}) {
+	payload, _ := json.Marshal(data)
+	ev := models.BotEvent{AppID: app.ID, Event: event, Payload: string(payload)}
+	if err := db.Create(&ev).Error; err != nil {
+		return
+	}
+
+	if app.WebhookActive() {
+		go deliverBotEvent(db, app, ev)
+	}
+}
+
+// deliverBotEvent POSTs a queued event to the app webhook and logs the call
+func deliverBotEvent(db *gorm.DB, app models.MiniApp, ev models.BotEvent) {
+	startTime := time.Now()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"event":     ev.Event,
+		"event_id":  ev.ID,
+		"timestamp": ev.CreatedAt.UnixMilli(),
+		"data":      json.RawMessage(ev.Payload),
+	})
+
+	webhookLog := models.WebhookLog{
+		AppID:     app.ID,
+		URL:       app.WebhookURL,
+		Method:    "POST",
+		Request:   string(body),
+		CreatedAt: time.Now(),
+	}
+
+	req, err := http.NewRequest("POST", app.WebhookURL, bytes.NewBuffer(body))
+	if err != nil {
+		webhookLog.Response = "Error creating request: " + err.Error()
+		webhookLog.Duration = int(time.Since(startTime).Milliseconds())
+		db.Create(&webhookLog)
+		recordWebhookDelivery(db, app, false)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-App-ID", fmt.Sprintf("%d", app.ID))
+	req.Header.Set("X-Event", ev.Event)
+	if app.WebhookSecret != "" {
+		req.Header.Set("X-Signature", SignWebhookPayload(body, app.WebhookSecret))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		webhookLog.Response = "Error sending request: " + err.Error()
+		webhookLog.Duration = int(time.Since(startTime).Milliseconds())
+		db.Create(&webhookLog)
+		recordWebhookDelivery(db, app, false)
+		return
+	}
+	defer resp.Body.Close()
+
+	var respBody bytes.Buffer
+	respBody.ReadFrom(resp.Body)
+
+	webhookLog.StatusCode = resp.StatusCode
+	webhookLog.Response = respBody.String()
+	webhookLog.Duration = int(time.Since(startTime).Milliseconds())
+	db.Create(&webhookLog)
+
+	delivered := resp.StatusCode >= 200 && resp.StatusCode < 300
+	recordWebhookDelivery(db, app, delivered)
+	if delivered {
+		now := time.Now()
+		db.Model(&models.BotEvent{}).Where("id = ?", ev.ID).
+			Updates(map[string]interface{}{"delivered": true, "delivered_at": now})
+	}
+}
+
+// recordWebhookDelivery tracks consecutive failed deliveries and disables the
+// webhook once they reach models.WebhookMaxFailures
+func recordWebhookDelivery(db *gorm.DB, app models.MiniApp, delivered bool) {
+	if delivered {
+		db.Model(&models.MiniApp{}).Where("id = ? AND webhook_failures > 0", app.ID).Update("webhook_failures", 0)
+		return
+	}
+
+	db.Model(&models.MiniApp{}).Where("id = ?", app.ID).Update("webhook_failures", gorm.Expr("webhook_failures + 1"))
+	res := db.Model(&models.MiniApp{}).
+		Where("id = ? AND webhook_disabled_at IS NULL AND webhook_failures >= ?", app.ID, models.WebhookMaxFailures).
+		Update("webhook_disabled_at", time.Now())
+	if res.Error == nil && res.RowsAffected > 0 {
+		recordAppAudit(db, nil, app, models.AuditWebhookDisabled,
+			map[string]interface{}{"webhookDisabled": false}, map[string]interface{}{"webhookDisabled": true})
+		notify.WebhookDisabled(db, app)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -68,7 +69,10 @@ func (h *CallsHandler) GetRoomByCode(c *fiber.Ctx) error {
 // JoinRoom — POST /api/calls/rooms/:roomId/join
 func (h *CallsHandler) JoinRoom(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	roomID := c.Params("roomId")
+	roomID, err := paramID(c, "roomId", publicid.Room)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var room models.CallRoom
 	if err := h.db.First(&room, roomID).Error; err != nil {
@@ -92,7 +96,10 @@ func (h *CallsHandler) JoinRoom(c *fiber.Ctx) error {
 
 // EndCall — POST /api/calls/rooms/:roomId/end
 func (h *CallsHandler) EndCall(c *fiber.Ctx) error {
-	roomID := c.Params("roomId")
+	roomID, err := paramID(c, "roomId", publicid.Room)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 	var room models.CallRoom
 	if err := h.db.First(&room, roomID).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Room not found"}})
@@ -114,7 +121,10 @@ func (h *CallsHandler) EndCall(c *fiber.Ctx) error {
 // UpdateParticipantStatus — PATCH /api/calls/rooms/:roomId/status
 func (h *CallsHandler) UpdateParticipantStatus(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	roomID := c.Params("roomId")
+	roomID, err := paramID(c, "roomId", publicid.Room)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var input struct {
 		IsMuted   *bool `json:"isMuted"`
@@ -144,21 +154,21 @@ func formatRoom(room models.CallRoom) fiber.Map {
 		var userData fiber.Map
 		if p.User.ID > 0 {
 			userData = fiber.Map{
-				"id": fmt.Sprintf("user_%d", p.User.ID),
+				"id": publicid.Format(publicid.User, p.User.ID),
 				"displayName": p.User.GetDisplayName(), "avatarUrl": p.User.GetAvatarURL(),
 			}
 		}
 		participants[i] = fiber.Map{
-			"id": fmt.Sprintf("part_%d", p.ID), "roomId": fmt.Sprintf("room_%d", p.RoomID),
-			"userId": fmt.Sprintf("user_%d", p.UserID), "status": p.Status,
+			"id": publicid.Format(publicid.Participant, p.ID), "roomId": publicid.Format(publicid.Room, p.RoomID),
+			"userId": publicid.Format(publicid.User, p.UserID), "status": p.Status,
 			"joinedAt": p.JoinedAt, "isMuted": p.IsMuted, "isVideoOn": p.IsVideoOn,
 			"isAudioOn": p.IsAudioOn, "user": userData,
 		}
 	}
 	return fiber.Map{
-		"id": fmt.Sprintf("room_%d", room.ID), "roomCode": room.RoomCode,
+		"id": publicid.Format(publicid.Room, room.ID), "roomCode": room.RoomCode,
 		"type": room.Type, "status": room.Status,
-		"createdBy": fmt.Sprintf("user_%d", room.CreatedBy),
+		"createdBy": publicid.Format(publicid.User, room.CreatedBy),
 		"startedAt": room.StartedAt, "endedAt": room.EndedAt,
 		"duration": room.Duration, "createdAt": room.CreatedAt,
 		"participants": participants,
@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	languageCodePattern = regexp.MustCompile(`^[a-z]{2}$`)
+
+	errCategoryNotEmpty = errors.New("category still has apps")
+)
+
+// CategoriesHandler serves marketplace categories and their admin management
+type CategoriesHandler struct {
+	db *gorm.DB
+}
+
+func NewCategoriesHandler(db *gorm.DB) *CategoriesHandler {
+	return &CategoriesHandler{db: db}
+}
+
+// GetCategories — GET /api/apps/categories?lang=
+// Names and descriptions follow ?lang=, then the user's language, then the defaults.
+func (h *CategoriesHandler) GetCategories(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	lang := c.Query("lang")
+	if lang == "" {
+		h.db.Model(&models.User{}).Where("id = ?", userID).Pluck("language", &lang)
+	}
+
+	var categories []models.Category
+	h.db.Preload("Translations", "language = ?", lang).Order(`"order" ASC, id ASC`).Find(&categories)
+	counts := h.appCounts(true)
+
+	result := make([]fiber.Map, len(categories))
+	for i, cat := range categories {
+		name, description := cat.Localized(lang)
+		result[i] = fiber.Map{
+			"id": cat.Slug, "name": name, "description": description,
+			"icon": cat.Icon, "appsCount": counts[cat.ID],
+		}
+	}
+	return c.JSON(fiber.Map{"categories": result})
+}
+
+// AdminListCategories — GET /api/admin/categories?includeDeleted=true
+func (h *CategoriesHandler) AdminListCategories(c *fiber.Ctx) error {
+	query := h.db.Preload("Translations")
+	if c.Query("includeDeleted") == "true" {
+		query = query.Unscoped()
+	}
+	var categories []models.Category
+	query.Order(`"order" ASC, id ASC`).Find(&categories)
+	counts := h.appCounts(false)
+
+	result := make([]fiber.Map, len(categories))
+	for i, cat := range categories {
+		result[i] = formatAdminCategory(cat)
+		result[i]["appsCount"] = counts[cat.ID]
+	}
+	return c.JSON(fiber.Map{"success": true, "categories": result})
+}
+
+type categoryInput struct {
+	Name         *string                        `json:"name"`
+	Slug         *string                        `json:"slug"`
+	Description  *string                        `json:"description"`
+	Icon         *string                        `json:"icon"`
+	Translations map[string]categoryTranslation `json:"translations"` // keyed by language; an empty name removes it
+}
+
+type categoryTranslation struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AdminCreateCategory — POST /api/admin/categories (appended at the end of the order)
+func (h *CategoriesHandler) AdminCreateCategory(c *fiber.Ctx) error {
+	var input categoryInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
+	}
+	if input.Name == nil || input.Slug == nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "name and slug are required"}})
+	}
+
+	var category models.Category
+	var maxOrder int
+	h.db.Unscoped().Model(&models.Category{}).Select(`COALESCE(MAX("order"), 0)`).Scan(&maxOrder)
+	category.Order = maxOrder + 1
+
+	if err := applyCategoryInput(&category, input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	}
+	if msg := h.categoryConflict(category); msg != "" {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "CATEGORY_EXISTS", "message": msg}})
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Translations").Create(&category).Error; err != nil {
+			return err
+		}
+		return saveCategoryTranslations(tx, category.ID, input.Translations)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to create category"}})
+	}
+
+	h.db.Preload("Translations").First(&category, category.ID)
+	return c.Status(201).JSON(fiber.Map{"success": true, "category": formatAdminCategory(category)})
+}
+
+// AdminUpdateCategory — PUT /api/admin/categories/:categoryId
+func (h *CategoriesHandler) AdminUpdateCategory(c *fiber.Ctx) error {
+	categoryID, err := paramID(c, "categoryId", publicid.Category)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var category models.Category
+	if err := h.db.First(&category, categoryID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Category not found"}})
+	}
+
+	var input categoryInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
+	}
+	if err := applyCategoryInput(&category, input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	}
+	if msg := h.categoryConflict(category); msg != "" {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "CATEGORY_EXISTS", "message": msg}})
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Translations").Save(&category).Error; err != nil {
+			return err
+		}
+		return saveCategoryTranslations(tx, category.ID, input.Translations)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update category"}})
+	}
+
+	h.db.Preload("Translations").First(&category, category.ID)
+	return c.JSON(fiber.Map{"success": true, "category": formatAdminCategory(category)})
+}
+
+// AdminReorderCategories — PUT /api/admin/categories/order
+// Categories left out of the list keep their relative order after the listed ones.
+func (h *CategoriesHandler) AdminReorderCategories(c *fiber.Ctx) error {
+	var input struct {
+		CategoryIDs []string `json:"categoryIds"`
+	}
+	if err := c.BodyParser(&input); err != nil || len(input.CategoryIDs) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "categoryIds is required"}})
+	}
+
+	ordered := make([]uint, 0, len(input.CategoryIDs))
+	seen := map[uint]bool{}
+	for _, raw := range input.CategoryIDs {
+		id, err := publicid.Parse(raw, publicid.Category)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ordered = append(ordered, id)
+		}
+	}
+
+	var categories []models.Category
+	h.db.Order(`"order" ASC, id ASC`).Find(&categories)
+	known := map[uint]bool{}
+	for _, cat := range categories {
+		known[cat.ID] = true
+	}
+	for _, id := range ordered {
+		if !known[id] {
+			return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Category not found: " + publicid.Format(publicid.Category, id)}})
+		}
+	}
+	for _, cat := range categories {
+		if !seen[cat.ID] {
+			ordered = append(ordered, cat.ID)
+		}
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ordered {
+			if err := tx.Model(&models.Category{}).Where("id = ?", id).Update("order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to reorder categories"}})
+	}
+	return h.AdminListCategories(c)
+}
+
+// AdminDeleteCategory — DELETE /api/admin/categories/:categoryId
+// Soft delete; a category with apps has to be merged into another one first.
+func (h *CategoriesHandler) AdminDeleteCategory(c *fiber.Ctx) error {
+	categoryID, err := paramID(c, "categoryId", publicid.Category)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, categoryID).Error; err != nil {
+			return err
+		}
+		// Apps in their restore window still point at the category
+		var apps int64
+		if err := tx.Model(&models.MiniApp{}).Unscoped().Where("category_id = ?", category.ID).Count(&apps).Error; err != nil {
+			return err
+		}
+		if apps > 0 {
+			return errCategoryNotEmpty
+		}
+		return tx.Delete(&category).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Category not found"}})
+	case errors.Is(err, errCategoryNotEmpty):
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "CATEGORY_NOT_EMPTY", "message": "Merge the category's apps into another category first"}})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to delete category"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// AdminRestoreCategory — POST /api/admin/categories/:categoryId/restore
+func (h *CategoriesHandler) AdminRestoreCategory(c *fiber.Ctx) error {
+	categoryID, err := paramID(c, "categoryId", publicid.Category)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	res := h.db.Unscoped().Model(&models.Category{}).
+		Where("id = ? AND deleted_at IS NOT NULL", categoryID).Update("deleted_at", nil)
+	if res.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Deleted category not found"}})
+	}
+
+	var category models.Category
+	h.db.Preload("Translations").First(&category, categoryID)
+	return c.JSON(fiber.Map{"success": true, "category": formatAdminCategory(category)})
+}
+
+// AdminMergeCategory — POST /api/admin/categories/:categoryId/merge
+// Moves every app (and pending release snapshot) into the target category, then
+// soft-deletes the source.
+func (h *CategoriesHandler) AdminMergeCategory(c *fiber.Ctx) error {
+	sourceID, err := paramID(c, "categoryId", publicid.Category)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var input struct {
+		Into string `json:"into"`
+	}
+	c.BodyParser(&input)
+	targetID, err := publicid.Parse(input.Into, publicid.Category)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	if targetID == sourceID {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Cannot merge a category into itself"}})
+	}
+
+	var moved int64
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var source, target models.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&source, sourceID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.MiniApp{}).Unscoped().Where("category_id = ?", source.ID).
+			UpdateColumn("category_id", target.ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		if err := tx.Model(&models.AppRelease{}).Where("category_id = ?", source.ID).
+			UpdateColumn("category_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Category not found"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to merge categories"}})
+	}
+	return c.JSON(fiber.Map{"success": true, "movedApps": moved, "into": publicid.Format(publicid.Category, targetID)})
+}
+
+// helpers
+
+// appCounts returns the number of apps per category, optionally only in one moderation status
+func (h *CategoriesHandler) appCounts(listedOnly bool) map[uint]int64 {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+	query := h.db.Model(&models.MiniApp{}).Select("category_id, COUNT(*) AS count")
+	if listedOnly {
+		query = listedApps(query)
+	}
+	query.Group("category_id").Scan(&rows)
+
+	counts := make(map[uint]int64, len(rows))
+	for _, r := range rows {
+		counts[r.CategoryID] = r.Count
+	}
+	return counts
+}
+
+// categoryConflict reports a name or slug already used by another category,
+// including soft-deleted ones (which can be restored instead)
+func (h *CategoriesHandler) categoryConflict(category models.Category) string {
+	var other models.Category
+	err := h.db.Unscoped().Where("(slug = ? OR name = ?) AND id <> ?", category.Slug, category.Name, category.ID).
+		First(&other).Error
+	if err != nil {
+		return ""
+	}
+	if other.DeletedAt.Valid {
+		return "A deleted category (" + publicid.Format(publicid.Category, other.ID) + ") uses this name or slug; restore it instead"
+	}
+	return "Another category uses this name or slug"
+}
+
+func applyCategoryInput(category *models.Category, input categoryInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || len([]rune(name)) > 40 {
+			return errors.New("name must be 1-40 characters")
+		}
+		category.Name = name
+	}
+	if input.Slug != nil {
+		slug := strings.ToLower(strings.TrimSpace(*input.Slug))
+		if len(slug) < 2 || len(slug) > 32 || !categorySlugPattern.MatchString(slug) {
+			return errors.New("slug must be 2-32 lowercase letters, digits and dashes")
+		}
+		category.Slug = slug
+	}
+	if input.Description != nil {
+		category.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Icon != nil {
+		category.Icon = strings.TrimSpace(*input.Icon)
+	}
+	for lang, t := range input.Translations {
+		if !languageCodePattern.MatchString(lang) {
+			return errors.New("invalid translation language: " + lang)
+		}
+		if len([]rune(strings.TrimSpace(t.Name))) > 40 {
+			return errors.New("translated name must be at most 40 characters")
+		}
+	}
+	return nil
+}
+
+// saveCategoryTranslations upserts the given translations; an empty name removes one
+func saveCategoryTranslations(tx *gorm.DB, categoryID uint, translations map[string]categoryTranslation) error {
+	for lang, t := range translations {
+		name := strings.TrimSpace(t.Name)
+		if name == "" {
+			if err := tx.Where("category_id = ? AND language = ?", categoryID, lang).
+				Delete(&models.CategoryTranslation{}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		row := models.CategoryTranslation{
+			CategoryID: categoryID, Language: lang,
+			Name: name, Description: strings.TrimSpace(t.Description),
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "category_id"}, {Name: "language"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+		}).Create(&row).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatAdminCategory(cat models.Category) fiber.Map {
+	translations := fiber.Map{}
+	for _, t := range cat.Translations {
+		translations[t.Language] = fiber.Map{"name": t.Name, "description": t.Description}
+	}
+	result := fiber.Map{
+		"id": publicid.Format(publicid.Category, cat.ID), "slug": cat.Slug,
+		"name": cat.Name, "description": cat.Description, "icon": cat.Icon,
+		"order": cat.Order, "translations": translations,
+		"createdAt": cat.CreatedAt, "updatedAt": cat.UpdatedAt,
+	}
+	if cat.DeletedAt.Valid {
+		result["deletedAt"] = cat.DeletedAt.Time
+	}
+	return result
+}
@@ -2,39 +2,59 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/fasad/solanafon-back/internal/config"
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 // ConversationsHandler handles /api/conversations/* endpoints
 type ConversationsHandler struct {
-	db *gorm.DB
+	db  *gorm.DB
+	cfg *config.Config
 }
 
-func NewConversationsHandler(db *gorm.DB) *ConversationsHandler {
-	return &ConversationsHandler{db: db}
+func NewConversationsHandler(db *gorm.DB, cfg *config.Config) *ConversationsHandler {
+	return &ConversationsHandler{db: db, cfg: cfg}
 }
 
 // ListConversations — GET /api/conversations
 func (h *ConversationsHandler) ListConversations(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	limit := pageLimit(c)
 	if page < 1 {
 		page = 1
 	}
 	offset := (page - 1) * limit
+	cursor, err := decodeCursor(h.cfg.JWTSecret, c.Query("cursor"), "conversations")
+	if err != nil {
+		return invalidCursorError(c)
+	}
 
+	query := h.db.Where("user_id = ?", userID).Preload("App", withDeletedApps).Order("updated_at DESC, id DESC")
+	if cursor != nil {
+		query = afterTimeCursor(query, "updated_at", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
 	var convs []models.Conversation
-	var total int64
-	h.db.Model(&models.Conversation{}).Where("user_id = ?", userID).Count(&total)
-	h.db.Where("user_id = ?", userID).Preload("App").Order("updated_at DESC").
-		Offset(offset).Limit(limit).Find(&convs)
+	query.Limit(limit + 1).Find(&convs)
+
+	hasMore := len(convs) > limit
+	if hasMore {
+		convs = convs[:limit]
+	}
+	var nextCursor interface{}
+	if len(convs) > 0 {
+		last := convs[len(convs)-1]
+		nextCursor = nextTimeCursor(h.cfg.JWTSecret, "conversations", hasMore, last.UpdatedAt, last.ID)
+	}
 
 	result := make([]fiber.Map, 0, len(convs))
 	for _, conv := range convs {
@@ -45,7 +65,7 @@ func (h *ConversationsHandler) ListConversations(c *fiber.Ctx) error {
 		var lastMsgMap fiber.Map
 		if lastMsg.ID > 0 {
 			lastMsgMap = fiber.Map{
-				"id": fmt.Sprintf("msg_%d", lastMsg.ID),
+				"id": publicid.Format(publicid.Message, lastMsg.ID),
 				"content":    json.RawMessage(lastMsg.Content),
 				"timestamp":  lastMsg.CreatedAt.UnixMilli(),
 				"senderType": lastMsg.SenderType,
@@ -53,9 +73,9 @@ func (h *ConversationsHandler) ListConversations(c *fiber.Ctx) error {
 		}
 
 		result = append(result, fiber.Map{
-			"id":          fmt.Sprintf("conv_%d", conv.ID),
-			"appId":       fmt.Sprintf("app_%d", conv.AppID),
-			"userId":      fmt.Sprintf("user_%d", conv.UserID),
+			"id":          publicid.Format(publicid.Conversation, conv.ID),
+			"appId":       publicid.Format(publicid.App, conv.AppID),
+			"userId":      publicid.Format(publicid.User, conv.UserID),
 			"appName":     conv.App.Title,
 			"appIcon":     conv.App.Icon,
 			"appIconUrl":  conv.App.IconURL,
@@ -65,27 +85,43 @@ func (h *ConversationsHandler) ListConversations(c *fiber.Ctx) error {
 			"createdAt":   conv.CreatedAt,
 			"updatedAt":   conv.UpdatedAt,
 			"isActive":    conv.IsActive,
+
+			"isAppAvailable":       conv.App.UnavailableReason() == "",
+			"appUnavailableReason": conv.App.UnavailableReason(),
 		})
 	}
 
-	totalPages := int(total) / limit
-	if int(total)%limit > 0 {
-		totalPages++
+	pagination := fiber.Map{"limit": limit, "hasMore": hasMore, "nextCursor": nextCursor}
+	if cursor == nil {
+		// Page-based fields for clients that haven't moved to cursors yet
+		var total int64
+		h.db.Model(&models.Conversation{}).Where("user_id = ?", userID).Count(&total)
+		totalPages := int(total) / limit
+		if int(total)%limit > 0 {
+			totalPages++
+		}
+		pagination["currentPage"], pagination["totalPages"], pagination["totalItems"] = page, totalPages, total
 	}
 
-	return c.JSON(fiber.Map{
-		"conversations": result,
-		"pagination": fiber.Map{
-			"currentPage": page, "totalPages": totalPages,
-			"totalItems": total, "hasMore": page < totalPages,
-		},
-	})
+	return c.JSON(fiber.Map{"conversations": result, "pagination": pagination})
 }
 
 // StartConversation — POST /api/apps/:appId/conversations
+// (also POST /api/conversations with the app in the body)
 func (h *ConversationsHandler) StartConversation(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID, _ := strconv.Atoi(c.Params("appId"))
+
+	var input struct {
+		AppID          string `json:"appId"`
+		InitialMessage string `json:"initialMessage"`
+	}
+	c.BodyParser(&input)
+
+	rawAppID := c.Params("appId", input.AppID)
+	appID, err := publicid.Parse(rawAppID, publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var app models.MiniApp
 	if err := h.db.First(&app, appID).Error; err != nil {
@@ -97,11 +133,9 @@ func (h *ConversationsHandler) StartConversation(c *fiber.Ctx) error {
 	if err := h.db.Where("user_id = ? AND app_id = ?", userID, appID).First(&existing).Error; err == nil {
 		return c.JSON(fiber.Map{"success": true, "conversation": formatConversation(existing, app)})
 	}
-
-	var input struct {
-		InitialMessage string `json:"initialMessage"`
+	if reason := app.UnavailableReason(); reason != "" && !hasAppRole(h.db, app, userID, models.OrgRoleAnalyst) {
+		return appUnavailableError(c, reason)
 	}
-	c.BodyParser(&input)
 
 	now := time.Now()
 	conv := models.Conversation{
@@ -114,6 +148,8 @@ func (h *ConversationsHandler) StartConversation(c *fiber.Ctx) error {
 	if h.db.Where("user_id = ? AND app_id = ?", userID, appID).First(&appUser).Error != nil {
 		h.db.Create(&models.AppUser{UserID: userID, AppID: uint(appID), LastUsed: now})
 		h.db.Model(&app).UpdateColumn("users_count", gorm.Expr("users_count + 1"))
+	} else {
+		h.db.Model(&appUser).Updates(map[string]interface{}{"last_used": now, "hidden_at": nil})
 	}
 
 	// Welcome message
@@ -127,7 +163,7 @@ func (h *ConversationsHandler) StartConversation(c *fiber.Ctx) error {
 		}
 		h.db.Create(&msg)
 		welcomeMsg = fiber.Map{
-			"id": fmt.Sprintf("msg_%d", msg.ID),
+			"id": publicid.Format(publicid.Message, msg.ID),
 			"content": json.RawMessage(msg.Content),
 			"senderType": "bot", "timestamp": msg.CreatedAt.UnixMilli(),
 		}
@@ -143,18 +179,24 @@ func (h *ConversationsHandler) StartConversation(c *fiber.Ctx) error {
 // GetMessages — GET /api/conversations/:conversationId/messages
 func (h *ConversationsHandler) GetMessages(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	convID, _ := strconv.Atoi(c.Params("conversationId"))
+	convID, err := paramID(c, "conversationId", publicid.Conversation)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 	limit, _ := strconv.Atoi(c.Query("limit", "50"))
 	before := c.Query("before")
 
 	var conv models.Conversation
-	if err := h.db.Where("id = ? AND user_id = ?", convID, userID).First(&conv).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_id = ?", convID, userID).Preload("App", withDeletedApps).First(&conv).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Conversation not found"}})
 	}
 
-	query := h.db.Where("conversation_id = ?", convID)
+	query := h.db.Where("conversation_id = ? AND hidden_at IS NULL", convID)
 	if before != "" {
-		beforeID, _ := strconv.Atoi(before)
+		beforeID, err := publicid.Parse(before, publicid.Message)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
 		query = query.Where("id < ?", beforeID)
 	}
 
@@ -164,53 +206,71 @@ func (h *ConversationsHandler) GetMessages(c *fiber.Ctx) error {
 	result := make([]fiber.Map, 0, len(messages))
 	for _, msg := range messages {
 		result = append(result, fiber.Map{
-			"id": fmt.Sprintf("msg_%d", msg.ID),
-			"appId":          fmt.Sprintf("app_%d", msg.AppID),
-			"conversationId": fmt.Sprintf("conv_%d", msg.ConversationID),
+			"id": publicid.Format(publicid.Message, msg.ID),
+			"appId":          publicid.Format(publicid.App, msg.AppID),
+			"conversationId": publicid.Format(publicid.Conversation, msg.ConversationID),
 			"senderId":       msg.SenderID,
 			"senderType":     msg.SenderType,
 			"content":        json.RawMessage(msg.Content),
 			"timestamp":      msg.CreatedAt.UnixMilli(),
 			"status":         msg.Status,
-			"replyToId":      msg.ReplyToID,
+			"replyToId":      formatOptionalID(publicid.Message, msg.ReplyToID),
 			"metadata":       msg.Metadata,
 		})
 	}
 
 	var totalCount int64
-	h.db.Model(&models.ChatMessage{}).Where("conversation_id = ?", convID).Count(&totalCount)
+	h.db.Model(&models.ChatMessage{}).Where("conversation_id = ? AND hidden_at IS NULL", convID).Count(&totalCount)
 
 	return c.JSON(fiber.Map{
 		"messages":   result,
 		"hasMore":    len(messages) == limit,
 		"pagination": fiber.Map{"totalItems": totalCount},
+
+		"isAppAvailable":       conv.App.UnavailableReason() == "",
+		"appUnavailableReason": conv.App.UnavailableReason(),
 	})
 }
 
 // SendMessage — POST /api/conversations/:conversationId/messages
 func (h *ConversationsHandler) SendMessage(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	convID, _ := strconv.Atoi(c.Params("conversationId"))
+	convID, err := paramID(c, "conversationId", publicid.Conversation)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var conv models.Conversation
-	if err := h.db.Where("id = ? AND user_id = ?", convID, userID).Preload("App").First(&conv).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_id = ?", convID, userID).Preload("App", withDeletedApps).First(&conv).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Conversation not found"}})
 	}
+	if reason := conv.App.UnavailableReason(); reason != "" && !hasAppRole(h.db, conv.App, userID, models.OrgRoleAnalyst) {
+		return appUnavailableError(c, reason)
+	}
 
 	var input struct {
 		Content   json.RawMessage `json:"content"`
-		ReplyToID *uint           `json:"replyToId"`
+		ReplyToID json.RawMessage `json:"replyToId"` // "msg_9" (or a bare number from older clients)
 		Metadata  json.RawMessage `json:"metadata"`
 	}
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid body"}})
 	}
 
+	var replyToID *uint
+	if raw := strings.Trim(string(input.ReplyToID), `"`); raw != "" && raw != "null" {
+		id, err := publicid.Parse(raw, publicid.Message)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		replyToID = &id
+	}
+
 	msg := models.ChatMessage{
 		ConversationID: uint(convID), AppID: conv.AppID,
-		SenderID: fmt.Sprintf("user_%d", userID), SenderType: "user",
+		SenderID: publicid.Format(publicid.User, userID), SenderType: "user",
 		Content: string(input.Content), Status: "sent",
-		ReplyToID: input.ReplyToID,
+		ReplyToID: replyToID,
 	}
 	if input.Metadata != nil {
 		msg.Metadata = string(input.Metadata)
@@ -228,7 +288,7 @@ func (h *ConversationsHandler) SendMessage(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": fiber.Map{
-			"id": fmt.Sprintf("msg_%d", msg.ID),
+			"id": publicid.Format(publicid.Message, msg.ID),
 			"content": json.RawMessage(msg.Content),
 			"senderType": "user", "timestamp": msg.CreatedAt.UnixMilli(), "status": msg.Status,
 		},
@@ -238,12 +298,18 @@ func (h *ConversationsHandler) SendMessage(c *fiber.Ctx) error {
 // ButtonCallback — POST /api/conversations/:conversationId/callback
 func (h *ConversationsHandler) ButtonCallback(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	convID, _ := strconv.Atoi(c.Params("conversationId"))
+	convID, err := paramID(c, "conversationId", publicid.Conversation)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var conv models.Conversation
-	if err := h.db.Where("id = ? AND user_id = ?", convID, userID).Preload("App").First(&conv).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_id = ?", convID, userID).Preload("App", withDeletedApps).First(&conv).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Conversation not found"}})
 	}
+	if reason := conv.App.UnavailableReason(); reason != "" && !hasAppRole(h.db, conv.App, userID, models.OrgRoleAnalyst) {
+		return appUnavailableError(c, reason)
+	}
 
 	var input struct {
 		MessageID string `json:"messageId"`
@@ -265,7 +331,10 @@ func (h *ConversationsHandler) ButtonCallback(c *fiber.Ctx) error {
 // MarkAsRead — POST /api/conversations/:conversationId/read
 func (h *ConversationsHandler) MarkAsRead(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	convID, _ := strconv.Atoi(c.Params("conversationId"))
+	convID, err := paramID(c, "conversationId", publicid.Conversation)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 	h.db.Model(&models.Conversation{}).Where("id = ? AND user_id = ?", convID, userID).
 		Update("unread_count", 0)
 	h.db.Model(&models.ChatMessage{}).Where("conversation_id = ? AND sender_type = ? AND status != ?", convID, "bot", "read").
@@ -276,7 +345,10 @@ func (h *ConversationsHandler) MarkAsRead(c *fiber.Ctx) error {
 // DeleteConversation — DELETE /api/conversations/:conversationId
 func (h *ConversationsHandler) DeleteConversation(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	convID, _ := strconv.Atoi(c.Params("conversationId"))
+	convID, err := paramID(c, "conversationId", publicid.Conversation)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var conv models.Conversation
 	if err := h.db.Where("id = ? AND user_id = ?", convID, userID).Preload("App").First(&conv).Error; err != nil {
@@ -296,26 +368,39 @@ func (h *ConversationsHandler) DeleteConversation(c *fiber.Ctx) error {
 
 func formatConversation(conv models.Conversation, app models.MiniApp) fiber.Map {
 	return fiber.Map{
-		"id": fmt.Sprintf("conv_%d", conv.ID), "appId": fmt.Sprintf("app_%d", conv.AppID),
+		"id": publicid.Format(publicid.Conversation, conv.ID), "appId": publicid.Format(publicid.App, conv.AppID),
 		"appName": app.Title, "appIcon": app.Icon, "appIconUrl": app.IconURL,
 		"unreadCount": conv.UnreadCount, "isActive": conv.IsActive,
 		"createdAt": conv.CreatedAt, "updatedAt": conv.UpdatedAt,
+		"isAppAvailable": app.UnavailableReason() == "", "appUnavailableReason": app.UnavailableReason(),
 	}
 }
 
+// withDeletedApps preloads a conversation's app even after it was deleted, so
+// the chat can show it as unavailable instead of blank
+func withDeletedApps(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func appUnavailableError(c *fiber.Ctx, reason string) error {
+	return c.Status(409).JSON(fiber.Map{"error": fiber.Map{
+		"code": "APP_UNAVAILABLE", "message": "This app is unavailable", "reason": reason,
+	}})
+}
+
 func triggerConvWebhook(db *gorm.DB, app models.MiniApp, conv models.Conversation, msg models.ChatMessage, event string) {
 	payload := fiber.Map{
 		"event": event, "timestamp": time.Now().UnixMilli(),
 		"data": fiber.Map{
-			"conversationId": fmt.Sprintf("conv_%d", conv.ID),
-			"userId":         fmt.Sprintf("user_%d", conv.UserID),
+			"conversationId": publicid.Format(publicid.Conversation, conv.ID),
+			"userId":         publicid.Format(publicid.User, conv.UserID),
 		},
 	}
 	if msg.ID > 0 {
 		payload["data"] = fiber.Map{
-			"conversationId": fmt.Sprintf("conv_%d", conv.ID),
+			"conversationId": publicid.Format(publicid.Conversation, conv.ID),
 			"message": fiber.Map{
-				"id": fmt.Sprintf("msg_%d", msg.ID), "senderId": msg.SenderID,
+				"id": publicid.Format(publicid.Message, msg.ID), "senderId": msg.SenderID,
 				"senderType": msg.SenderType, "content": json.RawMessage(msg.Content),
 				"timestamp": msg.CreatedAt.UnixMilli(),
 			},
@@ -330,9 +415,9 @@ func triggerCallbackWebhook(db *gorm.DB, app models.MiniApp, conv models.Convers
 	data := fiber.Map{
 		"event": "callback.received", "timestamp": time.Now().UnixMilli(),
 		"data": fiber.Map{
-			"conversationId": fmt.Sprintf("conv_%d", conv.ID),
+			"conversationId": publicid.Format(publicid.Conversation, conv.ID),
 			"messageId": msgID, "buttonId": btnID, "payload": payload,
-			"userId": fmt.Sprintf("user_%d", userID),
+			"userId": publicid.Format(publicid.User, userID),
 		},
 	}
 	body, _ := json.Marshal(data)
@@ -2,9 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -51,7 +51,7 @@ func (h *CrashHandler) ReportCrash(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"data": fiber.Map{
-			"id": fmt.Sprintf("crash_%d", report.ID), "appId": "solafon-android",
+			"id": publicid.Format(publicid.Crash, report.ID), "appId": "solafon-android",
 			"errorType": report.ErrorType, "message": report.Message, "createdAt": report.CreatedAt,
 		},
 	})
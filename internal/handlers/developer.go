@@ -5,15 +5,18 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fasad/solanafon-back/internal/config"
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -29,10 +32,12 @@ func NewDeveloperHandler(db *gorm.DB, cfg *config.Config) *DeveloperHandler {
 }
 
 // ListMyApps — GET /api/developer/apps
+// Personal apps plus the apps of every organization the user belongs to.
 func (h *DeveloperHandler) ListMyApps(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Preload("Category").Find(&apps)
+	teamApps(h.db, userID, models.OrgRoleAnalyst).Preload("Category").Find(&apps)
+	roles := teamRoles(h.db, apps, userID)
 
 	var totalUsers int
 	var approved, pending, rejected int64
@@ -51,13 +56,16 @@ func (h *DeveloperHandler) ListMyApps(c *fiber.Ctx) error {
 	result := make([]fiber.Map, len(apps))
 	for i, a := range apps {
 		result[i] = fiber.Map{
-			"id": fmt.Sprintf("app_%d", a.ID), "name": a.Title,
+			"id": publicid.Format(publicid.App, a.ID), "name": a.Title,
 			"description": a.Description, "icon": a.Icon, "iconUrl": a.IconURL,
 			"category": a.Category.Slug, "url": a.URL,
 			"status": string(a.ModerationStatus), "isVerified": a.IsVerified,
 			"users": a.FormatUsersCount(), "usersCount": a.UsersCount,
 			"rating": a.Rating, "createdAt": a.CreatedAt, "updatedAt": a.UpdatedAt,
 			"moderationNote": a.ModerationNote,
+			"organizationId": formatOptionalID(publicid.Organization, a.OrganizationID),
+			"isPublished":    a.UnpublishedAt == nil,
+			"role":           roles[a.ID],
 		}
 	}
 
@@ -80,11 +88,49 @@ func (h *DeveloperHandler) CreateApp(c *fiber.Ctx) error {
 		IconURL  string `json:"iconUrl"`
 		Category string `json:"category"`
 		URL      string `json:"url"`
+		Permissions []string `json:"permissions"`
+		LongDescription string   `json:"longDescription"`
+		Tags            []string `json:"tags"`
+		Screenshots     []string `json:"screenshots"`
+		OrganizationID  string   `json:"organizationId"` // Empty for a personal app
 	}
 	if err := c.BodyParser(&input); err != nil || input.Name == "" {
 		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "name is required"}})
 	}
 
+	var orgID *uint
+	if input.OrganizationID != "" {
+		id, err := publicid.Parse(input.OrganizationID, publicid.Organization)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		role, ok := orgRole(h.db, id, userID)
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Organization not found"}})
+		}
+		if !role.AtLeast(models.OrgRoleDeveloper) {
+			return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "Your role in this organization doesn't allow creating apps"}})
+		}
+		orgID = &id
+	}
+
+	permissions, err := encodePermissions(input.Permissions)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	}
+	longDescription, err := cleanLongDescription(input.LongDescription)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	}
+	tags, err := encodeTags(input.Tags)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	}
+	screenshots, err := encodeScreenshots(h.db, userID, input.Screenshots, "")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	}
+
 	// Resolve category
 	var category models.Category
 	h.db.Where("slug = ? OR name = ?", input.Category, input.Category).First(&category)
@@ -97,18 +143,20 @@ func (h *DeveloperHandler) CreateApp(c *fiber.Ctx) error {
 
 	app := models.MiniApp{
 		Title: input.Name, Description: input.Description, Icon: input.Icon, IconURL: input.IconURL,
-		CategoryID: category.ID, URL: input.URL, CreatorID: userID,
-		APIToken: apiKey, WebhookSecret: webhookSecret,
-		ModerationStatus: models.ModerationPending,
+		CategoryID: category.ID, URL: input.URL, CreatorID: userID, OrganizationID: orgID,
+		APIToken: apiKey, WebhookSecret: webhookSecret, Permissions: permissions,
+		LongDescription: longDescription, Tags: tags, Screenshots: screenshots,
+		ModerationStatus: models.ModerationPending, IsVerified: developerVerified(h.db, userID),
 	}
 	h.db.Create(&app)
+	resubmitRelease(h.db, app, userID)
 
 	return c.Status(201).JSON(fiber.Map{
 		"success": true,
 		"app":     formatDevApp(app),
 		"message": "App created successfully",
 		"apiKey":  apiKey,
-		"keyId":   fmt.Sprintf("key_%d", app.ID),
+		"keyId":   publicid.Format(publicid.APIKey, app.ID),
 		"apiKeyHint": apiKey[:12] + "...",
 	})
 }
@@ -116,115 +164,224 @@ func (h *DeveloperHandler) CreateApp(c *fiber.Ctx) error {
 // GetApp — GET /api/developer/apps/:appId
 func (h *DeveloperHandler) GetApp(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).Preload("Category").First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAnalyst)
+	if err != nil {
+		return appAccessError(c, err)
 	}
+	h.db.First(&app.Category, app.CategoryID)
 	return c.JSON(formatDevApp(app))
 }
 
 // UpdateApp — PATCH /api/developer/apps/:appId
 func (h *DeveloperHandler) UpdateApp(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleDeveloper)
+	if err != nil {
+		return appAccessError(c, err)
 	}
 
-	var input struct {
-		Name        *string `json:"name"`
-		Description *string `json:"description"`
-		Icon        *string `json:"icon"`
-		IconURL     *string `json:"iconUrl"`
-		Category    *string `json:"category"`
-		URL         *string `json:"url"`
+	var input releaseInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
 	}
-	c.BodyParser(&input)
 
-	needsRemoderation := false
-	if input.Name != nil {
-		app.Title = *input.Name
-		needsRemoderation = true
+	// A live app keeps its published listing; edits go to the draft release
+	if app.ModerationStatus == models.ModerationApproved {
+		release, err := stageRelease(h.db, app, userID, func(r *models.AppRelease) error {
+			return applyReleaseInput(h.db, userID, r, input, app.Version)
+		})
+		if err != nil {
+			return releaseError(c, err)
+		}
+		return c.JSON(fiber.Map{
+			"success": true, "app": formatDevApp(app), "draftRelease": formatRelease(release, app),
+			"message": fmt.Sprintf("Changes saved to draft %s. Submit it for review to publish.", release.Version),
+		})
 	}
-	if input.Description != nil {
-		app.Description = *input.Description
-		needsRemoderation = true
+
+	// Not live yet — edit the listing directly and send it (back) to review
+	input.Version, input.Changelog = nil, nil
+	listing := releaseFromApp(app)
+	if err := applyReleaseInput(h.db, userID, &listing, input, app.Version); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
 	}
-	if input.Icon != nil {
-		app.Icon = *input.Icon
+	setListing(&app, listing)
+	app.ModerationStatus = models.ModerationPending
+
+	h.db.Save(&app)
+	resubmitRelease(h.db, app, userID)
+	return c.JSON(fiber.Map{"success": true, "app": formatDevApp(app)})
+}
+
+// DeleteApp — DELETE /api/developer/apps/:appId
+func (h *DeveloperHandler) DeleteApp(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
 	}
-	if input.IconURL != nil {
-		app.IconURL = *input.IconURL
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleOwner)
+	if err != nil {
+		return appAccessError(c, err)
 	}
-	if input.URL != nil {
-		app.URL = *input.URL
+	h.db.Delete(&app)
+	return c.JSON(fiber.Map{
+		"success": true, "restorableUntil": time.Now().Add(models.AppRestoreWindow),
+	})
+}
+
+// ListDeletedApps — GET /api/developer/apps/deleted
+// Deleted apps the caller owns that can still be restored.
+func (h *DeveloperHandler) ListDeletedApps(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var apps []models.MiniApp
+	teamApps(h.db.Unscoped(), userID, models.OrgRoleOwner).Preload("Category").
+		Where("deleted_at > ?", time.Now().Add(-models.AppRestoreWindow)).
+		Order("deleted_at DESC").Find(&apps)
+
+	result := make([]fiber.Map, len(apps))
+	for i, a := range apps {
+		result[i] = formatDevApp(a)
+		result[i]["deletedAt"] = a.DeletedAt.Time
+		result[i]["restorableUntil"] = a.DeletedAt.Time.Add(models.AppRestoreWindow)
 	}
-	if input.Category != nil {
-		var cat models.Category
-		if h.db.Where("slug = ? OR name = ?", *input.Category, *input.Category).First(&cat).Error == nil {
-			app.CategoryID = cat.ID
-		}
+	return c.JSON(fiber.Map{"success": true, "apps": result})
+}
+
+// RestoreApp — POST /api/developer/apps/:appId/restore (owner, within 30 days of deletion)
+func (h *DeveloperHandler) RestoreApp(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
 	}
 
-	if needsRemoderation {
-		app.ModerationStatus = models.ModerationPending
+	var app models.MiniApp
+	if err := h.db.Unscoped().Where("deleted_at > ?", time.Now().Add(-models.AppRestoreWindow)).
+		First(&app, appID).Error; err != nil || !hasAppRole(h.db, app, userID, models.OrgRoleOwner) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "No restorable app found"}})
 	}
 
-	h.db.Save(&app)
+	h.db.Unscoped().Model(&app).Update("deleted_at", nil)
+	app.DeletedAt = gorm.DeletedAt{}
+	h.db.First(&app.Category, app.CategoryID)
 	return c.JSON(fiber.Map{"success": true, "app": formatDevApp(app)})
 }
 
-// DeleteApp — DELETE /api/developer/apps/:appId
-func (h *DeveloperHandler) DeleteApp(c *fiber.Ctx) error {
+// UnpublishApp — POST /api/developer/apps/:appId/unpublish (admin)
+// Takes the app off the marketplace without touching its moderation status;
+// existing users see it as unavailable until it is republished.
+func (h *DeveloperHandler) UnpublishApp(c *fiber.Ctx) error {
+	return h.setPublished(c, false)
+}
+
+// RepublishApp — POST /api/developer/apps/:appId/republish (admin)
+func (h *DeveloperHandler) RepublishApp(c *fiber.Ctx) error {
+	return h.setPublished(c, true)
+}
+
+func (h *DeveloperHandler) setPublished(c *fiber.Ctx, published bool) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	h.db.Where("id = ? AND creator_id = ?", appID, userID).Delete(&models.MiniApp{})
-	return c.JSON(fiber.Map{"success": true})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAdmin)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	if published != (app.UnpublishedAt == nil) {
+		var unpublishedAt *time.Time
+		if !published {
+			now := time.Now()
+			unpublishedAt = &now
+		}
+		h.db.Model(&app).Update("unpublished_at", unpublishedAt)
+		app.UnpublishedAt = unpublishedAt
+	}
+
+	h.db.First(&app.Category, app.CategoryID)
+	return c.JSON(fiber.Map{"success": true, "app": formatDevApp(app)})
 }
 
 // GenerateAPIKey — POST /api/developer/apps/:appId/api-key
 func (h *DeveloperHandler) GenerateAPIKey(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAdmin)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+	var input struct {
+		ExpiresInDays *int `json:"expiresInDays"` // Omit for a key that doesn't expire
+	}
+	c.BodyParser(&input)
+	if input.ExpiresInDays != nil && (*input.ExpiresInDays < 1 || *input.ExpiresInDays > 365) {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "expiresInDays must be between 1 and 365"}})
 	}
 
+	before := credentialAudit(app)
 	newKey := models.GenerateAPIToken()
 	newSecret := generateWebhookSecret()
 	app.APIToken = newKey
 	app.WebhookSecret = newSecret
-	h.db.Save(&app)
+	app.APITokenExpiresAt, app.APITokenExpiryNotifiedAt = nil, nil
+	if input.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *input.ExpiresInDays)
+		app.APITokenExpiresAt = &expiresAt
+	}
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&app).Error; err != nil {
+			return err
+		}
+		return recordAppAudit(tx, c, app, models.AuditAPIKeyGenerated, before, credentialAudit(app))
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to generate key"}})
+	}
 
 	return c.JSON(fiber.Map{
 		"success": true, "apiKey": newKey, "apiSecret": newSecret,
-		"keyId": fmt.Sprintf("key_%d", app.ID), "message": "Key generated",
+		"keyId": publicid.Format(publicid.APIKey, app.ID), "expiresAt": app.APITokenExpiresAt,
+		"message": "Key generated",
 	})
 }
 
 // ListAPICredentials — GET /api/developer/apps/:appId/api-key
 func (h *DeveloperHandler) ListAPICredentials(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAdmin)
+	if err != nil {
+		return appAccessError(c, err)
 	}
 
 	hint := ""
-	if len(app.APIToken) > 12 {
+	if app.HasAPIToken() && len(app.APIToken) > 12 {
 		hint = app.APIToken[:12] + "..."
 	}
 
 	return c.JSON(fiber.Map{
 		"credentials": []fiber.Map{{
-			"id": fmt.Sprintf("key_%d", app.ID), "appId": fmt.Sprintf("app_%d", app.ID),
+			"id": publicid.Format(publicid.APIKey, app.ID), "appId": publicid.Format(publicid.App, app.ID),
 			"apiKeyPrefix": hint, "webhookUrl": app.WebhookURL,
-			"webhookSecret": app.WebhookSecret, "isActive": true,
-			"createdAt": app.CreatedAt,
+			"webhookSecret": app.WebhookSecret, "webhookDisabledAt": app.WebhookDisabledAt,
+			"isActive":  app.HasAPIToken() && (app.APITokenExpiresAt == nil || time.Now().Before(*app.APITokenExpiresAt)),
+			"expiresAt": app.APITokenExpiresAt, "createdAt": app.CreatedAt,
 		}},
 	})
 }
@@ -232,23 +389,38 @@ func (h *DeveloperHandler) ListAPICredentials(c *fiber.Ctx) error {
 // RevokeAPIKey — DELETE /api/developer/apps/:appId/credentials/:keyId
 func (h *DeveloperHandler) RevokeAPIKey(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAdmin)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+	before := credentialAudit(app)
+	app.APIToken = models.RevokedAPIToken(app.ID)
+	app.APITokenExpiresAt, app.APITokenExpiryNotifiedAt = nil, nil
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&app).Error; err != nil {
+			return err
+		}
+		return recordAppAudit(tx, c, app, models.AuditAPIKeyRevoked, before, credentialAudit(app))
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to revoke key"}})
 	}
-	app.APIToken = ""
-	h.db.Save(&app)
 	return c.JSON(fiber.Map{"success": true})
 }
 
 // UpdateWebhook — PUT /api/developer/apps/:appId/webhook
 func (h *DeveloperHandler) UpdateWebhook(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleDeveloper)
+	if err != nil {
+		return appAccessError(c, err)
 	}
 
 	var input struct {
@@ -257,11 +429,24 @@ func (h *DeveloperHandler) UpdateWebhook(c *fiber.Ctx) error {
 	}
 	c.BodyParser(&input)
 
+	before := webhookAudit(app)
 	app.WebhookURL = input.WebhookURL
+	app.WebhookFailures, app.WebhookDisabledAt = 0, nil
 	if app.WebhookSecret == "" {
 		app.WebhookSecret = generateWebhookSecret()
 	}
-	h.db.Save(&app)
+	action := models.AuditWebhookUpdated
+	if app.WebhookURL == "" {
+		action = models.AuditWebhookDeleted
+	}
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&app).Error; err != nil {
+			return err
+		}
+		return recordAppAudit(tx, c, app, action, before, webhookAudit(app))
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update webhook"}})
+	}
 
 	return c.JSON(fiber.Map{"success": true, "webhookSecret": app.WebhookSecret})
 }
@@ -269,15 +454,18 @@ func (h *DeveloperHandler) UpdateWebhook(c *fiber.Ctx) error {
 // GetWelcomeMessage — GET /api/developer/apps/:appId/welcome-message
 func (h *DeveloperHandler) GetWelcomeMessage(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAnalyst)
+	if err != nil {
+		return appAccessError(c, err)
 	}
 
 	return c.JSON(fiber.Map{
 		"welcomeMessage": fiber.Map{
-			"id": fmt.Sprintf("wm_%d", app.ID), "appId": fmt.Sprintf("app_%d", app.ID),
+			"id": publicid.Format(publicid.WelcomeMessage, app.ID), "appId": publicid.Format(publicid.App, app.ID),
 			"content": fiber.Map{"type": "text", "text": app.WelcomeMessage},
 			"isActive": app.WelcomeMessage != "", "createdAt": app.CreatedAt,
 		},
@@ -287,10 +475,13 @@ func (h *DeveloperHandler) GetWelcomeMessage(c *fiber.Ctx) error {
 // UpdateWelcomeMessage — PUT /api/developer/apps/:appId/welcome-message
 func (h *DeveloperHandler) UpdateWelcomeMessage(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID := c.Params("appId")
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleDeveloper)
+	if err != nil {
+		return appAccessError(c, err)
 	}
 
 	var input struct {
@@ -346,6 +537,12 @@ func (h *DeveloperHandler) Upload(c *fiber.Ctx) error {
 	}
 
 	url := fmt.Sprintf("%s/uploads/%s", h.cfg.BaseURL, filename)
+	userID, _ := c.Locals("userID").(uint)
+	h.db.Create(&models.Upload{
+		UserID: userID, Filename: filename, URL: url,
+		Size: file.Size, ContentType: file.Header.Get("Content-Type"),
+	})
+
 	return c.JSON(fiber.Map{
 		"url": url, "filename": filename,
 		"size": file.Size, "file_type": file.Header.Get("Content-Type"),
@@ -354,98 +551,185 @@ func (h *DeveloperHandler) Upload(c *fiber.Ctx) error {
 
 // Apps marketplace — enhanced endpoints
 
-// ListApps — GET /api/apps (enhanced with pagination/sorting)
+// ListApps — GET /api/apps (enhanced with pagination/sorting).
+// With ?search= results are ordered by search relevance and carry highlights.
 func (h *DeveloperHandler) ListApps(c *fiber.Ctx) error {
 	category := c.Query("category")
-	search := c.Query("search")
+	search := strings.TrimSpace(c.Query("search"))
 	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	limit := pageLimit(c)
 	sortBy := c.Query("sortBy", "popular")
+	if sortBy != "new" && sortBy != "trending" {
+		sortBy = "popular"
+	}
 	if page < 1 {
 		page = 1
 	}
 	offset := (page - 1) * limit
 
-	query := h.db.Model(&models.MiniApp{}).Where("moderation_status = ?", "approved")
-
+	var categoryID uint
 	if category != "" && category != "all" {
 		var cat models.Category
 		if h.db.Where("slug = ?", category).First(&cat).Error == nil {
-			query = query.Where("category_id = ?", cat.ID)
+			categoryID = cat.ID
 		}
 	}
-	if search != "" {
-		query = query.Where("LOWER(title) LIKE ? OR LOWER(description) LIKE ?",
-			"%"+strings.ToLower(search)+"%", "%"+strings.ToLower(search)+"%")
+	// ?tags=games,puzzle — apps carrying all of the given tags
+	var tagFilter string
+	if raw := c.Query("tags"); raw != "" {
+		tags := []string{}
+		for _, t := range strings.Split(raw, ",") {
+			if tag := normalizeTag(t); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) > 0 {
+			encoded, _ := json.Marshal(tags)
+			tagFilter = string(encoded)
+		}
 	}
 
-	var total int64
-	query.Count(&total)
+	// Cursors only continue the exact list they were issued for
+	scope := fmt.Sprintf("apps:%s:%d:%s", sortBy, categoryID, tagFilter)
+	if search != "" {
+		scope = fmt.Sprintf("apps:search:%d:%s:%s", categoryID, tagFilter, search)
+	}
+	cursor, err := decodeCursor(h.cfg.JWTSecret, c.Query("cursor"), scope)
+	if err != nil {
+		return invalidCursorError(c)
+	}
 
-	switch sortBy {
-	case "new":
-		query = query.Order("created_at DESC")
-	case "trending":
-		query = query.Where("is_trending = true").Order("users_count DESC")
-	default: // popular
-		query = query.Order("users_count DESC")
+	filter := func(q *gorm.DB) *gorm.DB {
+		q = listedApps(q)
+		if categoryID != 0 {
+			q = q.Where("category_id = ?", categoryID)
+		}
+		if tagFilter != "" {
+			q = q.Where("tags @> CAST(? AS jsonb)", tagFilter)
+		}
+		return q
 	}
 
+	var total int64
 	var apps []models.MiniApp
-	query.Preload("Category").Preload("Creator").Offset(offset).Limit(limit).Find(&apps)
+	var highlights map[uint]appSearchHit
+	var hasMore bool
+	var nextCursor interface{}
+
+	if search != "" {
+		// Relevance has no stable sort key, so search cursors carry the offset
+		if cursor != nil {
+			offset = cursor.Offset
+		}
+		hits, count, err := searchApps(h.db, search, filter, offset, limit)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Search failed"}})
+		}
+		total = count
+		apps = loadSearchHits(h.db.Preload("Creator"), hits)
+		highlights = make(map[uint]appSearchHit, len(hits))
+		for _, hit := range hits {
+			highlights[hit.ID] = hit
+		}
+		hasMore = int64(offset+len(hits)) < total
+		if hasMore {
+			nextCursor = encodeCursor(h.cfg.JWTSecret, pageCursor{Scope: scope, Offset: offset + len(hits)})
+		}
+	} else {
+		query := filter(h.db.Model(&models.MiniApp{}))
+		if sortBy == "trending" {
+			query = query.Where("trending_score > 0")
+		}
+		if cursor == nil {
+			query.Count(&total)
+			query = query.Offset(offset)
+		}
+
+		switch sortBy {
+		case "new":
+			query = afterTimeCursor(query.Order("created_at DESC, id DESC"), "created_at", cursor)
+		case "trending":
+			query = query.Order("trending_score DESC, users_count DESC, id DESC")
+			if cursor != nil && len(cursor.Nums) == 2 {
+				query = query.Where("(trending_score, users_count, id) < (?, ?, ?)", cursor.Nums[0], cursor.Nums[1], cursor.ID)
+			}
+		default: // popular
+			query = query.Order("users_count DESC, id DESC")
+			if cursor != nil && len(cursor.Nums) == 1 {
+				query = query.Where("(users_count, id) < (?, ?)", cursor.Nums[0], cursor.ID)
+			}
+		}
+
+		query.Preload("Category").Preload("Creator").Limit(limit + 1).Find(&apps)
+
+		hasMore = len(apps) > limit
+		if hasMore {
+			apps = apps[:limit]
+			last := apps[len(apps)-1]
+			next := pageCursor{Scope: scope, ID: last.ID}
+			switch sortBy {
+			case "new":
+				next.Time = last.CreatedAt
+			case "trending":
+				next.Nums = []float64{last.TrendingScore, float64(last.UsersCount)}
+			default:
+				next.Nums = []float64{float64(last.UsersCount)}
+			}
+			nextCursor = encodeCursor(h.cfg.JWTSecret, next)
+		}
+	}
 
 	result := make([]fiber.Map, len(apps))
 	for i, a := range apps {
-		var dev fiber.Map
-		if a.Creator != nil {
-			dev = fiber.Map{
-				"id": fmt.Sprintf("dev_%d", a.Creator.ID),
-				"name": a.Creator.GetDisplayName(), "isVerified": false,
+		result[i] = formatMarketApp(a)
+		if hit, ok := highlights[a.ID]; ok {
+			result[i]["highlight"] = fiber.Map{
+				"name": hit.Title, "snippet": hit.Snippet, "fuzzy": hit.Fuzzy,
 			}
 		}
-		result[i] = fiber.Map{
-			"id": fmt.Sprintf("app_%d", a.ID), "name": a.Title,
-			"description": a.Description, "icon": a.Icon, "iconUrl": a.IconURL,
-			"category": a.Category.Slug, "url": a.URL,
-			"users": a.FormatUsersCount(), "usersCount": a.UsersCount,
-			"isVerified": a.IsVerified, "isTrending": a.IsTrending,
-			"rating": a.Rating, "createdAt": a.CreatedAt, "developer": dev,
-		}
 	}
 
-	totalPages := int(total) / limit
-	if int(total)%limit > 0 {
-		totalPages++
+	pagination := fiber.Map{"limit": limit, "hasMore": hasMore, "nextCursor": nextCursor}
+	if cursor == nil {
+		// Page-based fields for clients that haven't moved to cursors yet
+		totalPages := int(total) / limit
+		if int(total)%limit > 0 {
+			totalPages++
+		}
+		pagination["currentPage"], pagination["totalPages"], pagination["totalItems"] = page, totalPages, total
 	}
 
-	return c.JSON(fiber.Map{
-		"apps": result,
-		"pagination": fiber.Map{
-			"currentPage": page, "totalPages": totalPages,
-			"totalItems": total, "hasMore": page < totalPages,
-		},
-	})
+	return c.JSON(fiber.Map{"apps": result, "pagination": pagination})
 }
 
-// GetCategories — GET /api/apps/categories
-func (h *DeveloperHandler) GetCategories(c *fiber.Ctx) error {
-	var categories []models.Category
-	h.db.Order("`order` ASC").Find(&categories)
+// ListTags — GET /api/apps/tags?q= (tag vocabulary with live app counts)
+func (h *DeveloperHandler) ListTags(c *fiber.Ctx) error {
+	var tags []struct {
+		Slug  string
+		Count int
+	}
+	query := h.db.Table("tags").
+		Select("tags.slug, COUNT(mini_apps.id) AS count").
+		Joins("LEFT JOIN mini_apps ON mini_apps.tags @> jsonb_build_array(tags.slug) AND mini_apps.moderation_status = ? AND mini_apps.unpublished_at IS NULL AND mini_apps.hidden_at IS NULL AND mini_apps.suspended_at IS NULL AND mini_apps.deleted_at IS NULL", models.ModerationApproved)
+	if q := normalizeTag(c.Query("q")); q != "" {
+		query = query.Where("tags.slug LIKE ?", q+"%")
+	}
+	query.Group("tags.slug").Order("count DESC, tags.slug ASC").Limit(50).Scan(&tags)
 
-	result := make([]fiber.Map, len(categories))
-	for i, cat := range categories {
-		var count int64
-		h.db.Model(&models.MiniApp{}).Where("category_id = ? AND moderation_status = ?", cat.ID, "approved").Count(&count)
-		result[i] = fiber.Map{
-			"id": cat.Slug, "name": cat.Name, "icon": cat.Icon, "appsCount": count,
-		}
+	result := make([]fiber.Map, len(tags))
+	for i, t := range tags {
+		result[i] = fiber.Map{"tag": t.Slug, "appsCount": t.Count}
 	}
-	return c.JSON(fiber.Map{"categories": result})
+	return c.JSON(fiber.Map{"tags": result})
 }
 
 // GetAppDetail — GET /api/apps/:appId
 func (h *DeveloperHandler) GetAppDetail(c *fiber.Ctx) error {
-	appID := c.Params("appId")
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 	var app models.MiniApp
 	if err := h.db.Preload("Category").Preload("Creator").First(&app, appID).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
@@ -454,51 +738,114 @@ func (h *DeveloperHandler) GetAppDetail(c *fiber.Ctx) error {
 	var dev fiber.Map
 	if app.Creator != nil {
 		dev = fiber.Map{
-			"id": fmt.Sprintf("dev_%d", app.Creator.ID),
-			"name": app.Creator.GetDisplayName(), "isVerified": false,
+			"id": publicid.Format(publicid.Developer, app.Creator.ID),
+			"name": app.Creator.GetDisplayName(), "isVerified": app.Creator.IsVerifiedDeveloper,
+			"orgName": app.Creator.VerifiedOrgName,
 		}
 	}
 
+	var fav models.AppFavorite
+	h.db.Where("user_id = ? AND app_id = ?", userID, app.ID).Limit(1).Find(&fav)
+
 	return c.JSON(fiber.Map{
-		"id": fmt.Sprintf("app_%d", app.ID), "name": app.Title,
+		"id": publicid.Format(publicid.App, app.ID), "name": app.Title,
 		"description": app.Description, "longDescription": app.LongDescription,
 		"icon": app.Icon, "iconUrl": app.IconURL,
 		"category": app.Category.Slug, "url": app.URL,
 		"users": app.FormatUsersCount(), "usersCount": app.UsersCount,
 		"isVerified": app.IsVerified, "isTrending": app.IsTrending,
-		"rating": app.Rating, "screenshots": app.Screenshots,
-		"tags": app.Tags, "reviewsCount": app.ReviewsCount,
+		"rating": app.Rating, "screenshots": app.ScreenshotList(),
+		"tags": app.TagList(), "reviewsCount": app.ReviewsCount,
 		"createdAt": app.CreatedAt, "updatedAt": app.UpdatedAt,
-		"permissions": app.Permissions, "version": app.Version,
-		"developer": dev,
+		"permissions": app.DeclaredPermissions(), "version": app.Version,
+		"grantedPermissions": grantedList(grantedPermissions(h.db, userID, app.ID)),
+		"developer": dev, "isFavorite": fav.ID != 0, "isPinned": fav.PinPosition != nil,
+		"isAvailable": app.UnavailableReason() == "", "unavailableReason": app.UnavailableReason(),
 	})
 }
 
 // LaunchApp — POST /api/apps/:appId/launch
 func (h *DeveloperHandler) LaunchApp(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID, _ := strconv.Atoi(c.Params("appId"))
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+	// The team can still open an unpublished app to test it
+	if reason := app.UnavailableReason(); reason != "" && !hasAppRole(h.db, app, userID, models.OrgRoleAnalyst) {
+		return appUnavailableError(c, reason)
+	}
 
 	var appUser models.AppUser
 	if h.db.Where("user_id = ? AND app_id = ?", userID, appID).First(&appUser).Error != nil {
-		h.db.Create(&models.AppUser{UserID: userID, AppID: uint(appID)})
+		h.db.Create(&models.AppUser{UserID: userID, AppID: uint(appID), LastUsed: time.Now()})
 		h.db.Model(&models.MiniApp{}).Where("id = ?", appID).UpdateColumn("users_count", gorm.Expr("users_count + 1"))
+	} else {
+		h.db.Model(&appUser).Updates(map[string]interface{}{"last_used": time.Now(), "hidden_at": nil})
+	}
+	recordAppLaunch(h.db, userID, app.ID)
+
+	var user models.User
+	h.db.First(&user, userID)
+	granted := grantedPermissions(h.db, userID, app.ID)
+
+	// Declared permissions the user hasn't decided on yet — the client asks for consent
+	pending := []string{}
+	for _, p := range app.DeclaredPermissions() {
+		if !granted[p] {
+			pending = append(pending, p)
+		}
 	}
 
-	return c.JSON(fiber.Map{"success": true, "sessionId": fmt.Sprintf("session_%d", appID)})
+	return c.JSON(fiber.Map{
+		"success": true, "sessionId": fmt.Sprintf("session_%d", appID),
+		"launchData": fiber.Map{
+			"user":               launchUserPayload(user, granted),
+			"grantedPermissions": grantedList(granted),
+		},
+		"pendingPermissions": pending,
+	})
 }
 
 // helpers
 
+// formatMarketApp formats an app for the marketplace listing
+func formatMarketApp(a models.MiniApp) fiber.Map {
+	var dev fiber.Map
+	if a.Creator != nil {
+		dev = fiber.Map{
+			"id": publicid.Format(publicid.Developer, a.Creator.ID),
+			"name": a.Creator.GetDisplayName(), "isVerified": a.Creator.IsVerifiedDeveloper,
+			"orgName": a.Creator.VerifiedOrgName,
+		}
+	}
+	return fiber.Map{
+		"id": publicid.Format(publicid.App, a.ID), "name": a.Title,
+		"description": a.Description, "icon": a.Icon, "iconUrl": a.IconURL,
+		"category": a.Category.Slug, "url": a.URL,
+		"users": a.FormatUsersCount(), "usersCount": a.UsersCount,
+		"isVerified": a.IsVerified, "isTrending": a.IsTrending,
+		"rating": a.Rating, "createdAt": a.CreatedAt, "developer": dev,
+		"tags": a.TagList(),
+	}
+}
+
 func formatDevApp(app models.MiniApp) fiber.Map {
 	return fiber.Map{
-		"id": fmt.Sprintf("app_%d", app.ID), "name": app.Title,
+		"id": publicid.Format(publicid.App, app.ID), "name": app.Title,
 		"description": app.Description, "icon": app.Icon, "iconUrl": app.IconURL,
 		"category": app.Category.Slug, "url": app.URL,
 		"status": string(app.ModerationStatus), "isVerified": app.IsVerified,
 		"users": app.FormatUsersCount(), "usersCount": app.UsersCount,
 		"rating": app.Rating, "createdAt": app.CreatedAt, "updatedAt": app.UpdatedAt,
 		"moderationNote": app.ModerationNote,
+		"organizationId": formatOptionalID(publicid.Organization, app.OrganizationID),
+		"isPublished":    app.UnpublishedAt == nil, "unpublishedAt": app.UnpublishedAt,
 	}
 }
 
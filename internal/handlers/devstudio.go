@@ -238,8 +238,7 @@ func (h *DevStudioHandler) cmdNewApp(userID uint) string {
 }
 
 func (h *DevStudioHandler) cmdMyApps(userID uint) string {
-	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Order("created_at DESC").Find(&apps)
+	apps := h.teamApps(userID, models.OrgRoleAnalyst)
 
 	if len(apps) == 0 {
 		return "У тебя пока нет приложений. Создай первое с помощью /newapp"
@@ -272,8 +271,7 @@ func (h *DevStudioHandler) cmdMyApps(userID uint) string {
 }
 
 func (h *DevStudioHandler) cmdToken(userID uint) string {
-	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Find(&apps)
+	apps := h.teamApps(userID, appActionRoles["token"])
 
 	if len(apps) == 0 {
 		return "У тебя нет приложений. Создай первое с /newapp"
@@ -288,8 +286,7 @@ func (h *DevStudioHandler) cmdToken(userID uint) string {
 }
 
 func (h *DevStudioHandler) cmdEditApp(userID uint) string {
-	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Find(&apps)
+	apps := h.teamApps(userID, appActionRoles["edit"])
 
 	if len(apps) == 0 {
 		return "У тебя нет приложений. Создай первое с /newapp"
@@ -305,8 +302,7 @@ func (h *DevStudioHandler) cmdEditApp(userID uint) string {
 }
 
 func (h *DevStudioHandler) cmdDeleteApp(userID uint) string {
-	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Find(&apps)
+	apps := h.teamApps(userID, appActionRoles["delete"])
 
 	if len(apps) == 0 {
 		return "У тебя нет приложений."
@@ -322,8 +318,7 @@ func (h *DevStudioHandler) cmdDeleteApp(userID uint) string {
 }
 
 func (h *DevStudioHandler) cmdCommands(userID uint) string {
-	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Find(&apps)
+	apps := h.teamApps(userID, appActionRoles["commands"])
 
 	if len(apps) == 0 {
 		return "У тебя нет приложений. Создай первое с /newapp"
@@ -339,8 +334,7 @@ func (h *DevStudioHandler) cmdCommands(userID uint) string {
 }
 
 func (h *DevStudioHandler) cmdWebhook(userID uint) string {
-	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Find(&apps)
+	apps := h.teamApps(userID, appActionRoles["webhook"])
 
 	if len(apps) == 0 {
 		return "У тебя нет приложений. Создай первое с /newapp"
@@ -480,7 +474,7 @@ func (h *DevStudioHandler) handleWelcome(userID uint, welcome string) string {
 		WelcomeMessage:   welcomeMsg,
 		APIToken:         apiToken,
 		ModerationStatus: models.ModerationPending,
-		IsVerified:       false,
+		IsVerified:       developerVerified(h.db, userID),
 		IsSecret:         false,
 		UsersCount:       0,
 	}
@@ -489,6 +483,7 @@ func (h *DevStudioHandler) handleWelcome(userID uint, welcome string) string {
 		h.setState(userID, StateIdle, "")
 		return "Ошибка при создании приложения. Попробуй снова с /newapp"
 	}
+	resubmitRelease(h.db, app, userID)
 
 	// Create /start command if welcome message provided
 	if welcomeMsg != "" {
@@ -529,16 +524,15 @@ func (h *DevStudioHandler) handleAppSelection(userID uint, input string) string
 		return "Введи номер приложения"
 	}
 
-	var apps []models.MiniApp
-	h.db.Where("creator_id = ?", userID).Find(&apps)
+	data := h.getStateData(userID)
+	action, _ := data["action"].(string)
+	apps := h.teamApps(userID, appActionRoles[action])
 
 	if num < 1 || num > len(apps) {
 		return "Неверный номер"
 	}
 
 	app := apps[num-1]
-	data := h.getStateData(userID)
-	action, _ := data["action"].(string)
 
 	switch action {
 	case "token":
@@ -605,13 +599,10 @@ func (h *DevStudioHandler) handleNewName(userID uint, name string) string {
 	data := h.getStateData(userID)
 	appID := uint(data["app_id"].(float64))
 
-	h.db.Model(&models.MiniApp{}).Where("id = ?", appID).Updates(map[string]interface{}{
-		"title":             name,
-		"moderation_status": models.ModerationPending,
-	})
-
 	h.setState(userID, StateIdle, "")
-	return fmt.Sprintf("✅ Название изменено на: %s\n\nПриложение отправлено на повторную модерацию.", name)
+	return h.editListing(userID, appID, func(app *models.MiniApp) {
+		app.Title = name
+	}, fmt.Sprintf("✅ Название изменено на: %s", name))
 }
 
 func (h *DevStudioHandler) handleNewDesc(userID uint, desc string) string {
@@ -622,14 +613,46 @@ func (h *DevStudioHandler) handleNewDesc(userID uint, desc string) string {
 	data := h.getStateData(userID)
 	appID := uint(data["app_id"].(float64))
 
-	h.db.Model(&models.MiniApp{}).Where("id = ?", appID).Updates(map[string]interface{}{
-		"description":       desc,
-		"subtitle":          desc,
+	h.setState(userID, StateIdle, "")
+	return h.editListing(userID, appID, func(app *models.MiniApp) {
+		app.Description = desc
+		app.Subtitle = desc
+	}, "✅ Описание обновлено!")
+}
+
+// editListing applies a listing change: straight to the app while it is not live,
+// to the draft release once it is published.
+func (h *DevStudioHandler) editListing(userID, appID uint, edit func(*models.MiniApp), done string) string {
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return "Приложение не найдено"
+	}
+
+	if app.ModerationStatus == models.ModerationApproved {
+		release, err := stageRelease(h.db, app, userID, func(r *models.AppRelease) error {
+			edited := app
+			edit(&edited)
+			r.Title, r.Subtitle, r.Description = edited.Title, edited.Subtitle, edited.Description
+			return nil
+		})
+		if err == errReleaseInReview {
+			return "Новая версия уже на модерации. Изменения можно будет внести после её проверки."
+		}
+		if err != nil {
+			return "Ошибка при сохранении изменений"
+		}
+		return fmt.Sprintf("%s\n\nИзменения сохранены в черновике версии %s. Отправь её на модерацию в кабинете разработчика — текущая версия остаётся опубликованной.", done, release.Version)
+	}
+
+	edit(&app)
+	h.db.Model(&app).Updates(map[string]interface{}{
+		"title":             app.Title,
+		"subtitle":          app.Subtitle,
+		"description":       app.Description,
 		"moderation_status": models.ModerationPending,
 	})
-
-	h.setState(userID, StateIdle, "")
-	return "✅ Описание обновлено!\n\nПриложение отправлено на повторную модерацию."
+	resubmitRelease(h.db, app, userID)
+	return done + "\n\nПриложение отправлено на повторную модерацию."
 }
 
 func (h *DevStudioHandler) handleNewCommand(userID uint, input string) string {
@@ -700,7 +723,7 @@ func (h *DevStudioHandler) handleWebhookUrl(userID uint, url string) string {
 	appID := uint(data["app_id"].(float64))
 
 	if url == "/clear" || url == "clear" {
-		h.db.Model(&models.MiniApp{}).Where("id = ?", appID).Update("webhook_url", "")
+		h.saveWebhookURL(userID, appID, "")
 		h.setState(userID, StateIdle, "")
 		return "✅ Вебхук удалён"
 	}
@@ -709,12 +732,37 @@ func (h *DevStudioHandler) handleWebhookUrl(userID uint, url string) string {
 		return "URL должен начинаться с https://"
 	}
 
-	h.db.Model(&models.MiniApp{}).Where("id = ?", appID).Update("webhook_url", url)
+	h.saveWebhookURL(userID, appID, url)
 	h.setState(userID, StateIdle, "")
 
 	return fmt.Sprintf("✅ Вебхук установлен:\n%s\n\nТеперь сообщения пользователей будут отправляться на этот URL.", url)
 }
 
+// saveWebhookURL sets or clears the webhook and records it in the audit log
+func (h *DevStudioHandler) saveWebhookURL(userID, appID uint, url string) {
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return
+	}
+	before := webhookAudit(app)
+	action := models.AuditWebhookUpdated
+	if url == "" {
+		action = models.AuditWebhookDeleted
+	}
+
+	h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&app).Updates(map[string]interface{}{
+			"webhook_url": url, "webhook_failures": 0, "webhook_disabled_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		app.WebhookURL, app.WebhookDisabledAt = url, nil
+		return recordAudit(tx, nil, models.AuditLog{
+			ActorID: &userID, Action: action, TargetType: models.AuditTargetApp, TargetID: app.ID,
+		}, before, webhookAudit(app))
+	})
+}
+
 func (h *DevStudioHandler) handleDeleteConfirm(userID uint, confirm string) string {
 	if strings.ToUpper(confirm) != "YES" {
 		h.setState(userID, StateIdle, "")
@@ -728,19 +776,32 @@ func (h *DevStudioHandler) handleDeleteConfirm(userID uint, confirm string) stri
 	h.db.First(&app, appID)
 	title := app.Title
 
-	// Delete related data
-	h.db.Where("app_id = ?", appID).Delete(&models.BotCommand{})
-	h.db.Where("app_id = ?", appID).Delete(&models.AppMessage{})
-	h.db.Where("app_id = ?", appID).Delete(&models.AppUser{})
-	h.db.Where("app_id = ?", appID).Delete(&models.WebhookLog{})
+	// Related data stays until jobs.PurgeDeletedApps, so the app can be restored
 	h.db.Delete(&app)
 
 	h.setState(userID, StateIdle, "")
-	return fmt.Sprintf("✅ Приложение \"%s\" удалено.", title)
+	return fmt.Sprintf("✅ Приложение \"%s\" удалено.\n\nЕго можно восстановить в кабинете разработчика в течение 30 дней.", title)
 }
 
 // === Helper methods ===
 
+// Minimum organization role for each app action offered in Dev Studio
+var appActionRoles = map[string]models.OrgRole{
+	"token":    models.OrgRoleAdmin,
+	"edit":     models.OrgRoleDeveloper,
+	"delete":   models.OrgRoleOwner,
+	"commands": models.OrgRoleDeveloper,
+	"webhook":  models.OrgRoleDeveloper,
+}
+
+// teamApps lists the apps the user holds at least min on, in a stable order so
+// numbered choices stay valid between messages
+func (h *DevStudioHandler) teamApps(userID uint, min models.OrgRole) []models.MiniApp {
+	var apps []models.MiniApp
+	teamApps(h.db, userID, min).Order("created_at DESC").Find(&apps)
+	return apps
+}
+
 func (h *DevStudioHandler) getState(userID uint) *models.ConversationState {
 	var state models.ConversationState
 	if err := h.db.Where("user_id = ?", userID).First(&state).Error; err != nil {
@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errPayoutTransition = errors.New("invalid payout status transition")
+
+// EarningsHandler handles developer earnings, payouts and payout administration
+type EarningsHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewEarningsHandler(db *gorm.DB, cfg *config.Config) *EarningsHandler {
+	return &EarningsHandler{db: db, cfg: cfg}
+}
+
+// GetEarnings — GET /api/developer/earnings?from=&to=&period=day|week|month&appId=
+func (h *EarningsHandler) GetEarnings(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+	if v := c.Query("from"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			from = t
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			to = t.AddDate(0, 0, 1)
+		}
+	}
+	period := c.Query("period", "day")
+	if period != "day" && period != "week" && period != "month" {
+		period = "day"
+	}
+
+	query := h.db.Model(&models.EarningEntry{}).
+		Where("developer_id = ? AND type IN ? AND created_at >= ? AND created_at < ?",
+			userID, []string{models.EarningSale, models.EarningRefund}, from, to)
+	if appID := c.Query("appId"); appID != "" {
+		id, err := publicid.Parse(appID, publicid.App)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		query = query.Where("app_id = ?", id)
+	}
+
+	type appRow struct {
+		AppID    uint
+		Gross    int
+		Fees     int
+		Net      int
+		Sales    int
+		Refunded int
+	}
+	var appRows []appRow
+	query.Session(&gorm.Session{}).
+		Select("app_id, SUM(gross_amount) AS gross, SUM(fee_amount) AS fees, SUM(amount) AS net, " +
+			"COUNT(*) FILTER (WHERE type = 'sale') AS sales, " +
+			"COALESCE(-SUM(amount) FILTER (WHERE type = 'refund'), 0) AS refunded").
+		Group("app_id").Scan(&appRows)
+
+	type seriesRow struct {
+		Period time.Time
+		Gross  int
+		Fees   int
+		Net    int
+	}
+	var seriesRows []seriesRow
+	query.Session(&gorm.Session{}).
+		Select("date_trunc(?, created_at) AS period, SUM(gross_amount) AS gross, SUM(fee_amount) AS fees, SUM(amount) AS net", period).
+		Group("period").Order("period ASC").Scan(&seriesRows)
+
+	appIDs := make([]uint, 0, len(appRows))
+	for _, r := range appRows {
+		appIDs = append(appIDs, r.AppID)
+	}
+	titles := map[uint]string{}
+	if len(appIDs) > 0 {
+		var apps []models.MiniApp
+		h.db.Unscoped().Select("id, title").Where("id IN ?", appIDs).Find(&apps)
+		for _, a := range apps {
+			titles[a.ID] = a.Title
+		}
+	}
+
+	var totalGross, totalFees, totalNet, totalRefunded int
+	byApp := make([]fiber.Map, len(appRows))
+	for i, r := range appRows {
+		totalGross += r.Gross
+		totalFees += r.Fees
+		totalNet += r.Net
+		totalRefunded += r.Refunded
+		byApp[i] = fiber.Map{
+			"appId": publicid.Format(publicid.App, r.AppID), "appName": titles[r.AppID],
+			"gross": r.Gross, "fees": r.Fees, "net": r.Net,
+			"salesCount": r.Sales, "refunded": r.Refunded,
+		}
+	}
+
+	series := make([]fiber.Map, len(seriesRows))
+	for i, r := range seriesRows {
+		series[i] = fiber.Map{
+			"period": r.Period.Format("2006-01-02"), "gross": r.Gross, "fees": r.Fees, "net": r.Net,
+		}
+	}
+
+	pending, available := developerBalance(h.db, userID)
+
+	return c.JSON(fiber.Map{
+		"currency": "MP",
+		"balance":  fiber.Map{"pending": pending, "available": available},
+		"totals": fiber.Map{
+			"gross": totalGross, "fees": totalFees, "net": totalNet, "refunded": totalRefunded,
+		},
+		"byApp":  byApp,
+		"series": series,
+		"range":  fiber.Map{"from": from.Format("2006-01-02"), "to": to.AddDate(0, 0, -1).Format("2006-01-02"), "period": period},
+		"settings": fiber.Map{
+			"platformFeePercent": h.cfg.PlatformFeePercent, "holdDays": h.cfg.EarningsHoldDays,
+			"minPayout": h.cfg.MinPayoutAmount,
+		},
+	})
+}
+
+// GetLedger — GET /api/developer/earnings/ledger
+func (h *EarningsHandler) GetLedger(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit := pageLimit(c)
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	var total int64
+	h.db.Model(&models.EarningEntry{}).Where("developer_id = ?", userID).Count(&total)
+
+	var entries []models.EarningEntry
+	h.db.Where("developer_id = ?", userID).Order("created_at DESC, id DESC").
+		Offset(offset).Limit(limit).Find(&entries)
+
+	now := time.Now()
+	result := make([]fiber.Map, len(entries))
+	for i, e := range entries {
+		item := fiber.Map{
+			"id": publicid.Format(publicid.Earning, e.ID), "type": e.Type,
+			"grossAmount": e.GrossAmount, "feeAmount": e.FeeAmount, "amount": e.Amount,
+			"availableAt": e.AvailableAt, "isAvailable": !e.AvailableAt.After(now),
+			"createdAt": e.CreatedAt,
+		}
+		if e.AppID != nil {
+			item["appId"] = publicid.Format(publicid.App, *e.AppID)
+		}
+		if e.InvoiceID != nil {
+			item["invoiceId"] = publicid.Format(publicid.Invoice, *e.InvoiceID)
+		}
+		if e.PayoutID != nil {
+			item["payoutId"] = publicid.Format(publicid.Payout, *e.PayoutID)
+		}
+		result[i] = item
+	}
+
+	return c.JSON(fiber.Map{
+		"entries":    result,
+		"pagination": fiber.Map{"page": page, "limit": limit, "total": total},
+	})
+}
+
+// ListPayouts — GET /api/developer/payouts
+func (h *EarningsHandler) ListPayouts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var payouts []models.PayoutRequest
+	h.db.Where("developer_id = ?", userID).Order("created_at DESC").Find(&payouts)
+
+	result := make([]fiber.Map, len(payouts))
+	for i, p := range payouts {
+		result[i] = formatPayout(p)
+	}
+	return c.JSON(fiber.Map{"payouts": result})
+}
+
+// GetPayout — GET /api/developer/payouts/:payoutId
+func (h *EarningsHandler) GetPayout(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	payoutID, err := paramID(c, "payoutId", publicid.Payout)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var payout models.PayoutRequest
+	if err := h.db.Where("id = ? AND developer_id = ?", payoutID, userID).First(&payout).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Payout not found"}})
+	}
+
+	result := formatPayout(payout)
+	result["history"] = h.payoutHistory(payout.ID)
+	return c.JSON(fiber.Map{"payout": result})
+}
+
+// RequestPayout — POST /api/developer/payouts
+func (h *EarningsHandler) RequestPayout(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var input struct {
+		Amount      int    `json:"amount"`
+		Destination string `json:"destination"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Amount <= 0 || input.Destination == "" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "amount and destination are required"}})
+	}
+	if input.Amount < h.cfg.MinPayoutAmount {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "PAYOUT_TOO_SMALL", "message": fmt.Sprintf("Minimum payout is %d MP", h.cfg.MinPayoutAmount)}})
+	}
+
+	var payout models.PayoutRequest
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		// Serialize payout requests per developer
+		var dev models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dev, userID).Error; err != nil {
+			return err
+		}
+
+		_, available := developerBalance(tx, userID)
+		if input.Amount > available {
+			return errInsufficientMana
+		}
+
+		payout = models.PayoutRequest{
+			DeveloperID: userID, Amount: input.Amount,
+			Destination: input.Destination, Status: models.PayoutRequested,
+		}
+		if err := tx.Create(&payout).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.EarningEntry{
+			DeveloperID: userID, PayoutID: &payout.ID, Type: models.EarningPayout,
+			Amount: -input.Amount, AvailableAt: time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PayoutStatusChange{
+			PayoutID: payout.ID, ToStatus: models.PayoutRequested, ChangedBy: userID,
+		}).Error
+	})
+	if err == errInsufficientMana {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "INSUFFICIENT_BALANCE", "message": "Amount exceeds available balance"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to request payout"}})
+	}
+
+	return c.Status(201).JSON(fiber.Map{"success": true, "payout": formatPayout(payout)})
+}
+
+// AdminListPayouts — GET /api/admin/payouts?status=
+func (h *EarningsHandler) AdminListPayouts(c *fiber.Ctx) error {
+	query := h.db.Preload("Developer")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var payouts []models.PayoutRequest
+	query.Order("created_at ASC").Limit(200).Find(&payouts)
+
+	result := make([]fiber.Map, len(payouts))
+	for i, p := range payouts {
+		result[i] = formatPayout(p)
+		result[i]["developer"] = fiber.Map{
+			"id": publicid.Format(publicid.User, p.DeveloperID), "email": p.Developer.Email,
+			"displayName": p.Developer.GetDisplayName(),
+		}
+	}
+	return c.JSON(fiber.Map{"payouts": result})
+}
+
+// AdminApprovePayout — POST /api/admin/payouts/:payoutId/approve
+func (h *EarningsHandler) AdminApprovePayout(c *fiber.Ctx) error {
+	return h.transitionPayout(c, models.PayoutApproved)
+}
+
+// AdminRejectPayout — POST /api/admin/payouts/:payoutId/reject
+func (h *EarningsHandler) AdminRejectPayout(c *fiber.Ctx) error {
+	return h.transitionPayout(c, models.PayoutRejected)
+}
+
+// AdminMarkPayoutPaid — POST /api/admin/payouts/:payoutId/paid
+func (h *EarningsHandler) AdminMarkPayoutPaid(c *fiber.Ctx) error {
+	return h.transitionPayout(c, models.PayoutPaid)
+}
+
+func (h *EarningsHandler) transitionPayout(c *fiber.Ctx, to string) error {
+	adminID := c.Locals("userID").(uint)
+	payoutID, err := paramID(c, "payoutId", publicid.Payout)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var input struct {
+		Note      string `json:"note"`
+		Reference string `json:"reference"`
+	}
+	c.BodyParser(&input)
+
+	allowed := map[string][]string{
+		models.PayoutApproved: {models.PayoutRequested},
+		models.PayoutRejected: {models.PayoutRequested, models.PayoutApproved},
+		models.PayoutPaid:     {models.PayoutApproved},
+	}
+
+	var payout models.PayoutRequest
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payout, payoutID).Error; err != nil {
+			return err
+		}
+
+		ok := false
+		for _, from := range allowed[to] {
+			if payout.Status == from {
+				ok = true
+			}
+		}
+		if !ok {
+			return errPayoutTransition
+		}
+
+		from := payout.Status
+		now := time.Now()
+		payout.Status = to
+		payout.ProcessedBy = &adminID
+		payout.ProcessedAt = &now
+		if input.Note != "" {
+			payout.Note = input.Note
+		}
+		if input.Reference != "" {
+			payout.Reference = input.Reference
+		}
+		if err := tx.Save(&payout).Error; err != nil {
+			return err
+		}
+
+		// Rejected payouts return the reserved amount to the available balance
+		if to == models.PayoutRejected {
+			if err := tx.Create(&models.EarningEntry{
+				DeveloperID: payout.DeveloperID, PayoutID: &payout.ID, Type: models.EarningPayoutReversal,
+				Amount: payout.Amount, AvailableAt: now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.PayoutStatusChange{
+			PayoutID: payout.ID, FromStatus: from, ToStatus: to, ChangedBy: adminID, Note: input.Note,
+		}).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Payout not found"}})
+	}
+	if err == errPayoutTransition {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_STATUS", "message": fmt.Sprintf("Cannot move payout from %s to %s", payout.Status, to)}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update payout"}})
+	}
+
+	result := formatPayout(payout)
+	result["history"] = h.payoutHistory(payout.ID)
+	return c.JSON(fiber.Map{"success": true, "payout": result})
+}
+
+func (h *EarningsHandler) payoutHistory(payoutID uint) []fiber.Map {
+	var changes []models.PayoutStatusChange
+	h.db.Where("payout_id = ?", payoutID).Order("created_at ASC").Find(&changes)
+
+	history := make([]fiber.Map, len(changes))
+	for i, ch := range changes {
+		history[i] = fiber.Map{
+			"fromStatus": ch.FromStatus, "toStatus": ch.ToStatus,
+			"changedBy": publicid.Format(publicid.User, ch.ChangedBy), "note": ch.Note, "createdAt": ch.CreatedAt,
+		}
+	}
+	return history
+}
+
+// helpers
+
+// developerBalance returns the pending (on hold) and available earnings of a developer
+func developerBalance(db *gorm.DB, developerID uint) (pending, available int) {
+	now := time.Now()
+	db.Model(&models.EarningEntry{}).Where("developer_id = ? AND available_at > ?", developerID, now).
+		Select("COALESCE(SUM(amount), 0)").Scan(&pending)
+	db.Model(&models.EarningEntry{}).Where("developer_id = ? AND available_at <= ?", developerID, now).
+		Select("COALESCE(SUM(amount), 0)").Scan(&available)
+	return pending, available
+}
+
+// recordSaleEarning credits the app owner for a paid invoice, minus the platform fee.
+// Runs inside the payment transaction.
+func recordSaleEarning(tx *gorm.DB, cfg *config.Config, invoice models.Invoice) error {
+	fee := invoice.Amount * cfg.PlatformFeePercent / 100
+	appID := invoice.AppID
+	invoiceID := invoice.ID
+	return tx.Create(&models.EarningEntry{
+		DeveloperID: earningsRecipient(tx, invoice.App), AppID: &appID, InvoiceID: &invoiceID,
+		Type: models.EarningSale, GrossAmount: invoice.Amount, FeeAmount: fee,
+		Amount: invoice.Amount - fee, AvailableAt: time.Now().AddDate(0, 0, cfg.EarningsHoldDays),
+	}).Error
+}
+
+// earningsRecipient returns the user credited for the app's sales: the creator of a
+// personal app, or the longest-standing owner of the organization that owns it.
+// The creator of an organization app may have left the team since.
+func earningsRecipient(tx *gorm.DB, app models.MiniApp) uint {
+	if app.OrganizationID == nil {
+		return app.CreatorID
+	}
+	var owner models.OrgMember
+	if err := tx.Where("organization_id = ? AND role = ?", *app.OrganizationID, models.OrgRoleOwner).
+		Order("created_at ASC, id ASC").First(&owner).Error; err != nil {
+		return app.CreatorID
+	}
+	return owner.UserID
+}
+
+// reverseSaleEarning debits the developer for a refunded invoice.
+// Runs inside the refund transaction.
+func reverseSaleEarning(tx *gorm.DB, invoice models.Invoice) error {
+	var sale models.EarningEntry
+	if err := tx.Where("invoice_id = ? AND type = ?", invoice.ID, models.EarningSale).First(&sale).Error; err != nil {
+		// Invoice paid before the ledger existed — nothing to reverse
+		return nil
+	}
+	return tx.Create(&models.EarningEntry{
+		DeveloperID: sale.DeveloperID, AppID: sale.AppID, InvoiceID: sale.InvoiceID,
+		Type: models.EarningRefund, GrossAmount: -sale.GrossAmount, FeeAmount: -sale.FeeAmount,
+		Amount: -sale.Amount, AvailableAt: sale.AvailableAt,
+	}).Error
+}
+
+func formatPayout(p models.PayoutRequest) fiber.Map {
+	return fiber.Map{
+		"id": publicid.Format(publicid.Payout, p.ID), "amount": p.Amount, "currency": "MP",
+		"destination": p.Destination, "status": p.Status, "note": p.Note,
+		"reference": p.Reference, "processedAt": p.ProcessedAt,
+		"createdAt": p.CreatedAt, "updatedAt": p.UpdatedAt,
+	}
+}
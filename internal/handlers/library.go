@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxPinnedApps = 12
+
+// LibraryHandler serves the user's own app lists: recents, favorites and home screen pins
+type LibraryHandler struct {
+	db *gorm.DB
+}
+
+func NewLibraryHandler(db *gorm.DB) *LibraryHandler {
+	return &LibraryHandler{db: db}
+}
+
+// ListRecent — GET /api/apps/recent?limit=
+func (h *LibraryHandler) ListRecent(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	var used []models.AppUser
+	h.db.Where("user_id = ? AND hidden_at IS NULL", userID).
+		Order("last_used DESC").Limit(limit).Find(&used)
+
+	ids := make([]uint, len(used))
+	for i, u := range used {
+		ids[i] = u.AppID
+	}
+	appsByID := loadMarketApps(h.db, ids)
+	favorites := favoriteSet(h.db, userID, ids)
+
+	result := []fiber.Map{}
+	for _, u := range used {
+		app, ok := appsByID[u.AppID]
+		if !ok {
+			continue
+		}
+		item := formatMarketApp(app)
+		item["lastUsed"] = u.LastUsed
+		item["isFavorite"] = favorites[app.ID]
+		result = append(result, item)
+	}
+
+	return c.JSON(fiber.Map{"success": true, "apps": result})
+}
+
+// HideRecent — DELETE /api/apps/recent/:appId
+// Only hides the app from recents: conversations and history stay, and the app
+// comes back the next time it is used.
+func (h *LibraryHandler) HideRecent(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	res := h.db.Model(&models.AppUser{}).
+		Where("user_id = ? AND app_id = ?", userID, appID).
+		Update("hidden_at", time.Now())
+	if res.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App is not in recents"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// ListFavorites — GET /api/apps/favorites
+func (h *LibraryHandler) ListFavorites(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var favorites []models.AppFavorite
+	h.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&favorites)
+
+	return c.JSON(fiber.Map{"success": true, "apps": h.formatFavorites(favorites)})
+}
+
+// AddFavorite — POST /api/apps/:appId/favorite
+func (h *LibraryHandler) AddFavorite(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := listedApps(h.db).First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+
+	h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.AppFavorite{UserID: userID, AppID: app.ID})
+	return c.JSON(fiber.Map{"success": true, "isFavorite": true})
+}
+
+// RemoveFavorite — DELETE /api/apps/:appId/favorite (also unpins the app)
+func (h *LibraryHandler) RemoveFavorite(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	h.db.Where("user_id = ? AND app_id = ?", userID, appID).Delete(&models.AppFavorite{})
+	return c.JSON(fiber.Map{"success": true, "isFavorite": false})
+}
+
+// ListPinned — GET /api/apps/pinned
+func (h *LibraryHandler) ListPinned(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var pinned []models.AppFavorite
+	h.db.Where("user_id = ? AND pin_position IS NOT NULL", userID).Order("pin_position ASC").Find(&pinned)
+
+	return c.JSON(fiber.Map{"success": true, "apps": h.formatFavorites(pinned)})
+}
+
+// SetPinned — PUT /api/apps/pinned
+// Replaces the home screen pins with the given order. Pinning favorites the app;
+// apps left out of the list stay favorites but lose their pin.
+func (h *LibraryHandler) SetPinned(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input struct {
+		AppIDs []string `json:"appIds"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
+	}
+	if len(input.AppIDs) > maxPinnedApps {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": fmt.Sprintf("At most %d apps can be pinned", maxPinnedApps)}})
+	}
+
+	ids := make([]uint, 0, len(input.AppIDs))
+	seen := map[uint]bool{}
+	for _, raw := range input.AppIDs {
+		id, err := publicid.Parse(raw, publicid.App)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > 0 {
+		var count int64
+		listedApps(h.db.Model(&models.MiniApp{})).Where("id IN ?", ids).Count(&count)
+		if int(count) != len(ids) {
+			return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+		}
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AppFavorite{}).Where("user_id = ?", userID).
+			Update("pin_position", nil).Error; err != nil {
+			return err
+		}
+		for i, id := range ids {
+			position := i
+			fav := models.AppFavorite{UserID: userID, AppID: id, PinPosition: &position}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "app_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"pin_position"}),
+			}).Create(&fav).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to save pinned apps"}})
+	}
+
+	var pinned []models.AppFavorite
+	h.db.Where("user_id = ? AND pin_position IS NOT NULL", userID).Order("pin_position ASC").Find(&pinned)
+	return c.JSON(fiber.Map{"success": true, "apps": h.formatFavorites(pinned)})
+}
+
+// helpers
+
+func (h *LibraryHandler) formatFavorites(favorites []models.AppFavorite) []fiber.Map {
+	ids := make([]uint, len(favorites))
+	for i, f := range favorites {
+		ids[i] = f.AppID
+	}
+	appsByID := loadMarketApps(h.db, ids)
+
+	result := []fiber.Map{}
+	for _, f := range favorites {
+		app, ok := appsByID[f.AppID]
+		if !ok {
+			continue
+		}
+		item := formatMarketApp(app)
+		item["isFavorite"] = true
+		item["isPinned"] = f.PinPosition != nil
+		if f.PinPosition != nil {
+			item["pinPosition"] = *f.PinPosition
+		}
+		item["favoritedAt"] = f.CreatedAt
+		result = append(result, item)
+	}
+	return result
+}
+
+// loadMarketApps loads live marketplace apps by ID, ready for formatMarketApp
+func loadMarketApps(db *gorm.DB, ids []uint) map[uint]models.MiniApp {
+	appsByID := map[uint]models.MiniApp{}
+	if len(ids) == 0 {
+		return appsByID
+	}
+	var apps []models.MiniApp
+	listedApps(db.Preload("Category").Preload("Creator")).
+		Where("id IN ?", ids).Find(&apps)
+	for _, a := range apps {
+		appsByID[a.ID] = a
+	}
+	return appsByID
+}
+
+// favoriteSet returns which of the given apps the user has favorited
+func favoriteSet(db *gorm.DB, userID uint, appIDs []uint) map[uint]bool {
+	set := map[uint]bool{}
+	if len(appIDs) == 0 {
+		return set
+	}
+	var ids []uint
+	db.Model(&models.AppFavorite{}).Where("user_id = ? AND app_id IN ?", userID, appIDs).Pluck("app_id", &ids)
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxTags               = 5
+	minTagLength          = 2
+	maxTagLength          = 24
+	maxScreenshots        = 8
+	maxLongDescriptionLen = 4000
+)
+
+var (
+	tagInvalidChars = regexp.MustCompile(`[^\p{L}\p{N}-]+`)
+	tagDashes       = regexp.MustCompile(`-{2,}`)
+	htmlTag         = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// normalizeTag turns "#Photo Editing" into "photo-editing"
+func normalizeTag(raw string) string {
+	tag := strings.ToLower(strings.TrimSpace(raw))
+	tag = strings.TrimLeft(tag, "#")
+	tag = strings.NewReplacer(" ", "-", "_", "-").Replace(tag)
+	tag = tagInvalidChars.ReplaceAllString(tag, "")
+	tag = tagDashes.ReplaceAllString(tag, "-")
+	return strings.Trim(tag, "-")
+}
+
+// encodeTags normalizes and validates tags and
+// returns the JSON stored in MiniApp.Tags
+func encodeTags(raw []string) (string, error) {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, r := range raw {
+		tag := normalizeTag(r)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if n := len([]rune(tag)); n < minTagLength || n > maxTagLength {
+			return "", fmt.Errorf("tag %q must be %d–%d characters", tag, minTagLength, maxTagLength)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return "", fmt.Errorf("at most %d tags allowed", maxTags)
+	}
+
+	encoded, _ := json.Marshal(tags)
+	return string(encoded), nil
+}
+
+// addTagsToVocabulary adds the tags of a published listing to the vocabulary
+func addTagsToVocabulary(db *gorm.DB, tags []string) error {
+	for _, tag := range tags {
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Tag{Slug: tag}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeScreenshots validates an ordered list of screenshot URLs, each of which must
+// be the caller's own upload or already be on the listing (current, the stored
+// JSON), and returns the JSON stored in MiniApp.Screenshots
+func encodeScreenshots(db *gorm.DB, userID uint, urls []string, current string) (string, error) {
+	list := []string{}
+	seen := map[string]bool{}
+	for _, u := range urls {
+		u = strings.TrimSpace(u)
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		list = append(list, u)
+	}
+	if len(list) > maxScreenshots {
+		return "", fmt.Errorf("at most %d screenshots allowed", maxScreenshots)
+	}
+
+	var existing []string
+	if current != "" {
+		json.Unmarshal([]byte(current), &existing)
+	}
+	kept := map[string]bool{}
+	for _, u := range existing {
+		kept[u] = true
+	}
+	var added []string
+	for _, u := range list {
+		if !kept[u] {
+			added = append(added, u)
+		}
+	}
+	if len(added) > 0 {
+		var known int64
+		db.Model(&models.Upload{}).Where("url IN ? AND user_id = ?", added, userID).Distinct("url").Count(&known)
+		if int(known) != len(added) {
+			return "", errors.New("screenshots must be uploaded through /api/upload first")
+		}
+	}
+
+	encoded, _ := json.Marshal(list)
+	return string(encoded), nil
+}
+
+// cleanLongDescription validates the markdown long description; raw HTML is stripped
+func cleanLongDescription(text string) (string, error) {
+	text = strings.TrimSpace(htmlTag.ReplaceAllString(text, ""))
+	if len([]rune(text)) > maxLongDescriptionLen {
+		return "", fmt.Errorf("longDescription must be at most %d characters", maxLongDescriptionLen)
+	}
+	return text, nil
+}
+
+// listedApps narrows a MiniApp query to the apps shown in the marketplace:
+// approved by moderation, not unpublished by their developer and not taken
+// down over abuse reports
+func listedApps(q *gorm.DB) *gorm.DB {
+	return q.Where("moderation_status = ? AND unpublished_at IS NULL AND hidden_at IS NULL AND suspended_at IS NULL", models.ModerationApproved)
+}
@@ -29,7 +29,7 @@ func (h *MiniAppHandler) GetAll(c *fiber.Ctx) error {
 	var user models.User
 	h.db.First(&user, userID)
 
-	query := h.db.Preload("Category").Where("moderation_status = ?", models.ModerationApproved)
+	query := listedApps(h.db.Preload("Category"))
 
 	// Filter by category if provided
 	if categorySlug != "" && categorySlug != "all" {
@@ -71,28 +71,37 @@ func (h *MiniAppHandler) Search(c *fiber.Ctx) error {
 	var user models.User
 	h.db.First(&user, userID)
 
-	searchPattern := "%" + strings.ToLower(searchQuery) + "%"
-
-	query := h.db.Preload("Category").
-		Where("moderation_status = ?", models.ModerationApproved).
-		Where("LOWER(title) LIKE ? OR LOWER(subtitle) LIKE ? OR LOWER(description) LIKE ?",
-			searchPattern, searchPattern, searchPattern)
-
-	if !user.HasSecretAccess {
-		query = query.Where("is_secret = ?", false)
+	filter := func(q *gorm.DB) *gorm.DB {
+		q = listedApps(q)
+		if !user.HasSecretAccess {
+			q = q.Where("is_secret = ?", false)
+		}
+		return q
 	}
 
-	var miniApps []models.MiniApp
-	if err := query.Order("users_count DESC").Limit(20).Find(&miniApps).Error; err != nil {
+	hits, _, err := searchApps(h.db, searchQuery, filter, 0, 20)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to search apps",
 		})
 	}
+	miniApps := loadSearchHits(h.db, hits)
+
+	highlights := make([]fiber.Map, len(hits))
+	for i, hit := range hits {
+		highlights[i] = fiber.Map{
+			"id":      hit.ID,
+			"title":   hit.Title,
+			"snippet": hit.Snippet,
+			"fuzzy":   hit.Fuzzy,
+		}
+	}
 
 	return c.JSON(fiber.Map{
-		"apps":  miniApps,
-		"total": len(miniApps),
-		"query": searchQuery,
+		"apps":       miniApps,
+		"highlights": highlights,
+		"total":      len(miniApps),
+		"query":      searchQuery,
 	})
 }
 
@@ -122,6 +131,7 @@ func (h *MiniAppHandler) GetByID(c *fiber.Ctx) error {
 
 	// Track app usage
 	h.trackAppUsage(userID, miniApp.ID)
+	recordAppLaunch(h.db, userID, miniApp.ID)
 
 	return c.JSON(miniApp)
 }
@@ -156,7 +166,7 @@ func (h *MiniAppHandler) GetByCategory(c *fiber.Ctx) error {
 		})
 	}
 
-	query := h.db.Where("category_id = ? AND moderation_status = ?", category.ID, models.ModerationApproved)
+	query := listedApps(h.db).Where("category_id = ?", category.ID)
 
 	if !user.HasSecretAccess {
 		query = query.Where("is_secret = ?", false)
@@ -178,14 +188,18 @@ func (h *MiniAppHandler) GetByCategory(c *fiber.Ctx) error {
 
 // CreateAppInput - input for creating a new app
 type CreateAppInput struct {
-	Icon           string `json:"icon"`
-	Title          string `json:"title"`
-	Description    string `json:"description"`
-	CategoryID     uint   `json:"categoryId"`
-	URL            string `json:"url,omitempty"`
-	BotUsername    string `json:"botUsername,omitempty"`
-	WelcomeMessage string `json:"welcomeMessage,omitempty"`
-	WebhookURL     string `json:"webhookUrl,omitempty"`
+	Icon            string   `json:"icon"`
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	CategoryID      uint     `json:"categoryId"`
+	URL             string   `json:"url,omitempty"`
+	BotUsername     string   `json:"botUsername,omitempty"`
+	WelcomeMessage  string   `json:"welcomeMessage,omitempty"`
+	WebhookURL      string   `json:"webhookUrl,omitempty"`
+	Permissions     []string `json:"permissions,omitempty"`
+	LongDescription string   `json:"longDescription,omitempty"` // Markdown
+	Tags            []string `json:"tags,omitempty"`
+	Screenshots     []string `json:"screenshots,omitempty"` // Ordered upload URLs
 }
 
 // CreateApp - create a new mini app (user's app)
@@ -234,6 +248,31 @@ func (h *MiniAppHandler) CreateApp(c *fiber.Ctx) error {
 		})
 	}
 
+	permissions, err := encodePermissions(input.Permissions)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	longDescription, err := cleanLongDescription(input.LongDescription)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	tags, err := encodeTags(input.Tags)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	screenshots, err := encodeScreenshots(h.db, userID, input.Screenshots, "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate unique API token for the app
 	apiToken := models.GenerateAPIToken()
 
@@ -255,7 +294,7 @@ func (h *MiniAppHandler) CreateApp(c *fiber.Ctx) error {
 		CategoryID:       input.CategoryID,
 		URL:              input.URL,
 		CreatorID:        userID,
-		IsVerified:       false,
+		IsVerified:       developerVerified(h.db, userID),
 		IsSecret:         false,
 		UsersCount:       0,
 		ModerationStatus: models.ModerationPending,
@@ -263,6 +302,10 @@ func (h *MiniAppHandler) CreateApp(c *fiber.Ctx) error {
 		BotUsername:      input.BotUsername,
 		WelcomeMessage:   input.WelcomeMessage,
 		WebhookURL:       input.WebhookURL,
+		Permissions:      permissions,
+		LongDescription:  longDescription,
+		Tags:             tags,
+		Screenshots:      screenshots,
 	}
 
 	if err := h.db.Create(&app).Error; err != nil {
@@ -270,6 +313,7 @@ func (h *MiniAppHandler) CreateApp(c *fiber.Ctx) error {
 			"error": "Failed to create app",
 		})
 	}
+	resubmitRelease(h.db, app, userID)
 
 	// Create default /start command if welcome message provided
 	if input.WelcomeMessage != "" {
@@ -293,13 +337,12 @@ func (h *MiniAppHandler) CreateApp(c *fiber.Ctx) error {
 	})
 }
 
-// GetMyApps - get apps created by current user
+// GetMyApps - get apps the current user created or manages through an organization
 func (h *MiniAppHandler) GetMyApps(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
 	var apps []models.MiniApp
-	if err := h.db.Preload("Category").
-		Where("creator_id = ?", userID).
+	if err := teamApps(h.db, userID, models.OrgRoleAnalyst).Preload("Category").
 		Order("created_at DESC").
 		Find(&apps).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -315,14 +358,18 @@ func (h *MiniAppHandler) GetMyApps(c *fiber.Ctx) error {
 
 // UpdateAppInput - input for updating app
 type UpdateAppInput struct {
-	Icon           string `json:"icon,omitempty"`
-	Title          string `json:"title,omitempty"`
-	Description    string `json:"description,omitempty"`
-	CategoryID     uint   `json:"categoryId,omitempty"`
-	URL            string `json:"url,omitempty"`
-	BotUsername    string `json:"botUsername,omitempty"`
-	WelcomeMessage string `json:"welcomeMessage,omitempty"`
-	WebhookURL     string `json:"webhookUrl,omitempty"`
+	Icon            string   `json:"icon,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	CategoryID      uint     `json:"categoryId,omitempty"`
+	URL             string   `json:"url,omitempty"`
+	BotUsername     string   `json:"botUsername,omitempty"`
+	WelcomeMessage  string   `json:"welcomeMessage,omitempty"`
+	WebhookURL      string   `json:"webhookUrl,omitempty"`
+	Permissions     []string `json:"permissions,omitempty"`
+	LongDescription string   `json:"longDescription,omitempty"` // Markdown
+	Tags            []string `json:"tags,omitempty"`
+	Screenshots     []string `json:"screenshots,omitempty"` // Ordered upload URLs
 }
 
 // UpdateApp - update user's own app
@@ -338,9 +385,9 @@ func (h *MiniAppHandler) UpdateApp(c *fiber.Ctx) error {
 	}
 
 	// Check ownership
-	if app.CreatorID != userID {
+	if !hasAppRole(h.db, app, userID, models.OrgRoleDeveloper) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only update your own apps",
+			"error": "You don't have permission to update this app",
 		})
 	}
 
@@ -352,6 +399,7 @@ func (h *MiniAppHandler) UpdateApp(c *fiber.Ctx) error {
 	}
 
 	// Update fields
+	original := app
 	contentChanged := false
 	if input.Title != "" {
 		if len(input.Title) > 50 {
@@ -377,6 +425,48 @@ func (h *MiniAppHandler) UpdateApp(c *fiber.Ctx) error {
 	}
 	if input.URL != "" {
 		app.URL = input.URL
+		contentChanged = true
+	}
+	if input.LongDescription != "" {
+		longDescription, err := cleanLongDescription(input.LongDescription)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		app.LongDescription = longDescription
+		contentChanged = true
+	}
+	if input.Tags != nil {
+		tags, err := encodeTags(input.Tags)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		app.Tags = tags
+		contentChanged = true
+	}
+	if input.Screenshots != nil {
+		screenshots, err := encodeScreenshots(h.db, userID, input.Screenshots, app.Screenshots)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		app.Screenshots = screenshots
+		contentChanged = true
+	}
+
+	if input.Permissions != nil {
+		permissions, err := encodePermissions(input.Permissions)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		app.Permissions = permissions
+		contentChanged = true
 	}
 
 	// Bot settings (don't require re-moderation)
@@ -395,23 +485,86 @@ func (h *MiniAppHandler) UpdateApp(c *fiber.Ctx) error {
 	}
 	if input.WebhookURL != "" {
 		app.WebhookURL = input.WebhookURL
-	}
-
-	// Reset moderation status only if content changed
-	if contentChanged {
+		app.WebhookFailures, app.WebhookDisabledAt = 0, nil
+	}
+
+	// A live app keeps its published listing: content changes go to the draft
+	// release, bot settings are saved right away
+	message := "App updated."
+	if contentChanged && original.ModerationStatus == models.ModerationApproved {
+		edited := app
+		release, err := stageRelease(h.db, original, userID, func(r *models.AppRelease) error {
+			// Only the fields in this request, so earlier draft edits survive
+			if input.Title != "" {
+				r.Title = edited.Title
+			}
+			if input.Description != "" {
+				r.Description, r.Subtitle = edited.Description, edited.Subtitle
+			}
+			if input.Icon != "" {
+				r.Icon = edited.Icon
+			}
+			if input.CategoryID != 0 {
+				r.CategoryID = edited.CategoryID
+			}
+			if input.URL != "" {
+				r.URL = edited.URL
+			}
+			if input.LongDescription != "" {
+				r.LongDescription = edited.LongDescription
+			}
+			if input.Tags != nil {
+				r.Tags = edited.Tags
+			}
+			if input.Screenshots != nil {
+				r.Screenshots = edited.Screenshots
+			}
+			if input.Permissions != nil {
+				r.Permissions = edited.Permissions
+			}
+			return nil
+		})
+		if err == errReleaseInReview {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "A release is waiting for review; content can be changed after it is approved or rejected",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update app",
+			})
+		}
+		app.Title, app.Subtitle, app.Description = original.Title, original.Subtitle, original.Description
+		app.Icon, app.CategoryID = original.Icon, original.CategoryID
+		app.LongDescription, app.Tags, app.Screenshots = original.LongDescription, original.Tags, original.Screenshots
+		app.URL, app.Permissions = original.URL, original.Permissions
+		message = fmt.Sprintf("App updated. Content changes were saved to draft %s; submit it for review to publish.", release.Version)
+	} else if contentChanged {
 		app.ModerationStatus = models.ModerationPending
+		message = "App updated. It will be re-reviewed by moderators."
 	}
 
-	if err := h.db.Save(&app).Error; err != nil {
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&app).Error; err != nil {
+			return err
+		}
+		if app.WebhookURL == original.WebhookURL && (app.WebhookDisabledAt == nil) == (original.WebhookDisabledAt == nil) {
+			return nil
+		}
+		return recordAppAudit(tx, c, app, models.AuditWebhookUpdated, webhookAudit(original), webhookAudit(app))
+	}); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update app",
 		})
 	}
+	if contentChanged && original.ModerationStatus != models.ModerationApproved {
+		resubmitRelease(h.db, app, userID)
+	}
 
 	h.db.Preload("Category").First(&app, app.ID)
 
 	return c.JSON(fiber.Map{
-		"message": "App updated. It will be re-reviewed by moderators.",
+		"message": message,
 		"app":     app,
 	})
 }
@@ -429,9 +582,9 @@ func (h *MiniAppHandler) DeleteApp(c *fiber.Ctx) error {
 	}
 
 	// Check ownership
-	if app.CreatorID != userID {
+	if !hasAppRole(h.db, app, userID, models.OrgRoleOwner) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only delete your own apps",
+			"error": "Only the app owner can delete it",
 		})
 	}
 
@@ -465,8 +618,8 @@ func (h *MiniAppHandler) trackAppUsage(userID, appID uint) {
 		h.db.Model(&models.MiniApp{}).Where("id = ?", appID).
 			UpdateColumn("users_count", gorm.Expr("users_count + 1"))
 	} else {
-		// Update last used
-		h.db.Model(&appUser).Update("last_used", time.Now())
+		// Update last used and bring the app back to recents
+		h.db.Model(&appUser).Updates(map[string]interface{}{"last_used": time.Now(), "hidden_at": nil})
 	}
 }
 
@@ -525,6 +678,11 @@ func (h *MiniAppHandler) SendAppMessage(c *fiber.Ctx) error {
 			"error": "App not found",
 		})
 	}
+	if app.UnavailableReason() != "" {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "This app is unavailable",
+		})
+	}
 
 	// Get user info
 	var user models.User
@@ -588,7 +746,7 @@ func (h *MiniAppHandler) getBotResponse(app models.MiniApp, user models.User, me
 	}
 
 	// If webhook is configured, trigger it asynchronously
-	if app.WebhookURL != "" {
+	if app.WebhookActive() {
 		go h.triggerWebhook(app, user, message, messageID)
 		return "" // Response will come later via Bot API
 	}
@@ -608,12 +766,7 @@ func (h *MiniAppHandler) triggerWebhook(app models.MiniApp, user models.User, me
 	payload := map[string]interface{}{
 		"update_id":  messageID,
 		"message_id": messageID,
-		"from": map[string]interface{}{
-			"id":       user.ID,
-			"email":    user.Email,
-			"name":     user.Name,
-			"language": user.Language,
-		},
+		"from":       botUserPayload(h.db, user, app.ID),
 		"chat": map[string]interface{}{
 			"id":   user.ID,
 			"type": "private",
@@ -645,6 +798,7 @@ func (h *MiniAppHandler) triggerWebhook(app models.MiniApp, user models.User, me
 		webhookLog.Response = "Error creating request: " + err.Error()
 		webhookLog.Duration = int(time.Since(startTime).Milliseconds())
 		h.db.Create(&webhookLog)
+		recordWebhookDelivery(h.db, app, false)
 		return
 	}
 
@@ -657,6 +811,7 @@ func (h *MiniAppHandler) triggerWebhook(app models.MiniApp, user models.User, me
 		webhookLog.Response = "Error sending request: " + err.Error()
 		webhookLog.Duration = int(time.Since(startTime).Milliseconds())
 		h.db.Create(&webhookLog)
+		recordWebhookDelivery(h.db, app, false)
 		return
 	}
 	defer resp.Body.Close()
@@ -670,6 +825,7 @@ func (h *MiniAppHandler) triggerWebhook(app models.MiniApp, user models.User, me
 	webhookLog.Duration = int(time.Since(startTime).Milliseconds())
 
 	h.db.Create(&webhookLog)
+	recordWebhookDelivery(h.db, app, resp.StatusCode >= 200 && resp.StatusCode < 300)
 }
 
 // RegenerateAPIToken - regenerate API token for an app
@@ -684,16 +840,23 @@ func (h *MiniAppHandler) RegenerateAPIToken(c *fiber.Ctx) error {
 		})
 	}
 
-	if app.CreatorID != userID {
+	if !hasAppRole(h.db, app, userID, models.OrgRoleAdmin) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only manage your own apps",
+			"error": "You don't have permission to manage this app",
 		})
 	}
 
+	before := credentialAudit(app)
 	newToken := models.GenerateAPIToken()
 	app.APIToken = newToken
+	app.APITokenExpiresAt, app.APITokenExpiryNotifiedAt = nil, nil
 
-	if err := h.db.Save(&app).Error; err != nil {
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&app).Error; err != nil {
+			return err
+		}
+		return recordAppAudit(tx, c, app, models.AuditAPIKeyGenerated, before, credentialAudit(app))
+	}); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to regenerate token",
 		})
@@ -717,11 +880,17 @@ func (h *MiniAppHandler) GetAppSettings(c *fiber.Ctx) error {
 		})
 	}
 
-	if app.CreatorID != userID {
+	role, ok := appRole(h.db, app, userID)
+	if !ok || !role.AtLeast(models.OrgRoleDeveloper) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only view settings for your own apps",
+			"error": "You don't have permission to view this app's settings",
 		})
 	}
+	// The bot token is a credential, shown to admins and owners only
+	apiToken := ""
+	if role.AtLeast(models.OrgRoleAdmin) && app.HasAPIToken() {
+		apiToken = app.APIToken
+	}
 
 	// Get bot commands
 	var commands []models.BotCommand
@@ -743,7 +912,7 @@ func (h *MiniAppHandler) GetAppSettings(c *fiber.Ctx) error {
 			"botUsername":      app.BotUsername,
 			"welcomeMessage":   app.WelcomeMessage,
 			"webhookUrl":       app.WebhookURL,
-			"apiToken":         app.APIToken,
+			"apiToken":         apiToken,
 			"moderationStatus": app.ModerationStatus,
 			"usersCount":       app.UsersCount,
 			"createdAt":        app.CreatedAt,
@@ -773,9 +942,9 @@ func (h *MiniAppHandler) AddBotCommand(c *fiber.Ctx) error {
 		})
 	}
 
-	if app.CreatorID != userID {
+	if !hasAppRole(h.db, app, userID, models.OrgRoleDeveloper) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only manage your own apps",
+			"error": "You don't have permission to manage this app",
 		})
 	}
 
@@ -833,9 +1002,9 @@ func (h *MiniAppHandler) UpdateBotCommand(c *fiber.Ctx) error {
 		})
 	}
 
-	if app.CreatorID != userID {
+	if !hasAppRole(h.db, app, userID, models.OrgRoleDeveloper) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only manage your own apps",
+			"error": "You don't have permission to manage this app",
 		})
 	}
 
@@ -886,9 +1055,9 @@ func (h *MiniAppHandler) DeleteBotCommand(c *fiber.Ctx) error {
 		})
 	}
 
-	if app.CreatorID != userID {
+	if !hasAppRole(h.db, app, userID, models.OrgRoleDeveloper) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only manage your own apps",
+			"error": "You don't have permission to manage this app",
 		})
 	}
 
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ModerationHandler handles the admin moderation queue: claiming submitted
+// releases and the history of moderation decisions. Approving and rejecting
+// live on ReleasesHandler.
+type ModerationHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewModerationHandler(db *gorm.DB, cfg *config.Config) *ModerationHandler {
+	return &ModerationHandler{db: db, cfg: cfg}
+}
+
+// AdminModerationQueue — GET /api/admin/moderation/queue
+// ?status=pending|approved|rejected|all&claimed=me|unclaimed|others&kind=new|update&flagged=true|false&category=&q=
+// Oldest submissions come first.
+func (h *ModerationHandler) AdminModerationQueue(c *fiber.Ctx) error {
+	adminID := c.Locals("userID").(uint)
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit := pageLimit(c)
+	staleBefore := time.Now().Add(-models.ModerationClaimTTL)
+
+	status, claimed, kind := c.Query("status", models.ReleasePending), c.Query("claimed"), c.Query("kind")
+	if claimed != "" && claimed != "me" && claimed != "unclaimed" && claimed != "others" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "claimed must be me, unclaimed or others"}})
+	}
+	if kind != "" && kind != "new" && kind != "update" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "kind must be new or update"}})
+	}
+
+	filter := func(q *gorm.DB) *gorm.DB {
+		q = q.Joins("JOIN mini_apps ON mini_apps.id = app_releases.app_id AND mini_apps.deleted_at IS NULL")
+		if status != "all" {
+			q = q.Where("app_releases.status = ?", status)
+		}
+		switch claimed {
+		case "me":
+			q = q.Where("app_releases.claimed_by = ? AND app_releases.claimed_at > ?", adminID, staleBefore)
+		case "unclaimed":
+			q = q.Where("app_releases.claimed_by IS NULL OR app_releases.claimed_at <= ?", staleBefore)
+		case "others":
+			q = q.Where("app_releases.claimed_by <> ? AND app_releases.claimed_at > ?", adminID, staleBefore)
+		}
+		switch kind {
+		case "new":
+			q = q.Where("mini_apps.moderation_status <> ?", models.ModerationApproved)
+		case "update":
+			q = q.Where("mini_apps.moderation_status = ?", models.ModerationApproved)
+		}
+		if slug := c.Query("category"); slug != "" {
+			q = q.Where("app_releases.category_id IN (?)", h.db.Model(&models.Category{}).Select("id").Where("slug = ?", slug))
+		}
+		switch c.Query("flagged") {
+		case "true":
+			q = q.Where("app_releases.risk_flags <> ''")
+		case "false":
+			q = q.Where("app_releases.checked_at IS NOT NULL AND app_releases.risk_flags = ''")
+		}
+		if search := c.Query("q"); search != "" {
+			like := "%" + escapeLike(search) + "%"
+			q = q.Where("app_releases.title ILIKE ? OR mini_apps.title ILIKE ?", like, like)
+		}
+		return q
+	}
+
+	var total int64
+	filter(h.db.Model(&models.AppRelease{})).Count(&total)
+	var releases []models.AppRelease
+	filter(h.db).Preload("App.Creator").Order("app_releases.submitted_at ASC, app_releases.id ASC").
+		Offset((page - 1) * limit).Limit(limit).Find(&releases)
+
+	now := time.Now()
+	result := make([]fiber.Map, len(releases))
+	for i, r := range releases {
+		result[i] = formatRelease(r, r.App)
+		result[i]["appName"] = r.App.Title
+		result[i]["liveVersion"] = r.App.Version
+		result[i]["isNewApp"] = r.App.ModerationStatus != models.ModerationApproved
+		result[i]["developer"] = fiber.Map{
+			"id": publicid.Format(publicid.User, r.App.CreatorID), "email": r.App.Creator.Email,
+			"name": r.App.Creator.GetDisplayName(), "isVerified": r.App.IsVerified,
+		}
+		result[i]["claim"] = formatClaim(r, adminID, now)
+		result[i]["riskFlags"] = r.FlagList()
+		result[i]["checkedAt"] = r.CheckedAt
+	}
+
+	return c.JSON(fiber.Map{
+		"success":    true,
+		"releases":   result,
+		"pagination": fiber.Map{"page": page, "limit": limit, "total": total, "hasMore": int64(page*limit) < total},
+	})
+}
+
+// AdminModerationReasons — GET /api/admin/moderation/reasons
+func (h *ModerationHandler) AdminModerationReasons(c *fiber.Ctx) error {
+	codes := make([]string, 0, len(models.ModerationReasons))
+	for code := range models.ModerationReasons {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	result := make([]fiber.Map, len(codes))
+	for i, code := range codes {
+		result[i] = fiber.Map{"code": code, "label": models.ModerationReasons[code]}
+	}
+	return c.JSON(fiber.Map{"success": true, "reasons": result})
+}
+
+// AdminClaimRelease — POST /api/admin/moderation/:releaseId/claim
+// Marks the release as being reviewed by the caller. A claim expires after
+// ModerationClaimTTL; claiming again refreshes it.
+func (h *ModerationHandler) AdminClaimRelease(c *fiber.Ctx) error {
+	return h.setClaim(c, true)
+}
+
+// AdminUnclaimRelease — DELETE /api/admin/moderation/:releaseId/claim
+func (h *ModerationHandler) AdminUnclaimRelease(c *fiber.Ctx) error {
+	return h.setClaim(c, false)
+}
+
+// AdminRecheckRelease — POST /api/admin/moderation/:releaseId/recheck
+// Clears the release's risk flags so the automated checks run on it again.
+func (h *ModerationHandler) AdminRecheckRelease(c *fiber.Ctx) error {
+	releaseID, err := paramID(c, "releaseId", publicid.Release)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var release models.AppRelease
+	if err := h.db.First(&release, releaseID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Release not found"}})
+	}
+	if release.Status != models.ReleasePending {
+		return releaseError(c, errReleaseStatus)
+	}
+	h.db.Model(&release).Updates(map[string]interface{}{"risk_flags": "", "checked_at": nil})
+	return c.JSON(fiber.Map{"success": true, "message": "Automated checks will run again shortly"})
+}
+
+// AdminModerationHistory — GET /api/admin/moderation/history?appId=&moderatorId=&action=
+func (h *ModerationHandler) AdminModerationHistory(c *fiber.Ctx) error {
+	query := h.db.Model(&models.ModerationAction{})
+	scope := "moderation-history"
+	if raw := c.Query("appId"); raw != "" {
+		appID, err := publicid.Parse(raw, publicid.App)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		query = query.Where("app_id = ?", appID)
+		scope += ":app:" + raw
+	}
+	if raw := c.Query("moderatorId"); raw != "" {
+		moderatorID, err := publicid.Parse(raw, publicid.User)
+		if err != nil {
+			return invalidIDError(c, err)
+		}
+		query = query.Where("moderator_id = ?", moderatorID)
+		scope += ":moderator:" + raw
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+		scope += ":action:" + action
+	}
+	cursor, err := decodeCursor(h.cfg.JWTSecret, c.Query("cursor"), scope)
+	if err != nil {
+		return invalidCursorError(c)
+	}
+	limit := pageLimit(c)
+
+	var actions []models.ModerationAction
+	afterTimeCursor(query, "created_at", cursor).Preload("Release").Preload("Moderator").
+		Order("created_at DESC, id DESC").Limit(limit + 1).Find(&actions)
+
+	hasMore := len(actions) > limit
+	if hasMore {
+		actions = actions[:limit]
+	}
+	var nextCursor interface{}
+	if len(actions) > 0 {
+		last := actions[len(actions)-1]
+		nextCursor = nextTimeCursor(h.cfg.JWTSecret, scope, hasMore, last.CreatedAt, last.ID)
+	}
+
+	result := make([]fiber.Map, len(actions))
+	for i, a := range actions {
+		result[i] = fiber.Map{
+			"releaseId": publicid.Format(publicid.Release, a.ReleaseID),
+			"appId":     publicid.Format(publicid.App, a.AppID),
+			"version":   a.Release.Version,
+			"action":    a.Action,
+			"note":      a.Note,
+			"reasons":   a.ReasonList(),
+			"moderator": nil,
+			"createdAt": a.CreatedAt,
+		}
+		if a.Moderator != nil {
+			result[i]["moderator"] = fiber.Map{
+				"id": publicid.Format(publicid.User, a.Moderator.ID), "email": a.Moderator.Email,
+				"name": a.Moderator.GetDisplayName(),
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success":    true,
+		"history":    result,
+		"pagination": fiber.Map{"limit": limit, "hasMore": hasMore, "nextCursor": nextCursor},
+	})
+}
+
+// helpers
+
+func (h *ModerationHandler) setClaim(c *fiber.Ctx, claim bool) error {
+	adminID := c.Locals("userID").(uint)
+	releaseID, err := paramID(c, "releaseId", publicid.Release)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var release models.AppRelease
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&release, releaseID).Error; err != nil {
+			return err
+		}
+		if release.Status != models.ReleasePending {
+			return errReleaseStatus
+		}
+		now := time.Now()
+		if release.ClaimedByOther(adminID, now) {
+			return errReleaseClaimed
+		}
+
+		action := models.ModerationActionUnclaimed
+		if claim {
+			action = models.ModerationActionClaimed
+			release.ClaimedBy, release.ClaimedAt = &adminID, &now
+		} else {
+			if release.ClaimedBy == nil {
+				return nil
+			}
+			release.ClaimedBy, release.ClaimedAt = nil, nil
+		}
+		if err := tx.Model(&release).Updates(map[string]interface{}{
+			"claimed_by": release.ClaimedBy, "claimed_at": release.ClaimedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return recordModeration(tx, release, &adminID, action, "", nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Release not found"}})
+	}
+	if err != nil {
+		return releaseError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"success": true, "claim": formatClaim(release, adminID, time.Now())})
+}
+
+// recordModeration appends an entry to the moderation history. Runs inside a transaction.
+func recordModeration(tx *gorm.DB, release models.AppRelease, moderatorID *uint, action, note string, reasons []string) error {
+	entry := models.ModerationAction{
+		ReleaseID: release.ID, AppID: release.AppID, ModeratorID: moderatorID,
+		Action: action, Note: note,
+	}
+	if len(reasons) > 0 {
+		encoded, _ := json.Marshal(reasons)
+		entry.Reasons = string(encoded)
+	}
+	return tx.Create(&entry).Error
+}
+
+// escapeLike escapes the LIKE metacharacters in a user's search term
+func escapeLike(term string) string {
+	return likeEscaper.Replace(term)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// validateReasons checks rejection reason codes against models.ModerationReasons
+func validateReasons(reasons []string) error {
+	if len(reasons) == 0 {
+		return errors.New("at least one reason code is required when rejecting")
+	}
+	for _, code := range reasons {
+		if _, ok := models.ModerationReasons[code]; !ok {
+			return fmt.Errorf("unknown reason code %q", code)
+		}
+	}
+	return nil
+}
+
+func formatClaim(r models.AppRelease, adminID uint, now time.Time) interface{} {
+	if r.ClaimedBy == nil || r.ClaimedAt == nil || !now.Before(r.ClaimedAt.Add(models.ModerationClaimTTL)) {
+		return nil
+	}
+	return fiber.Map{
+		"moderatorId": publicid.Format(publicid.User, *r.ClaimedBy),
+		"isMine":      *r.ClaimedBy == adminID,
+		"claimedAt":   r.ClaimedAt,
+		"expiresAt":   r.ClaimedAt.Add(models.ModerationClaimTTL),
+	}
+}
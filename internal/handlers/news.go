@@ -5,35 +5,55 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/fasad/solanafon-back/internal/config"
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 // NewsHandler handles /api/news/* endpoints
 type NewsHandler struct {
-	db *gorm.DB
+	db  *gorm.DB
+	cfg *config.Config
 }
 
-func NewNewsHandler(db *gorm.DB) *NewsHandler {
-	return &NewsHandler{db: db}
+func NewNewsHandler(db *gorm.DB, cfg *config.Config) *NewsHandler {
+	return &NewsHandler{db: db, cfg: cfg}
 }
 
 // GetFeed — GET /api/news
 func (h *NewsHandler) GetFeed(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	limit := pageLimit(c)
 	if page < 1 {
 		page = 1
 	}
 	offset := (page - 1) * limit
+	cursor, err := decodeCursor(h.cfg.JWTSecret, c.Query("cursor"), "feed")
+	if err != nil {
+		return invalidCursorError(c)
+	}
 
-	var total int64
-	h.db.Model(&models.NewsPost{}).Count(&total)
-
+	query := h.db.Where("hidden_at IS NULL").Preload("App").Order("created_at DESC, id DESC")
+	if cursor != nil {
+		query = afterTimeCursor(query, "created_at", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
 	var posts []models.NewsPost
-	h.db.Preload("App").Order("created_at DESC").Offset(offset).Limit(limit).Find(&posts)
+	query.Limit(limit + 1).Find(&posts)
+
+	hasMore := len(posts) > limit
+	if hasMore {
+		posts = posts[:limit]
+	}
+	var nextCursor interface{}
+	if len(posts) > 0 {
+		last := posts[len(posts)-1]
+		nextCursor = nextTimeCursor(h.cfg.JWTSecret, "feed", hasMore, last.CreatedAt, last.ID)
+	}
 
 	result := make([]fiber.Map, len(posts))
 	for i, p := range posts {
@@ -42,7 +62,7 @@ func (h *NewsHandler) GetFeed(c *fiber.Ctx) error {
 			Select("count(*) > 0").Scan(&isLiked)
 
 		result[i] = fiber.Map{
-			"id": fmt.Sprintf("post_%d", p.ID), "appId": fmt.Sprintf("app_%d", p.AppID),
+			"id": publicid.Format(publicid.Post, p.ID), "appId": publicid.Format(publicid.App, p.AppID),
 			"appName": p.App.Title, "appIcon": p.App.IconURL,
 			"text": p.Text, "imageUrl": p.ImageURL,
 			"commentsCount": p.CommentsCount, "likesCount": p.LikesCount,
@@ -51,13 +71,23 @@ func (h *NewsHandler) GetFeed(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.JSON(fiber.Map{"success": true, "posts": result, "pagination": fiber.Map{"page": page, "total": total}})
+	pagination := fiber.Map{"limit": limit, "hasMore": hasMore, "nextCursor": nextCursor}
+	if cursor == nil {
+		var total int64
+		h.db.Model(&models.NewsPost{}).Where("hidden_at IS NULL").Count(&total)
+		pagination["page"], pagination["total"] = page, total
+	}
+
+	return c.JSON(fiber.Map{"success": true, "posts": result, "pagination": pagination})
 }
 
 // LikePost — POST /api/news/:postId/like
 func (h *NewsHandler) LikePost(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	postID, _ := strconv.Atoi(c.Params("postId"))
+	postID, err := paramID(c, "postId", publicid.Post)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var existing models.NewsLike
 	if h.db.Where("post_id = ? AND user_id = ?", postID, userID).First(&existing).Error == nil {
@@ -76,41 +106,67 @@ func (h *NewsHandler) LikePost(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"success": true, "liked": true, "likesCount": post.LikesCount})
 }
 
-// SharePost — POST /api/news/:postId/share
-func (h *NewsHandler) SharePost(c *fiber.Ctx) error {
-	postID, _ := strconv.Atoi(c.Params("postId"))
-	h.db.Model(&models.NewsPost{}).Where("id = ?", postID).UpdateColumn("shares_count", gorm.Expr("shares_count + 1"))
-	var post models.NewsPost
-	h.db.First(&post, postID)
-	return c.JSON(fiber.Map{"success": true, "sharesCount": post.SharesCount})
-}
-
 // GetComments — GET /api/news/:postId/comments
 func (h *NewsHandler) GetComments(c *fiber.Ctx) error {
-	postID := c.Params("postId")
+	postID, err := paramID(c, "postId", publicid.Post)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	limit := pageLimit(c)
+	if page < 1 {
+		page = 1
+	}
 	offset := (page - 1) * limit
+	// Comment cursors are per post
+	scope := fmt.Sprintf("comments:%d", postID)
+	cursor, err := decodeCursor(h.cfg.JWTSecret, c.Query("cursor"), scope)
+	if err != nil {
+		return invalidCursorError(c)
+	}
 
+	query := h.db.Where("post_id = ? AND hidden_at IS NULL", postID).Preload("User").Order("created_at DESC, id DESC")
+	if cursor != nil {
+		query = afterTimeCursor(query, "created_at", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
 	var comments []models.NewsComment
-	h.db.Where("post_id = ?", postID).Preload("User").Order("created_at DESC").
-		Offset(offset).Limit(limit).Find(&comments)
+	query.Limit(limit + 1).Find(&comments)
+
+	hasMore := len(comments) > limit
+	if hasMore {
+		comments = comments[:limit]
+	}
+	var nextCursor interface{}
+	if len(comments) > 0 {
+		last := comments[len(comments)-1]
+		nextCursor = nextTimeCursor(h.cfg.JWTSecret, scope, hasMore, last.CreatedAt, last.ID)
+	}
 
 	result := make([]fiber.Map, len(comments))
 	for i, cm := range comments {
 		result[i] = fiber.Map{
-			"id": fmt.Sprintf("comment_%d", cm.ID), "userId": fmt.Sprintf("user_%d", cm.UserID),
+			"id": publicid.Format(publicid.Comment, cm.ID), "userId": publicid.Format(publicid.User, cm.UserID),
 			"userName": cm.User.GetDisplayName(), "userAvatar": cm.User.GetAvatarURL(),
 			"text": cm.Text, "createdAt": cm.CreatedAt,
 		}
 	}
-	return c.JSON(fiber.Map{"success": true, "comments": result})
+	pagination := fiber.Map{"limit": limit, "hasMore": hasMore, "nextCursor": nextCursor}
+	if cursor == nil {
+		pagination["page"] = page
+	}
+
+	return c.JSON(fiber.Map{"success": true, "comments": result, "pagination": pagination})
 }
 
 // PostComment — POST /api/news/:postId/comments
 func (h *NewsHandler) PostComment(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	postID, _ := strconv.Atoi(c.Params("postId"))
+	postID, err := paramID(c, "postId", publicid.Post)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
 	var input struct {
 		Text string `json:"text"`
@@ -124,18 +180,20 @@ func (h *NewsHandler) PostComment(c *fiber.Ctx) error {
 	h.db.Model(&models.NewsPost{}).Where("id = ?", postID).UpdateColumn("comments_count", gorm.Expr("comments_count + 1"))
 
 	return c.Status(201).JSON(fiber.Map{"success": true, "comment": fiber.Map{
-		"id": fmt.Sprintf("comment_%d", comment.ID), "text": comment.Text, "createdAt": comment.CreatedAt,
+		"id": publicid.Format(publicid.Comment, comment.ID), "text": comment.Text, "createdAt": comment.CreatedAt,
 	}})
 }
 
 // CreatePost — POST /api/developer/apps/:appId/news
 func (h *NewsHandler) CreatePost(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	appID, _ := strconv.Atoi(c.Params("appId"))
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 
-	var app models.MiniApp
-	if err := h.db.Where("id = ? AND creator_id = ?", appID, userID).First(&app).Error; err != nil {
-		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "Not your app"}})
+	if _, err := authorizeApp(h.db, appID, userID, models.OrgRoleDeveloper); err != nil {
+		return appAccessError(c, err)
 	}
 
 	var input struct {
@@ -149,6 +207,6 @@ func (h *NewsHandler) CreatePost(c *fiber.Ctx) error {
 	post := models.NewsPost{AppID: uint(appID), Text: input.Text, ImageURL: input.ImageURL}
 	h.db.Create(&post)
 	return c.Status(201).JSON(fiber.Map{"success": true, "post": fiber.Map{
-		"id": fmt.Sprintf("post_%d", post.ID), "text": post.Text, "createdAt": post.CreatedAt,
+		"id": publicid.Format(publicid.Post, post.ID), "text": post.Text, "createdAt": post.CreatedAt,
 	}})
 }
@@ -1,21 +1,23 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
 
+	"github.com/fasad/solanafon-back/internal/config"
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 // NotificationsHandler handles /api/notifications/* endpoints
 type NotificationsHandler struct {
-	db *gorm.DB
+	db  *gorm.DB
+	cfg *config.Config
 }
 
-func NewNotificationsHandler(db *gorm.DB) *NotificationsHandler {
-	return &NotificationsHandler{db: db}
+func NewNotificationsHandler(db *gorm.DB, cfg *config.Config) *NotificationsHandler {
+	return &NotificationsHandler{db: db, cfg: cfg}
 }
 
 // RegisterPushToken — POST /api/notifications/register
@@ -59,51 +61,79 @@ func (h *NotificationsHandler) UnregisterPushToken(c *fiber.Ctx) error {
 func (h *NotificationsHandler) ListNotifications(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	limit := pageLimit(c)
 	unreadOnly := c.Query("unreadOnly") == "true"
 	if page < 1 {
 		page = 1
 	}
 	offset := (page - 1) * limit
-
-	query := h.db.Where("user_id = ?", userID)
+	// The filter is part of the scope: an unread-only cursor can't page the full list
+	scope := "notifications"
 	if unreadOnly {
-		query = query.Where("is_read = false")
+		scope = "notifications:unread"
+	}
+	cursor, err := decodeCursor(h.cfg.JWTSecret, c.Query("cursor"), scope)
+	if err != nil {
+		return invalidCursorError(c)
 	}
 
-	var total int64
-	query.Model(&models.Notification{}).Count(&total)
+	filter := func(q *gorm.DB) *gorm.DB {
+		q = q.Where("user_id = ?", userID)
+		if unreadOnly {
+			q = q.Where("is_read = false")
+		}
+		return q
+	}
 
+	query := filter(h.db).Order("created_at DESC, id DESC")
+	if cursor != nil {
+		query = afterTimeCursor(query, "created_at", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
 	var notifs []models.Notification
-	query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&notifs)
+	query.Limit(limit + 1).Find(&notifs)
+
+	hasMore := len(notifs) > limit
+	if hasMore {
+		notifs = notifs[:limit]
+	}
+	var nextCursor interface{}
+	if len(notifs) > 0 {
+		last := notifs[len(notifs)-1]
+		nextCursor = nextTimeCursor(h.cfg.JWTSecret, scope, hasMore, last.CreatedAt, last.ID)
+	}
 
 	result := make([]fiber.Map, len(notifs))
 	for i, n := range notifs {
 		result[i] = fiber.Map{
-			"id": fmt.Sprintf("notif_%d", n.ID), "title": n.Title,
+			"id": publicid.Format(publicid.Notification, n.ID), "title": n.Title,
 			"body": n.Body, "type": n.Type, "isRead": n.IsRead,
 			"createdAt": n.CreatedAt, "actionUrl": n.ActionURL,
 		}
 	}
 
-	totalPages := int(total) / limit
-	if int(total)%limit > 0 {
-		totalPages++
+	pagination := fiber.Map{"limit": limit, "hasMore": hasMore, "nextCursor": nextCursor}
+	if cursor == nil {
+		var total int64
+		filter(h.db.Model(&models.Notification{})).Count(&total)
+		pagination["page"], pagination["total"] = page, total
 	}
 
 	return c.JSON(fiber.Map{
 		"success":       true,
 		"notifications": result,
-		"pagination": fiber.Map{
-			"page": page, "limit": limit, "total": total, "hasMore": page < totalPages,
-		},
+		"pagination":    pagination,
 	})
 }
 
 // MarkAsRead — POST /api/notifications/:id/read
 func (h *NotificationsHandler) MarkAsRead(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
+	id, err := paramID(c, "notificationId", publicid.Notification)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
 	h.db.Model(&models.Notification{}).Where("id = ? AND user_id = ?", id, userID).Update("is_read", true)
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -0,0 +1,557 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/fasad/solanafon-back/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const orgInviteTTL = 7 * 24 * time.Hour
+
+var (
+	errLastOwner     = errors.New("organization must keep at least one owner")
+	errInviteNotOpen = errors.New("invite is no longer open")
+)
+
+// OrganizationsHandler manages developer organizations, their members and invites
+type OrganizationsHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewOrganizationsHandler(db *gorm.DB, cfg *config.Config) *OrganizationsHandler {
+	return &OrganizationsHandler{db: db, cfg: cfg}
+}
+
+// ListMyOrganizations — GET /api/orgs
+func (h *OrganizationsHandler) ListMyOrganizations(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var members []models.OrgMember
+	h.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&members)
+
+	result := []fiber.Map{}
+	for _, m := range members {
+		var org models.Organization
+		if err := h.db.First(&org, m.OrganizationID).Error; err != nil {
+			continue
+		}
+		item := h.formatOrg(org)
+		item["role"] = m.Role
+		result = append(result, item)
+	}
+	return c.JSON(fiber.Map{"success": true, "organizations": result})
+}
+
+// CreateOrganization — POST /api/orgs
+// The creator becomes the organization's first owner.
+func (h *OrganizationsHandler) CreateOrganization(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	c.BodyParser(&input)
+	name, ok := cleanOrgName(input.Name)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "name is required, up to 100 characters without control characters"}})
+	}
+
+	org := models.Organization{Name: name, CreatedBy: userID}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrgMember{OrganizationID: org.ID, UserID: userID, Role: models.OrgRoleOwner}).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to create organization"}})
+	}
+
+	result := h.formatOrg(org)
+	result["role"] = models.OrgRoleOwner
+	return c.Status(201).JSON(fiber.Map{"success": true, "organization": result})
+}
+
+// GetOrganization — GET /api/orgs/:orgId
+func (h *OrganizationsHandler) GetOrganization(c *fiber.Ctx) error {
+	org, role, err := h.memberOrg(c, models.OrgRoleAnalyst)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+
+	var apps []models.MiniApp
+	h.db.Where("organization_id = ?", org.ID).Preload("Category").Order("created_at DESC").Find(&apps)
+	appList := make([]fiber.Map, len(apps))
+	for i, a := range apps {
+		appList[i] = formatDevApp(a)
+	}
+
+	result := h.formatOrg(org)
+	result["role"] = role
+	result["members"] = h.formatMembers(org.ID)
+	result["apps"] = appList
+	return c.JSON(fiber.Map{"success": true, "organization": result})
+}
+
+// UpdateOrganization — PATCH /api/orgs/:orgId (admin)
+func (h *OrganizationsHandler) UpdateOrganization(c *fiber.Ctx) error {
+	org, _, err := h.memberOrg(c, models.OrgRoleAdmin)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	c.BodyParser(&input)
+	name, ok := cleanOrgName(input.Name)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "name is required, up to 100 characters without control characters"}})
+	}
+
+	h.db.Model(&org).Update("name", name)
+	return c.JSON(fiber.Map{"success": true, "organization": h.formatOrg(org)})
+}
+
+// DeleteOrganization — DELETE /api/orgs/:orgId (owner)
+// Apps have to be moved out first. Deleted apps count until they are purged, so
+// the organization is still there to restore them.
+func (h *OrganizationsHandler) DeleteOrganization(c *fiber.Ctx) error {
+	org, _, err := h.memberOrg(c, models.OrgRoleOwner)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+
+	var apps int64
+	h.db.Unscoped().Model(&models.MiniApp{}).Where("organization_id = ?", org.ID).Count(&apps)
+	if apps > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "ORG_HAS_APPS", "message": "Move the organization's apps out first; deleted apps count until they are purged"}})
+	}
+
+	h.db.Transaction(func(tx *gorm.DB) error {
+		tx.Where("organization_id = ?", org.ID).Delete(&models.OrgMember{})
+		tx.Model(&models.OrgInvite{}).Where("organization_id = ? AND status = ?", org.ID, models.OrgInvitePending).
+			Update("status", models.OrgInviteRevoked)
+		return tx.Delete(&org).Error
+	})
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// AddApp — POST /api/orgs/:orgId/apps
+// Moves one of the caller's personal apps into the organization.
+func (h *OrganizationsHandler) AddApp(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	org, _, err := h.memberOrg(c, models.OrgRoleAdmin)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+
+	var input struct {
+		AppID string `json:"appId"`
+	}
+	c.BodyParser(&input)
+	appID, err := publicid.Parse(input.AppID, publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := h.db.Preload("Category").Where("id = ? AND creator_id = ? AND organization_id IS NULL", appID, userID).First(&app).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Personal app not found"}})
+	}
+
+	h.db.Model(&app).Update("organization_id", org.ID)
+	return c.JSON(fiber.Map{"success": true, "app": formatDevApp(app)})
+}
+
+// UpdateMember — PUT /api/orgs/:orgId/members/:userId (admin)
+// Only owners can grant the owner role or change another owner's role.
+func (h *OrganizationsHandler) UpdateMember(c *fiber.Ctx) error {
+	org, role, err := h.memberOrg(c, models.OrgRoleAdmin)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+	memberID, err := paramID(c, "userId", publicid.User)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var input struct {
+		Role models.OrgRole `json:"role"`
+	}
+	c.BodyParser(&input)
+	if !input.Role.Valid() {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "role must be owner, admin, developer or analyst"}})
+	}
+
+	var member models.OrgMember
+	if err := h.db.Where("organization_id = ? AND user_id = ?", org.ID, memberID).First(&member).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Member not found"}})
+	}
+	if (member.Role == models.OrgRoleOwner || input.Role == models.OrgRoleOwner) && role != models.OrgRoleOwner {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "Only owners can manage owners"}})
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if member.Role == models.OrgRoleOwner && input.Role != models.OrgRoleOwner {
+			if err := ensureAnotherOwner(tx, org.ID, member.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&member).Update("role", input.Role).Error
+	})
+	if errors.Is(err, errLastOwner) {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "LAST_OWNER", "message": "The organization must keep at least one owner"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update member"}})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "members": h.formatMembers(org.ID)})
+}
+
+// RemoveMember — DELETE /api/orgs/:orgId/members/:userId
+// Admins remove members; any member may remove themselves to leave.
+func (h *OrganizationsHandler) RemoveMember(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	memberID, err := paramID(c, "userId", publicid.User)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	minRole := models.OrgRoleAdmin
+	if memberID == userID {
+		minRole = models.OrgRoleAnalyst
+	}
+	org, role, err := h.memberOrg(c, minRole)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+
+	var member models.OrgMember
+	if err := h.db.Where("organization_id = ? AND user_id = ?", org.ID, memberID).First(&member).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Member not found"}})
+	}
+	if member.Role == models.OrgRoleOwner && role != models.OrgRoleOwner {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "Only owners can manage owners"}})
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if member.Role == models.OrgRoleOwner {
+			if err := ensureAnotherOwner(tx, org.ID, member.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&member).Error
+	})
+	if errors.Is(err, errLastOwner) {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "LAST_OWNER", "message": "The organization must keep at least one owner"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to remove member"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// ListInvites — GET /api/orgs/:orgId/invites (admin)
+func (h *OrganizationsHandler) ListInvites(c *fiber.Ctx) error {
+	org, _, err := h.memberOrg(c, models.OrgRoleAdmin)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+
+	var invites []models.OrgInvite
+	h.db.Where("organization_id = ? AND status = ? AND expires_at > ?", org.ID, models.OrgInvitePending, time.Now()).
+		Order("created_at DESC").Find(&invites)
+
+	result := make([]fiber.Map, len(invites))
+	for i, inv := range invites {
+		inv.Organization = org
+		result[i] = formatOrgInvite(inv)
+	}
+	return c.JSON(fiber.Map{"success": true, "invites": result})
+}
+
+// InviteMember — POST /api/orgs/:orgId/invites (admin)
+// Sends the invite by email, and as a notification when the address already has an account.
+func (h *OrganizationsHandler) InviteMember(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	org, role, err := h.memberOrg(c, models.OrgRoleAdmin)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+
+	var input struct {
+		Email string         `json:"email"`
+		Role  models.OrgRole `json:"role"`
+	}
+	c.BodyParser(&input)
+	addr, err := mail.ParseAddress(strings.TrimSpace(input.Email))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "A valid email is required"}})
+	}
+	email := strings.ToLower(addr.Address)
+	if !input.Role.Valid() {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "role must be owner, admin, developer or analyst"}})
+	}
+	if input.Role == models.OrgRoleOwner && role != models.OrgRoleOwner {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "Only owners can invite owners"}})
+	}
+
+	var invitee models.User
+	h.db.Where("LOWER(email) = ?", email).Limit(1).Find(&invitee)
+	if invitee.ID != 0 {
+		if _, isMember := orgRole(h.db, org.ID, invitee.ID); isMember {
+			return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "ALREADY_MEMBER", "message": "This user is already a member"}})
+		}
+	}
+
+	// A repeated invite to the same address replaces the open one
+	h.db.Model(&models.OrgInvite{}).
+		Where("organization_id = ? AND email = ? AND status = ?", org.ID, email, models.OrgInvitePending).
+		Update("status", models.OrgInviteRevoked)
+	invite := models.OrgInvite{
+		OrganizationID: org.ID, Organization: org, Email: email, Role: input.Role,
+		Status: models.OrgInvitePending, InvitedBy: userID, ExpiresAt: time.Now().Add(orgInviteTTL),
+	}
+	if err := h.db.Omit("Organization").Create(&invite).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to create invite"}})
+	}
+
+	var inviter models.User
+	h.db.First(&inviter, userID)
+	emailCfg := utils.EmailConfig{
+		Host: h.cfg.SMTPHost, Port: h.cfg.SMTPPort,
+		User: h.cfg.SMTPUser, Password: h.cfg.SMTPPassword,
+	}
+	if err := utils.SendOrgInviteEmail(email, org.Name, string(input.Role), inviter.GetDisplayName(), emailCfg); err != nil {
+		fmt.Printf("[DEV] Invite to %s for %s\n", org.Name, email)
+	}
+	if invitee.ID != 0 {
+		h.db.Create(&models.Notification{
+			UserID: invitee.ID, Type: "system",
+			Title:     "Team invitation",
+			Body:      fmt.Sprintf("%s invited you to join %s as %s", inviter.GetDisplayName(), org.Name, input.Role),
+			ActionURL: "solafon://developer/invites",
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{"success": true, "invite": formatOrgInvite(invite)})
+}
+
+// RevokeInvite — DELETE /api/orgs/:orgId/invites/:inviteId (admin)
+func (h *OrganizationsHandler) RevokeInvite(c *fiber.Ctx) error {
+	org, _, err := h.memberOrg(c, models.OrgRoleAdmin)
+	if err != nil {
+		return orgAccessError(c, err)
+	}
+	inviteID, err := paramID(c, "inviteId", publicid.OrgInvite)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	res := h.db.Model(&models.OrgInvite{}).
+		Where("id = ? AND organization_id = ? AND status = ?", inviteID, org.ID, models.OrgInvitePending).
+		Update("status", models.OrgInviteRevoked)
+	if res.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Invite not found"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// ListMyInvites — GET /api/orgs/invites
+// Open invites addressed to the caller's email.
+func (h *OrganizationsHandler) ListMyInvites(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "User not found"}})
+	}
+
+	var invites []models.OrgInvite
+	h.db.Preload("Organization").
+		Where("email = ? AND status = ? AND expires_at > ?", strings.ToLower(user.Email), models.OrgInvitePending, time.Now()).
+		Order("created_at DESC").Find(&invites)
+
+	result := []fiber.Map{}
+	for _, inv := range invites {
+		if inv.Organization.ID == 0 {
+			continue // Organization deleted
+		}
+		result = append(result, formatOrgInvite(inv))
+	}
+	return c.JSON(fiber.Map{"success": true, "invites": result})
+}
+
+// AcceptInvite — POST /api/orgs/invites/:inviteId/accept
+func (h *OrganizationsHandler) AcceptInvite(c *fiber.Ctx) error {
+	return h.respondToInvite(c, true)
+}
+
+// DeclineInvite — POST /api/orgs/invites/:inviteId/decline
+func (h *OrganizationsHandler) DeclineInvite(c *fiber.Ctx) error {
+	return h.respondToInvite(c, false)
+}
+
+// helpers
+
+func (h *OrganizationsHandler) respondToInvite(c *fiber.Ctx, accept bool) error {
+	userID := c.Locals("userID").(uint)
+	inviteID, err := paramID(c, "inviteId", publicid.OrgInvite)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "User not found"}})
+	}
+
+	var invite models.OrgInvite
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND email = ?", inviteID, strings.ToLower(user.Email)).First(&invite).Error; err != nil {
+			return err
+		}
+		if !invite.IsOpen() {
+			return errInviteNotOpen
+		}
+		if err := tx.First(&invite.Organization, invite.OrganizationID).Error; err != nil {
+			return errInviteNotOpen
+		}
+
+		now := time.Now()
+		status := models.OrgInviteDeclined
+		if accept {
+			status = models.OrgInviteAccepted
+			member := models.OrgMember{
+				OrganizationID: invite.OrganizationID, UserID: userID,
+				Role: invite.Role, InvitedBy: &invite.InvitedBy,
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+				return err
+			}
+		}
+		invite.Status, invite.RespondedAt = status, &now
+		return tx.Model(&invite).Updates(map[string]interface{}{"status": status, "responded_at": now}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Invite not found"}})
+	}
+	if errors.Is(err, errInviteNotOpen) {
+		return c.Status(410).JSON(fiber.Map{"error": fiber.Map{"code": "INVITE_EXPIRED", "message": "This invite has expired or was withdrawn"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to respond to invite"}})
+	}
+
+	result := fiber.Map{"success": true, "invite": formatOrgInvite(invite)}
+	if accept {
+		org := h.formatOrg(invite.Organization)
+		org["role"] = invite.Role
+		result["organization"] = org
+	}
+	return c.JSON(result)
+}
+
+// memberOrg loads the route's organization if the caller holds at least min in it
+func (h *OrganizationsHandler) memberOrg(c *fiber.Ctx, min models.OrgRole) (models.Organization, models.OrgRole, error) {
+	userID := c.Locals("userID").(uint)
+	var org models.Organization
+	orgID, err := paramID(c, "orgId", publicid.Organization)
+	if err != nil {
+		return org, "", err
+	}
+	role, ok := orgRole(h.db, orgID, userID)
+	if !ok {
+		return org, "", gorm.ErrRecordNotFound
+	}
+	if err := h.db.First(&org, orgID).Error; err != nil {
+		return org, "", err
+	}
+	if !role.AtLeast(min) {
+		return org, role, errRoleTooLow
+	}
+	return org, role, nil
+}
+
+// cleanOrgName trims an organization name and rejects empty, overlong and
+// multi-line names. The name ends up in invite email headers.
+func cleanOrgName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return "", false
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+// ensureAnotherOwner fails with errLastOwner unless someone besides userID owns the organization
+func ensureAnotherOwner(tx *gorm.DB, orgID, userID uint) error {
+	var owners []models.OrgMember
+	tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ?", orgID, models.OrgRoleOwner).Find(&owners)
+	for _, o := range owners {
+		if o.UserID != userID {
+			return nil
+		}
+	}
+	return errLastOwner
+}
+
+func orgAccessError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errRoleTooLow) {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "Your role in this organization doesn't allow this"}})
+	}
+	return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Organization not found"}})
+}
+
+func (h *OrganizationsHandler) formatOrg(org models.Organization) fiber.Map {
+	var members, apps int64
+	h.db.Model(&models.OrgMember{}).Where("organization_id = ?", org.ID).Count(&members)
+	h.db.Model(&models.MiniApp{}).Where("organization_id = ?", org.ID).Count(&apps)
+	return fiber.Map{
+		"id": publicid.Format(publicid.Organization, org.ID), "name": org.Name,
+		"membersCount": members, "appsCount": apps, "createdAt": org.CreatedAt,
+	}
+}
+
+func (h *OrganizationsHandler) formatMembers(orgID uint) []fiber.Map {
+	var members []models.OrgMember
+	h.db.Preload("User").Where("organization_id = ?", orgID).Order("created_at ASC").Find(&members)
+
+	result := make([]fiber.Map, len(members))
+	for i, m := range members {
+		result[i] = fiber.Map{
+			"userId": publicid.Format(publicid.User, m.UserID), "name": m.User.GetDisplayName(),
+			"email": m.User.Email, "avatarUrl": m.User.GetAvatarURL(),
+			"role": m.Role, "joinedAt": m.CreatedAt,
+		}
+	}
+	return result
+}
+
+func formatOrgInvite(inv models.OrgInvite) fiber.Map {
+	return fiber.Map{
+		"id":               publicid.Format(publicid.OrgInvite, inv.ID),
+		"organizationId":   publicid.Format(publicid.Organization, inv.OrganizationID),
+		"organizationName": inv.Organization.Name,
+		"email":            inv.Email, "role": inv.Role, "status": inv.Status,
+		"invitedBy": publicid.Format(publicid.User, inv.InvitedBy),
+		"expiresAt": inv.ExpiresAt, "createdAt": inv.CreatedAt,
+	}
+}
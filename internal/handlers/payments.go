@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errInvoiceNotPending = errors.New("invoice is not pending")
+	errInvoiceNotPaid    = errors.New("invoice is not paid")
+	errInsufficientMana  = errors.New("not enough Mana Points")
+)
+
+// PaymentsHandler handles /api/payments/* endpoints and developer refunds
+type PaymentsHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewPaymentsHandler(db *gorm.DB, cfg *config.Config) *PaymentsHandler {
+	return &PaymentsHandler{db: db, cfg: cfg}
+}
+
+// ListMyInvoices — GET /api/payments/invoices
+func (h *PaymentsHandler) ListMyInvoices(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	status := c.Query("status")
+
+	query := h.db.Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invoices []models.Invoice
+	query.Preload("App").Order("created_at DESC").Limit(100).Find(&invoices)
+
+	result := make([]fiber.Map, len(invoices))
+	for i, inv := range invoices {
+		result[i] = formatInvoice(inv)
+	}
+	return c.JSON(fiber.Map{"invoices": result})
+}
+
+// GetInvoice — GET /api/payments/invoices/:invoiceId
+func (h *PaymentsHandler) GetInvoice(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	invoiceID, err := paramID(c, "invoiceId", publicid.Invoice)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var invoice models.Invoice
+	if err := h.db.Where("id = ? AND user_id = ?", invoiceID, userID).Preload("App").First(&invoice).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Invoice not found"}})
+	}
+	return c.JSON(fiber.Map{"invoice": formatInvoice(invoice)})
+}
+
+// PayInvoice — POST /api/payments/invoices/:invoiceId/pay
+func (h *PaymentsHandler) PayInvoice(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	invoiceID, err := paramID(c, "invoiceId", publicid.Invoice)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var invoice models.Invoice
+	if err := h.db.Where("id = ? AND user_id = ?", invoiceID, userID).Preload("App").First(&invoice).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Invoice not found"}})
+	}
+
+	if err := payInvoice(h.db, c, h.cfg, &invoice); err != nil {
+		switch err {
+		case errInsufficientMana:
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "INSUFFICIENT_BALANCE", "message": err.Error()}})
+		case errInvoiceNotPending:
+			return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "INVOICE_NOT_PENDING", "message": "Invoice is already " + invoice.Status}})
+		default:
+			return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Payment failed"}})
+		}
+	}
+
+	var user models.User
+	h.db.First(&user, userID)
+
+	return c.JSON(fiber.Map{
+		"success": true, "invoice": formatInvoice(invoice), "newBalance": user.ManaPoints,
+	})
+}
+
+// ListAppInvoices — GET /api/developer/apps/:appId/invoices
+func (h *PaymentsHandler) ListAppInvoices(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAnalyst)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	query := h.db.Where("app_id = ?", app.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invoices []models.Invoice
+	query.Order("created_at DESC").Limit(100).Find(&invoices)
+
+	result := make([]fiber.Map, len(invoices))
+	for i, inv := range invoices {
+		inv.App = app
+		result[i] = formatInvoice(inv)
+		result[i]["userId"] = publicid.Format(publicid.User, inv.UserID)
+	}
+	return c.JSON(fiber.Map{"invoices": result})
+}
+
+// RefundInvoice — POST /api/developer/apps/:appId/invoices/:invoiceId/refund
+func (h *PaymentsHandler) RefundInvoice(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	invoiceID, err := paramID(c, "invoiceId", publicid.Invoice)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleAdmin)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	var invoice models.Invoice
+	if err := h.db.Where("id = ? AND app_id = ?", invoiceID, app.ID).First(&invoice).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Invoice not found"}})
+	}
+	invoice.App = app
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	c.BodyParser(&input)
+
+	if err := refundInvoice(h.db, c, &invoice, input.Reason); err != nil {
+		if err == errInvoiceNotPaid {
+			return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "INVOICE_NOT_PAID", "message": "Only paid invoices can be refunded"}})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Refund failed"}})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "invoice": formatInvoice(invoice)})
+}
+
+// helpers
+
+// payInvoice debits the user, marks the invoice paid and
+// credits the developer ledger, then notifies the bot with payment.succeeded.
+// invoice.App must be loaded.
+func payInvoice(db *gorm.DB, c *fiber.Ctx, cfg *config.Config, invoice *models.Invoice) error {
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Invoice{}).
+			Where("id = ? AND status = ?", invoice.ID, models.InvoicePending).
+			Updates(map[string]interface{}{"status": models.InvoicePaid, "paid_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInvoiceNotPending
+		}
+
+		res = tx.Model(&models.User{}).
+			Where("id = ? AND mana_points >= ?", invoice.UserID, invoice.Amount).
+			UpdateColumn("mana_points", gorm.Expr("mana_points - ?", invoice.Amount))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInsufficientMana
+		}
+
+		mt := models.ManaTransaction{
+			UserID: invoice.UserID, Amount: -invoice.Amount, Type: "app_payment",
+			Description: fmt.Sprintf("%s: %s", invoice.App.Title, invoice.Title),
+			ReferenceID: &invoice.ID,
+		}
+		if err := tx.Create(&mt).Error; err != nil {
+			return err
+		}
+		balance, err := manaBalance(tx, invoice.UserID)
+		if err != nil {
+			return err
+		}
+		if err := recordBalanceChange(tx, c, balanceAudit(invoice.UserID, mt.Description), balance, -invoice.Amount); err != nil {
+			return err
+		}
+		if err := recordSaleEarning(tx, cfg, *invoice); err != nil {
+			return err
+		}
+		return tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Update("transaction_id", mt.ID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	db.First(invoice, invoice.ID)
+	dispatchBotEvent(db, invoice.App, "payment.succeeded", invoiceEventData(*invoice))
+	return nil
+}
+
+// refundInvoice returns the Mana Points of a paid invoice to the user,
+// reverses the developer earning and notifies the bot with payment.refunded. invoice.App must be loaded.
+func refundInvoice(db *gorm.DB, c *fiber.Ctx, invoice *models.Invoice, reason string) error {
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Invoice{}).
+			Where("id = ? AND status = ?", invoice.ID, models.InvoicePaid).
+			Updates(map[string]interface{}{
+				"status": models.InvoiceRefunded, "refunded_at": now, "refund_reason": reason,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInvoiceNotPaid
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", invoice.UserID).
+			UpdateColumn("mana_points", gorm.Expr("mana_points + ?", invoice.Amount)).Error; err != nil {
+			return err
+		}
+
+		mt := models.ManaTransaction{
+			UserID: invoice.UserID, Amount: invoice.Amount, Type: "refund",
+			Description: fmt.Sprintf("Refund from %s: %s", invoice.App.Title, invoice.Title),
+			ReferenceID: &invoice.ID,
+		}
+		if err := tx.Create(&mt).Error; err != nil {
+			return err
+		}
+		balance, err := manaBalance(tx, invoice.UserID)
+		if err != nil {
+			return err
+		}
+		// Refunds come from the app's developers or from the bot itself
+		entry := balanceAudit(invoice.UserID, mt.Description)
+		if c.Locals("userID") == nil {
+			entry.ActorType, entry.ActorID = models.AuditActorApp, &invoice.AppID
+		}
+		if err := recordBalanceChange(tx, c, entry, balance, invoice.Amount); err != nil {
+			return err
+		}
+		return reverseSaleEarning(tx, *invoice)
+	})
+	if err != nil {
+		return err
+	}
+
+	db.First(invoice, invoice.ID)
+	dispatchBotEvent(db, invoice.App, "payment.refunded", invoiceEventData(*invoice))
+	return nil
+}
+
+func invoiceEventData(inv models.Invoice) map[string]interface{} {
+	data := map[string]interface{}{
+		"invoice_id": inv.ID,
+		"chat_id":    inv.UserID,
+		"amount":     inv.Amount,
+		"currency":   "MP",
+		"payload":    inv.Payload,
+		"status":     inv.Status,
+	}
+	if inv.TransactionID != nil {
+		data["transaction_id"] = *inv.TransactionID
+	}
+	if inv.RefundReason != "" {
+		data["refund_reason"] = inv.RefundReason
+	}
+	return data
+}
+
+func formatInvoice(inv models.Invoice) fiber.Map {
+	return fiber.Map{
+		"id": publicid.Format(publicid.Invoice, inv.ID), "appId": publicid.Format(publicid.App, inv.AppID),
+		"appName": inv.App.Title, "appIcon": inv.App.Icon,
+		"title": inv.Title, "description": inv.Description,
+		"amount": inv.Amount, "currency": "MP", "status": inv.Status,
+		"paidAt": inv.PaidAt, "refundedAt": inv.RefundedAt, "refundReason": inv.RefundReason,
+		"createdAt": inv.CreatedAt,
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// PermissionsHandler handles mini-app permission consent endpoints
+type PermissionsHandler struct {
+	db *gorm.DB
+}
+
+func NewPermissionsHandler(db *gorm.DB) *PermissionsHandler {
+	return &PermissionsHandler{db: db}
+}
+
+// GetCatalogue — GET /api/apps/permissions
+func (h *PermissionsHandler) GetCatalogue(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"permissions": models.PermissionCatalogue})
+}
+
+// GetAppPermissions — GET /api/apps/:appId/permissions
+func (h *PermissionsHandler) GetAppPermissions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+
+	return c.JSON(fiber.Map{
+		"appId":       publicid.Format(publicid.App, app.ID),
+		"permissions": formatAppPermissions(app, grantedPermissions(h.db, userID, app.ID)),
+	})
+}
+
+// GrantPermissions — POST /api/apps/:appId/permissions
+func (h *PermissionsHandler) GrantPermissions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+
+	var input struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.BodyParser(&input); err != nil || len(input.Permissions) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "permissions are required"}})
+	}
+
+	for _, p := range input.Permissions {
+		if !app.DeclaresPermission(p) {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "PERMISSION_NOT_DECLARED", "message": fmt.Sprintf("App does not request permission %q", p)}})
+		}
+	}
+
+	for _, p := range input.Permissions {
+		grant := models.AppPermissionGrant{UserID: userID, AppID: app.ID, Permission: p}
+		h.db.Where(models.AppPermissionGrant{UserID: userID, AppID: app.ID, Permission: p}).FirstOrCreate(&grant)
+	}
+
+	return c.JSON(fiber.Map{
+		"success":     true,
+		"permissions": formatAppPermissions(app, grantedPermissions(h.db, userID, app.ID)),
+	})
+}
+
+// RevokePermission — DELETE /api/apps/:appId/permissions/:permission
+func (h *PermissionsHandler) RevokePermission(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	permission := c.Params("permission")
+
+	if !models.IsValidPermission(permission) {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Unknown permission"}})
+	}
+
+	h.db.Where("user_id = ? AND app_id = ? AND permission = ?", userID, appID, permission).
+		Delete(&models.AppPermissionGrant{})
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// RevokeAllPermissions — DELETE /api/apps/:appId/permissions
+func (h *PermissionsHandler) RevokeAllPermissions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	h.db.Where("user_id = ? AND app_id = ?", userID, appID).Delete(&models.AppPermissionGrant{})
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// ListMyGrants — GET /api/users/me/app-permissions
+func (h *PermissionsHandler) ListMyGrants(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var grants []models.AppPermissionGrant
+	h.db.Where("user_id = ?", userID).Preload("App").Order("app_id ASC, permission ASC").Find(&grants)
+
+	byApp := map[uint]fiber.Map{}
+	order := []uint{}
+	for _, g := range grants {
+		if g.App.ID == 0 {
+			continue
+		}
+		entry, ok := byApp[g.AppID]
+		if !ok {
+			entry = fiber.Map{
+				"appId": publicid.Format(publicid.App, g.AppID), "appName": g.App.Title,
+				"appIcon": g.App.Icon, "appIconUrl": g.App.IconURL,
+				"permissions": []fiber.Map{},
+			}
+			byApp[g.AppID] = entry
+			order = append(order, g.AppID)
+		}
+		entry["permissions"] = append(entry["permissions"].([]fiber.Map), fiber.Map{
+			"permission": g.Permission, "grantedAt": g.CreatedAt,
+		})
+	}
+
+	result := make([]fiber.Map, 0, len(order))
+	for _, id := range order {
+		result = append(result, byApp[id])
+	}
+	return c.JSON(fiber.Map{"apps": result})
+}
+
+// helpers
+
+// grantedPermissions returns the permissions a user has granted to an app,
+// limited to the ones the app still declares
+func grantedPermissions(db *gorm.DB, userID, appID uint) map[string]bool {
+	var app models.MiniApp
+	if err := db.First(&app, appID).Error; err != nil {
+		return map[string]bool{}
+	}
+
+	var keys []string
+	db.Model(&models.AppPermissionGrant{}).Where("user_id = ? AND app_id = ?", userID, appID).
+		Pluck("permission", &keys)
+
+	granted := map[string]bool{}
+	for _, k := range keys {
+		if app.DeclaresPermission(k) {
+			granted[k] = true
+		}
+	}
+	return granted
+}
+
+// hasPermission reports whether the user granted a declared permission to the app
+func hasPermission(db *gorm.DB, userID, appID uint, permission string) bool {
+	return grantedPermissions(db, userID, appID)[permission]
+}
+
+// grantedList returns granted permissions in catalogue order
+func grantedList(granted map[string]bool) []string {
+	list := []string{}
+	for _, info := range models.PermissionCatalogue {
+		if granted[info.Key] {
+			list = append(list, info.Key)
+		}
+	}
+	return list
+}
+
+// encodePermissions validates declared permissions and returns them as jsonb
+func encodePermissions(perms []string) (string, error) {
+	seen := map[string]bool{}
+	clean := []string{}
+	for _, p := range perms {
+		if !models.IsValidPermission(p) {
+			return "", fmt.Errorf("unknown permission %q", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			clean = append(clean, p)
+		}
+	}
+	b, _ := json.Marshal(clean)
+	return string(b), nil
+}
+
+func formatAppPermissions(app models.MiniApp, granted map[string]bool) []fiber.Map {
+	declared := app.DeclaredPermissions()
+	result := make([]fiber.Map, 0, len(declared))
+	for _, key := range declared {
+		for _, info := range models.PermissionCatalogue {
+			if info.Key == key {
+				result = append(result, fiber.Map{
+					"key": info.Key, "title": info.Title,
+					"description": info.Description, "granted": granted[key],
+				})
+			}
+		}
+	}
+	return result
+}
+
+// botUserPayload builds the Bot API "from" object exposing only granted fields
+func botUserPayload(db *gorm.DB, user models.User, appID uint) map[string]interface{} {
+	granted := grantedPermissions(db, user.ID, appID)
+	from := map[string]interface{}{"id": user.ID}
+	if granted[models.PermissionProfile] {
+		from["name"] = user.GetDisplayName()
+		from["language"] = user.Language
+	}
+	if granted[models.PermissionEmail] {
+		from["email"] = user.Email
+	}
+	if granted[models.PermissionWalletAddress] && user.WalletAddress != "" {
+		from["wallet_address"] = user.WalletAddress
+	}
+	return from
+}
+
+// launchUserPayload builds the user object passed to a mini-app on launch
+func launchUserPayload(user models.User, granted map[string]bool) fiber.Map {
+	data := fiber.Map{"id": publicid.Format(publicid.User, user.ID)}
+	if granted[models.PermissionProfile] {
+		data["displayName"] = user.GetDisplayName()
+		data["avatarUrl"] = user.GetAvatarURL()
+		data["language"] = user.Language
+	}
+	if granted[models.PermissionEmail] {
+		data["email"] = user.Email
+	}
+	if granted[models.PermissionWalletAddress] && user.WalletAddress != "" {
+		data["walletAddress"] = user.WalletAddress
+	}
+	return data
+}
@@ -258,6 +258,7 @@ func (h *ProfileHandler) TopUpMana(c *fiber.Ctx) error {
 	// Update balance
 	user.ManaPoints += input.Amount
 	h.db.Save(&user)
+	recordBalanceChange(h.db, c, balanceAudit(userID, "Mana Points top up"), user.ManaPoints, input.Amount)
 
 	return c.JSON(fiber.Map{
 		"message":    "Mana Points added successfully",
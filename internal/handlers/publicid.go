@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+)
+
+// paramID parses a public ID route param, e.g. paramID(c, "appId", publicid.App)
+// for "/apps/app_12"
+func paramID(c *fiber.Ctx, name, kind string) (uint, error) {
+	return publicid.Parse(c.Params(name), kind)
+}
+
+// formatOptionalID formats a nullable foreign key, keeping null as null
+func formatOptionalID(kind string, id *uint) interface{} {
+	if id == nil {
+		return nil
+	}
+	return publicid.Format(kind, *id)
+}
+
+func invalidIDError(c *fiber.Ctx, err error) error {
+	return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_ID", "message": err.Error()}})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecommendationsHandler serves precomputed app recommendations and app blocking
+type RecommendationsHandler struct {
+	db *gorm.DB
+}
+
+func NewRecommendationsHandler(db *gorm.DB) *RecommendationsHandler {
+	return &RecommendationsHandler{db: db}
+}
+
+// GetRecommended — GET /api/apps/recommended?limit=
+// Reads recommendations built by jobs.ComputeRecommendations and tops them up with
+// popular apps, which is all a cold-start user gets.
+func (h *RecommendationsHandler) GetRecommended(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	// Recommendations may be hours old — re-check what the user used or blocked since
+	var excluded []uint
+	h.db.Model(&models.AppUser{}).Where("user_id = ?", userID).Pluck("app_id", &excluded)
+	var blocked []uint
+	h.db.Model(&models.AppBlock{}).Where("user_id = ?", userID).Pluck("app_id", &blocked)
+	excluded = append(excluded, blocked...)
+
+	var recs []models.AppRecommendation
+	query := h.db.Where("user_id = ?", userID)
+	if len(excluded) > 0 {
+		query = query.Where("app_id NOT IN ?", excluded)
+	}
+	query.Order("score DESC").Limit(limit).Find(&recs)
+
+	ids := make([]uint, len(recs))
+	for i, r := range recs {
+		ids[i] = r.AppID
+	}
+	appsByID := map[uint]models.MiniApp{}
+	if len(ids) > 0 {
+		var apps []models.MiniApp
+		listedApps(h.db.Preload("Category").Preload("Creator")).
+			Where("id IN ? AND is_secret = false", ids).Find(&apps)
+		for _, a := range apps {
+			appsByID[a.ID] = a
+		}
+	}
+
+	// Names of the apps behind "because you use X"
+	sourceNames := map[uint]string{}
+	var sourceIDs []uint
+	for _, r := range recs {
+		if r.SourceAppID != nil {
+			sourceIDs = append(sourceIDs, *r.SourceAppID)
+		}
+	}
+	if len(sourceIDs) > 0 {
+		var sources []models.MiniApp
+		h.db.Select("id, title").Where("id IN ?", sourceIDs).Find(&sources)
+		for _, s := range sources {
+			sourceNames[s.ID] = s.Title
+		}
+	}
+
+	result := []fiber.Map{}
+	seen := map[uint]bool{}
+	for _, r := range recs {
+		app, ok := appsByID[r.AppID]
+		if !ok {
+			continue
+		}
+		item := formatMarketApp(app)
+		reason := fiber.Map{"type": r.Reason}
+		if r.SourceAppID != nil {
+			reason["appId"] = publicid.Format(publicid.App, *r.SourceAppID)
+			reason["appName"] = sourceNames[*r.SourceAppID]
+		}
+		item["reason"] = reason
+		result = append(result, item)
+		seen[app.ID] = true
+	}
+
+	// Popularity fallback
+	if len(result) < limit {
+		for id := range seen {
+			excluded = append(excluded, id)
+		}
+		fill := listedApps(h.db.Preload("Category").Preload("Creator")).
+			Where("is_secret = false")
+		if len(excluded) > 0 {
+			fill = fill.Where("id NOT IN ?", excluded)
+		}
+		var popular []models.MiniApp
+		fill.Order("users_count DESC, rating DESC").Limit(limit - len(result)).Find(&popular)
+		for _, a := range popular {
+			item := formatMarketApp(a)
+			item["reason"] = fiber.Map{"type": models.RecommendPopular}
+			result = append(result, item)
+		}
+	}
+
+	return c.JSON(fiber.Map{"success": true, "apps": result})
+}
+
+// BlockApp — POST /api/apps/:appId/block
+func (h *RecommendationsHandler) BlockApp(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+
+	h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.AppBlock{UserID: userID, AppID: app.ID})
+	h.db.Where("user_id = ? AND app_id = ?", userID, app.ID).Delete(&models.AppRecommendation{})
+
+	return c.JSON(fiber.Map{"success": true, "isBlocked": true})
+}
+
+// UnblockApp — DELETE /api/apps/:appId/block
+func (h *RecommendationsHandler) UnblockApp(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	h.db.Where("user_id = ? AND app_id = ?", userID, appID).Delete(&models.AppBlock{})
+	return c.JSON(fiber.Map{"success": true, "isBlocked": false})
+}
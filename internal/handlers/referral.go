@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -35,7 +36,7 @@ func (h *ReferralHandler) GetReferralInfo(c *fiber.Ctx) error {
 	refs := make([]fiber.Map, len(referrals))
 	for i, r := range referrals {
 		refs[i] = fiber.Map{
-			"id": fmt.Sprintf("user_%d", r.ReferredID),
+			"id": publicid.Format(publicid.User, r.ReferredID),
 			"displayName":  r.Referred.GetDisplayName(),
 			"avatarUrl":    r.Referred.GetAvatarURL(),
 			"registeredAt": r.RegisteredAt,
@@ -0,0 +1,686 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/notify"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errReleaseOpen     = errors.New("another release is already in progress")
+	errReleaseInReview = errors.New("a release is waiting for review")
+	errReleaseStatus   = errors.New("release status does not allow this action")
+	errInvalidVersion  = errors.New("version must look like 1.2.3 and be greater than the live version")
+	errReleaseClaimed  = errors.New("another moderator has claimed this release")
+
+	versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)$`)
+)
+
+// ReleasesHandler handles app versions: drafts, review, publishing and rollback
+type ReleasesHandler struct {
+	db *gorm.DB
+}
+
+func NewReleasesHandler(db *gorm.DB) *ReleasesHandler {
+	return &ReleasesHandler{db: db}
+}
+
+// releaseInput — fields a developer can set on a draft release
+type releaseInput struct {
+	Version         *string  `json:"version"`
+	Name            *string  `json:"name"`
+	Subtitle        *string  `json:"subtitle"`
+	Description     *string  `json:"description"`
+	LongDescription *string  `json:"longDescription"` // Markdown
+	Icon            *string  `json:"icon"`
+	IconURL         *string  `json:"iconUrl"`
+	URL             *string  `json:"url"`
+	Category        *string  `json:"category"`
+	Permissions     []string `json:"permissions"`
+	Tags            []string `json:"tags"`
+	Screenshots     []string `json:"screenshots"` // Ordered upload URLs
+	Changelog       *string  `json:"changelog"`
+}
+
+// ListReleases — GET /api/developer/apps/:appId/releases
+func (h *ReleasesHandler) ListReleases(c *fiber.Ctx) error {
+	app, err := h.teamApp(c, models.OrgRoleAnalyst)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	var releases []models.AppRelease
+	h.db.Where("app_id = ?", app.ID).Order("created_at DESC").Find(&releases)
+
+	result := make([]fiber.Map, len(releases))
+	for i, r := range releases {
+		result[i] = formatRelease(r, app)
+	}
+	return c.JSON(fiber.Map{"success": true, "liveVersion": app.Version, "releases": result})
+}
+
+// GetRelease — GET /api/developer/apps/:appId/releases/:releaseId
+func (h *ReleasesHandler) GetRelease(c *fiber.Ctx) error {
+	app, err := h.teamApp(c, models.OrgRoleAnalyst)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+	release, err := h.findRelease(c, app.ID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Release not found"}})
+	}
+	return c.JSON(fiber.Map{"success": true, "release": formatRelease(release, app)})
+}
+
+// CreateRelease — POST /api/developer/apps/:appId/releases
+// Starts a draft from the live listing; only one draft or pending release per app.
+func (h *ReleasesHandler) CreateRelease(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	app, err := h.teamApp(c, models.OrgRoleDeveloper)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	var input releaseInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
+	}
+
+	var release models.AppRelease
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockOpenRelease(tx, app.ID, 0); err != nil {
+			return err
+		}
+
+		release = releaseFromApp(app)
+		release.Status = models.ReleaseDraft
+		release.Version = nextPatchVersion(app.Version)
+		release.CreatedBy = userID
+		if err := applyReleaseInput(tx, userID, &release, input, app.Version); err != nil {
+			return err
+		}
+		return tx.Create(&release).Error
+	})
+	if err != nil {
+		return releaseError(c, err)
+	}
+
+	return c.Status(201).JSON(fiber.Map{"success": true, "release": formatRelease(release, app)})
+}
+
+// UpdateRelease — PUT /api/developer/apps/:appId/releases/:releaseId
+// Editing a rejected release turns it back into a draft.
+func (h *ReleasesHandler) UpdateRelease(c *fiber.Ctx) error {
+	app, err := h.teamApp(c, models.OrgRoleDeveloper)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+	release, err := h.findRelease(c, app.ID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Release not found"}})
+	}
+
+	var input releaseInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockOpenRelease(tx, app.ID, release.ID); err != nil {
+			return err
+		}
+		// Re-read under the app lock so a concurrent submit isn't overwritten
+		if err := tx.First(&release, release.ID).Error; err != nil {
+			return err
+		}
+		if release.Status != models.ReleaseDraft && release.Status != models.ReleaseRejected {
+			return errReleaseStatus
+		}
+		if err := applyReleaseInput(tx, c.Locals("userID").(uint), &release, input, app.Version); err != nil {
+			return err
+		}
+		release.Status = models.ReleaseDraft
+		return tx.Save(&release).Error
+	})
+	if err != nil {
+		return releaseError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"success": true, "release": formatRelease(release, app)})
+}
+
+// DeleteRelease — DELETE /api/developer/apps/:appId/releases/:releaseId (drafts and rejected only)
+func (h *ReleasesHandler) DeleteRelease(c *fiber.Ctx) error {
+	app, err := h.teamApp(c, models.OrgRoleDeveloper)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+	releaseID, err := paramID(c, "releaseId", publicid.Release)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	res := h.db.Where("id = ? AND app_id = ? AND status IN ?", releaseID, app.ID,
+		[]string{models.ReleaseDraft, models.ReleaseRejected}).Delete(&models.AppRelease{})
+	if res.RowsAffected == 0 {
+		return releaseError(c, errReleaseStatus)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// SubmitRelease — POST /api/developer/apps/:appId/releases/:releaseId/submit
+func (h *ReleasesHandler) SubmitRelease(c *fiber.Ctx) error {
+	app, err := h.teamApp(c, models.OrgRoleDeveloper)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+	release, err := h.findRelease(c, app.ID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Release not found"}})
+	}
+	if release.Status != models.ReleaseDraft {
+		return releaseError(c, errReleaseStatus)
+	}
+	if strings.TrimSpace(release.Changelog) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "changelog is required to submit a release"}})
+	}
+
+	now := time.Now()
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockOpenRelease(tx, app.ID, release.ID); err != nil {
+			return err
+		}
+		res := tx.Model(&models.AppRelease{}).Where("id = ? AND status = ?", release.ID, models.ReleaseDraft).
+			Updates(map[string]interface{}{
+				"status": models.ReleasePending, "submitted_at": now, "review_note": "",
+				"risk_flags": "", "checked_at": nil,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errReleaseStatus
+		}
+		return nil
+	})
+	if err != nil {
+		return releaseError(c, err)
+	}
+	release.Status, release.SubmittedAt = models.ReleasePending, &now
+
+	return c.JSON(fiber.Map{
+		"success": true, "release": formatRelease(release, app),
+		"message": "Release submitted for review. The current version stays live until it is approved.",
+	})
+}
+
+// RollbackRelease — POST /api/developer/apps/:appId/releases/:releaseId/rollback
+// Republishes a previously approved release without another review.
+func (h *ReleasesHandler) RollbackRelease(c *fiber.Ctx) error {
+	app, err := h.teamApp(c, models.OrgRoleAdmin)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+	release, err := h.findRelease(c, app.ID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Release not found"}})
+	}
+	if release.Status != models.ReleaseApproved || (app.LiveReleaseID != nil && *app.LiveReleaseID == release.ID) {
+		return releaseError(c, errReleaseStatus)
+	}
+
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		return publishRelease(tx, &app, &release)
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Rollback failed"}})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "liveVersion": app.Version, "release": formatRelease(release, app)})
+}
+
+// GetChangelog — GET /api/apps/:appId/changelog
+func (h *ReleasesHandler) GetChangelog(c *fiber.Ctx) error {
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := listedApps(h.db).First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+
+	var releases []models.AppRelease
+	h.db.Where("app_id = ? AND status = ? AND published_at IS NOT NULL", app.ID, models.ReleaseApproved).
+		Order("published_at DESC").Limit(50).Find(&releases)
+
+	result := make([]fiber.Map, len(releases))
+	for i, r := range releases {
+		result[i] = fiber.Map{
+			"version": r.Version, "changelog": r.Changelog, "publishedAt": r.PublishedAt,
+			"isCurrent": app.LiveReleaseID != nil && *app.LiveReleaseID == r.ID,
+		}
+	}
+	return c.JSON(fiber.Map{"success": true, "currentVersion": app.Version, "changelog": result})
+}
+
+// AdminListReleases — GET /api/admin/releases?status=pending
+func (h *ReleasesHandler) AdminListReleases(c *fiber.Ctx) error {
+	status := c.Query("status", models.ReleasePending)
+
+	var releases []models.AppRelease
+	h.db.Preload("App").Where("status = ?", status).Order("submitted_at ASC, id ASC").Limit(100).Find(&releases)
+
+	result := make([]fiber.Map, len(releases))
+	for i, r := range releases {
+		result[i] = formatRelease(r, r.App)
+		result[i]["appName"] = r.App.Title
+		result[i]["liveVersion"] = r.App.Version
+	}
+	return c.JSON(fiber.Map{"success": true, "releases": result})
+}
+
+// AdminApproveRelease — POST /api/admin/releases/:releaseId/approve
+func (h *ReleasesHandler) AdminApproveRelease(c *fiber.Ctx) error {
+	return h.reviewRelease(c, true)
+}
+
+// AdminRejectRelease — POST /api/admin/releases/:releaseId/reject
+func (h *ReleasesHandler) AdminRejectRelease(c *fiber.Ctx) error {
+	return h.reviewRelease(c, false)
+}
+
+func (h *ReleasesHandler) reviewRelease(c *fiber.Ctx, approve bool) error {
+	adminID := c.Locals("userID").(uint)
+	var input struct {
+		Note    string   `json:"note"`
+		Reasons []string `json:"reasons"` // Reason codes, required when rejecting
+	}
+	c.BodyParser(&input)
+	input.Note = strings.TrimSpace(input.Note)
+	if !approve {
+		if input.Note == "" {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "note is required when rejecting"}})
+		}
+		if err := validateReasons(input.Reasons); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+		}
+	} else {
+		input.Reasons = nil
+	}
+
+	releaseID, err := paramID(c, "releaseId", publicid.Release)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var release models.AppRelease
+	var app models.MiniApp
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&release, releaseID).Error; err != nil {
+			return err
+		}
+		if release.Status != models.ReleasePending {
+			return errReleaseStatus
+		}
+		now := time.Now()
+		if release.ClaimedByOther(adminID, now) {
+			return errReleaseClaimed
+		}
+		if err := tx.First(&app, release.AppID).Error; err != nil {
+			return err
+		}
+		action := models.ModerationActionRejected
+		if approve {
+			action = models.ModerationActionApproved
+		}
+		return DecideRelease(tx, &app, &release, &adminID, action, input.Note, input.Reasons)
+	})
+	if err == gorm.ErrRecordNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Release not found"}})
+	}
+	if err != nil {
+		return releaseError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"success": true, "release": formatRelease(release, app)})
+}
+
+// helpers
+
+// teamApp loads the route's app if the caller holds at least min on it
+func (h *ReleasesHandler) teamApp(c *fiber.Ctx, min models.OrgRole) (models.MiniApp, error) {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return models.MiniApp{}, err
+	}
+	return authorizeApp(h.db, appID, userID, min)
+}
+
+func (h *ReleasesHandler) findRelease(c *fiber.Ctx, appID uint) (models.AppRelease, error) {
+	var release models.AppRelease
+	releaseID, err := paramID(c, "releaseId", publicid.Release)
+	if err != nil {
+		return release, err
+	}
+	err = h.db.Where("id = ? AND app_id = ?", releaseID, appID).First(&release).Error
+	return release, err
+}
+
+// DecideRelease approves or rejects a pending release and records the decision in
+// the moderation history. moderatorID is nil for automated decisions such as the
+// release prechecks job. Runs inside a transaction.
+func DecideRelease(tx *gorm.DB, app *models.MiniApp, release *models.AppRelease, moderatorID *uint, action, note string, reasons []string) error {
+	now := time.Now()
+	encoded, _ := json.Marshal(reasons)
+	release.ReviewedBy = moderatorID
+	release.ReviewedAt = &now
+	release.ReviewNote = note
+	release.ReviewReasons = ""
+	if len(reasons) > 0 {
+		release.ReviewReasons = string(encoded)
+	}
+	release.ClaimedBy, release.ClaimedAt = nil, nil
+	if err := recordModeration(tx, *release, moderatorID, action, note, reasons); err != nil {
+		return err
+	}
+	// Apps approved before releases existed have no LiveReleaseID, so go by the
+	// app's own status
+	firstRelease := app.ModerationStatus != models.ModerationApproved
+
+	if action == models.ModerationActionApproved {
+		release.Status = models.ReleaseApproved
+		if err := publishRelease(tx, app, release); err != nil {
+			return err
+		}
+		return notify.ReleaseDecision(tx, *app, *release, firstRelease)
+	}
+
+	release.Status = models.ReleaseRejected
+	if err := tx.Save(release).Error; err != nil {
+		return err
+	}
+	// A live app keeps its current version; a first submission is rejected as a whole
+	if firstRelease {
+		if err := tx.Model(app).Updates(map[string]interface{}{
+			"moderation_status": models.ModerationRejected, "moderation_note": note, "moderated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return notify.ReleaseDecision(tx, *app, *release, firstRelease)
+}
+
+// releaseFromApp snapshots the app's current listing
+func releaseFromApp(app models.MiniApp) models.AppRelease {
+	return models.AppRelease{
+		AppID: app.ID, Version: app.Version,
+		Title: app.Title, Subtitle: app.Subtitle, Description: app.Description,
+		LongDescription: app.LongDescription, Icon: app.Icon, IconURL: app.IconURL,
+		URL: app.URL, CategoryID: app.CategoryID, Tags: app.Tags,
+		Screenshots: app.Screenshots, Permissions: app.Permissions,
+	}
+}
+
+// publishRelease makes release the live listing of app. Runs inside a transaction.
+func publishRelease(tx *gorm.DB, app *models.MiniApp, release *models.AppRelease) error {
+	now := time.Now()
+	release.PublishedAt = &now
+	if err := tx.Save(release).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"title": release.Title, "subtitle": release.Subtitle, "description": release.Description,
+		"long_description": release.LongDescription, "icon": release.Icon, "icon_url": release.IconURL,
+		"url": release.URL, "category_id": release.CategoryID, "tags": nullableJSON(release.Tags),
+		"screenshots": nullableJSON(release.Screenshots), "permissions": nullableJSON(release.Permissions),
+		"version": release.Version, "live_release_id": release.ID,
+		"moderation_status": models.ModerationApproved, "moderation_note": release.ReviewNote, "moderated_at": now,
+	}
+	if err := tx.Model(app).Updates(updates).Error; err != nil {
+		return err
+	}
+	if err := tx.First(app, app.ID).Error; err != nil {
+		return err
+	}
+	// Tags join the vocabulary once a listing using them goes live
+	return addTagsToVocabulary(tx, app.TagList())
+}
+
+// stageRelease records listing changes to a live app in its draft release instead of
+// touching the published listing. The draft is created from the live listing if needed.
+func stageRelease(db *gorm.DB, app models.MiniApp, userID uint, edit func(*models.AppRelease) error) (models.AppRelease, error) {
+	var release models.AppRelease
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		tx.Model(&models.AppRelease{}).Where("app_id = ? AND status = ?", app.ID, models.ReleasePending).Count(&pending)
+		if pending > 0 {
+			return errReleaseInReview
+		}
+
+		err := tx.Where("app_id = ? AND status IN ?", app.ID, []string{models.ReleaseDraft, models.ReleaseRejected}).
+			Order("created_at DESC").First(&release).Error
+		if err == gorm.ErrRecordNotFound {
+			release = releaseFromApp(app)
+			release.Version = nextPatchVersion(app.Version)
+			release.CreatedBy = userID
+		} else if err != nil {
+			return err
+		}
+
+		if err := edit(&release); err != nil {
+			return err
+		}
+		release.Status = models.ReleaseDraft
+		return tx.Save(&release).Error
+	})
+	return release, err
+}
+
+// resubmitRelease keeps the release under review in sync with an app that is not live
+// yet, where edits go straight to the listing. It creates the release if there is none.
+func resubmitRelease(db *gorm.DB, app models.MiniApp, userID uint) error {
+	snapshot := releaseFromApp(app)
+	now := time.Now()
+
+	var release models.AppRelease
+	err := db.Where("app_id = ? AND status IN ?", app.ID,
+		[]string{models.ReleaseDraft, models.ReleasePending, models.ReleaseRejected}).
+		Order("created_at DESC").First(&release).Error
+	if err == gorm.ErrRecordNotFound {
+		snapshot.Status = models.ReleasePending
+		snapshot.Changelog = "Initial release"
+		snapshot.CreatedBy = userID
+		snapshot.SubmittedAt = &now
+		return db.Create(&snapshot).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	snapshot.ID = release.ID
+	snapshot.Version = release.Version
+	snapshot.Status = models.ReleasePending
+	snapshot.Changelog = release.Changelog
+	snapshot.CreatedBy = release.CreatedBy
+	snapshot.CreatedAt = release.CreatedAt
+	snapshot.SubmittedAt = &now
+	return db.Save(&snapshot).Error
+}
+
+// setListing copies the listing fields of a release onto app, without saving
+func setListing(app *models.MiniApp, r models.AppRelease) {
+	app.Title, app.Subtitle, app.Description = r.Title, r.Subtitle, r.Description
+	app.LongDescription, app.Icon, app.IconURL = r.LongDescription, r.Icon, r.IconURL
+	app.URL, app.CategoryID = r.URL, r.CategoryID
+	app.Tags, app.Screenshots, app.Permissions = r.Tags, r.Screenshots, r.Permissions
+}
+
+func applyReleaseInput(db *gorm.DB, userID uint, release *models.AppRelease, input releaseInput, liveVersion string) error {
+	if input.Version != nil {
+		if !versionPattern.MatchString(*input.Version) || compareVersions(*input.Version, liveVersion) <= 0 {
+			return errInvalidVersion
+		}
+		release.Version = *input.Version
+	}
+	if input.Name != nil {
+		if strings.TrimSpace(*input.Name) == "" {
+			return errors.New("name cannot be empty")
+		}
+		release.Title = *input.Name
+	}
+	if input.Subtitle != nil {
+		release.Subtitle = *input.Subtitle
+	}
+	if input.Description != nil {
+		release.Description = *input.Description
+	}
+	if input.LongDescription != nil {
+		longDescription, err := cleanLongDescription(*input.LongDescription)
+		if err != nil {
+			return err
+		}
+		release.LongDescription = longDescription
+	}
+	if input.Icon != nil {
+		release.Icon = *input.Icon
+	}
+	if input.IconURL != nil {
+		release.IconURL = *input.IconURL
+	}
+	if input.URL != nil {
+		release.URL = *input.URL
+	}
+	if input.Category != nil {
+		var cat models.Category
+		if db.Where("slug = ? OR name = ?", *input.Category, *input.Category).First(&cat).Error == nil {
+			release.CategoryID = cat.ID
+		}
+	}
+	if input.Permissions != nil {
+		permissions, err := encodePermissions(input.Permissions)
+		if err != nil {
+			return err
+		}
+		release.Permissions = permissions
+	}
+	if input.Tags != nil {
+		tags, err := encodeTags(input.Tags)
+		if err != nil {
+			return err
+		}
+		release.Tags = tags
+	}
+	if input.Screenshots != nil {
+		screenshots, err := encodeScreenshots(db, userID, input.Screenshots, release.Screenshots)
+		if err != nil {
+			return err
+		}
+		release.Screenshots = screenshots
+	}
+	if input.Changelog != nil {
+		release.Changelog = *input.Changelog
+	}
+	return nil
+}
+
+// lockOpenRelease locks the app row, serializing release changes per app, and
+// returns errReleaseOpen when a draft or pending release other than exceptID exists
+func lockOpenRelease(tx *gorm.DB, appID, exceptID uint) error {
+	var app models.MiniApp
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&app, appID).Error; err != nil {
+		return err
+	}
+	var open int64
+	if err := tx.Model(&models.AppRelease{}).Where("app_id = ? AND id <> ? AND status IN ?", appID, exceptID,
+		[]string{models.ReleaseDraft, models.ReleasePending}).Count(&open).Error; err != nil {
+		return err
+	}
+	if open > 0 {
+		return errReleaseOpen
+	}
+	return nil
+}
+
+func releaseError(c *fiber.Ctx, err error) error {
+	switch err {
+	case errReleaseOpen:
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "RELEASE_IN_PROGRESS", "message": err.Error()}})
+	case errReleaseInReview:
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "RELEASE_IN_REVIEW", "message": "A release is waiting for review; changes can be made after it is approved or rejected"}})
+	case errReleaseStatus:
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_STATUS", "message": err.Error()}})
+	case errReleaseClaimed:
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "ALREADY_CLAIMED", "message": err.Error()}})
+	case errInvalidVersion:
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_VERSION", "message": err.Error()}})
+	}
+	if strings.Contains(err.Error(), "duplicate key") {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "VERSION_EXISTS", "message": "This version already exists"}})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+}
+
+// nullableJSON maps an empty jsonb string to NULL, which Postgres accepts unlike ""
+func nullableJSON(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func compareVersions(a, b string) int {
+	pa, pb := versionPattern.FindStringSubmatch(a), versionPattern.FindStringSubmatch(b)
+	if pa == nil || pb == nil {
+		return strings.Compare(a, b)
+	}
+	for i := 1; i <= 3; i++ {
+		x, _ := strconv.Atoi(pa[i])
+		y, _ := strconv.Atoi(pb[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func nextPatchVersion(version string) string {
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return "1.0.1"
+	}
+	patch, _ := strconv.Atoi(m[3])
+	return fmt.Sprintf("%s.%s.%d", m[1], m[2], patch+1)
+}
+
+func formatRelease(r models.AppRelease, app models.MiniApp) fiber.Map {
+	var listing models.MiniApp
+	setListing(&listing, r)
+	return fiber.Map{
+		"id": publicid.Format(publicid.Release, r.ID), "appId": publicid.Format(publicid.App, r.AppID),
+		"version": r.Version, "status": r.Status, "changelog": r.Changelog,
+		"name": r.Title, "subtitle": r.Subtitle, "description": r.Description,
+		"longDescription": r.LongDescription, "tags": listing.TagList(), "screenshots": listing.ScreenshotList(),
+		"icon": r.Icon, "iconUrl": r.IconURL, "url": r.URL, "categoryId": r.CategoryID,
+		"reviewNote": r.ReviewNote, "reviewReasons": r.ReasonList(),
+		"submittedAt": r.SubmittedAt, "reviewedAt": r.ReviewedAt,
+		"publishedAt": r.PublishedAt, "createdAt": r.CreatedAt, "updatedAt": r.UpdatedAt,
+		"isLive":      app.LiveReleaseID != nil && *app.LiveReleaseID == r.ID,
+		"permissions": listing.DeclaredPermissions(),
+	}
+}
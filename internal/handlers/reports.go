@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errOwnContent      = errors.New("you cannot report your own content")
+	errReportAction    = errors.New("this action doesn't apply to the reported content")
+	errNoOpenReports   = errors.New("there are no open reports on this content")
+	errReportForbidden = errors.New("content not found")
+)
+
+// Public ID kind of each report target type
+var reportTargetKinds = map[string]string{
+	models.ReportTargetApp:     publicid.App,
+	models.ReportTargetPost:    publicid.Post,
+	models.ReportTargetComment: publicid.Comment,
+	models.ReportTargetMessage: publicid.Message,
+	models.ReportTargetReview:  publicid.Review,
+}
+
+// ReportsHandler handles abuse reports from users and their triage by admins
+type ReportsHandler struct {
+	db *gorm.DB
+}
+
+func NewReportsHandler(db *gorm.DB) *ReportsHandler {
+	return &ReportsHandler{db: db}
+}
+
+// reportTarget — the reported content with who is responsible for it
+type reportTarget struct {
+	Type      string
+	ID        uint
+	AuthorID  uint // User who wrote the content; the developer for apps, posts and bot messages
+	AppID     uint // App the content belongs to
+	VisibleTo uint // Only this user can see the content (bot messages); 0 when public
+	Hidden    bool
+	Preview   fiber.Map
+}
+
+// CreateReport — POST /api/reports
+// { "targetType": "app|post|comment|message|review", "targetId": "post_12", "reason": "spam", "details": "..." }
+func (h *ReportsHandler) CreateReport(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var input struct {
+		TargetType string `json:"targetType"`
+		TargetID   string `json:"targetId"`
+		Reason     string `json:"reason"`
+		Details    string `json:"details"`
+	}
+	if err := c.BodyParser(&input); err != nil || !models.ReportReasons[input.Reason] {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "reason must be one of spam, scam, offensive, harassment, sexual_content, violence, illegal, off_topic, other"}})
+	}
+	kind, ok := reportTargetKinds[input.TargetType]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "targetType must be one of app, post, comment, message, review"}})
+	}
+	targetID, err := publicid.Parse(input.TargetID, kind)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	target, err := loadReportTarget(h.db, input.TargetType, targetID)
+	if err == nil && target.VisibleTo != 0 && target.VisibleTo != userID {
+		err = errReportForbidden
+	}
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Content not found"}})
+	}
+
+	filed, err := fileReport(h.db, target, userID, input.Reason, input.Details)
+	if errors.Is(err, errOwnContent) {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": err.Error()}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to submit report"}})
+	}
+
+	message := "Report submitted"
+	if !filed {
+		message = "You already reported this"
+	}
+	return c.JSON(fiber.Map{"success": true, "message": message})
+}
+
+// AdminListReports — GET /api/admin/reports?status=open&targetType=
+// Reports are grouped by target, most reported first.
+func (h *ReportsHandler) AdminListReports(c *fiber.Ctx) error {
+	limit := pageLimit(c)
+	status := c.Query("status", models.ReportOpen)
+	targetType := c.Query("targetType")
+	if _, ok := reportTargetKinds[targetType]; targetType != "" && !ok {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "targetType must be one of app, post, comment, message, review"}})
+	}
+
+	query := h.db.Model(&models.ContentReport{}).
+		Select("target_type, target_id, COUNT(*) AS reports, MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at").
+		Group("target_type, target_id")
+	if status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var groups []struct {
+		TargetType      string
+		TargetID        uint
+		Reports         int
+		FirstReportedAt time.Time
+		LastReportedAt  time.Time
+	}
+	query.Order("reports DESC, first_reported_at ASC").Limit(limit).Scan(&groups)
+
+	result := make([]fiber.Map, len(groups))
+	for i, g := range groups {
+		var reasons []struct {
+			Reason string
+			Count  int
+		}
+		reasonQuery := h.db.Model(&models.ContentReport{}).Select("reason, COUNT(*) AS count").
+			Where("target_type = ? AND target_id = ?", g.TargetType, g.TargetID)
+		if status != "all" {
+			reasonQuery = reasonQuery.Where("status = ?", status)
+		}
+		reasonQuery.Group("reason").Order("count DESC").Scan(&reasons)
+		byReason := fiber.Map{}
+		for _, r := range reasons {
+			byReason[r.Reason] = r.Count
+		}
+
+		result[i] = fiber.Map{
+			"targetType": g.TargetType, "targetId": publicid.Format(reportTargetKinds[g.TargetType], g.TargetID),
+			"reportsCount": g.Reports, "reasons": byReason,
+			"firstReportedAt": g.FirstReportedAt, "lastReportedAt": g.LastReportedAt,
+			"target": h.targetSummary(g.TargetType, g.TargetID),
+		}
+	}
+	return c.JSON(fiber.Map{"success": true, "targets": result})
+}
+
+// AdminGetReports — GET /api/admin/reports/:targetType/:targetId
+func (h *ReportsHandler) AdminGetReports(c *fiber.Ctx) error {
+	targetType, targetID, err := reportTargetParams(c)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var reports []models.ContentReport
+	h.db.Preload("Reporter").Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("created_at DESC").Limit(maxPageLimit).Find(&reports)
+
+	result := make([]fiber.Map, len(reports))
+	for i, r := range reports {
+		result[i] = fiber.Map{
+			"id": publicid.Format(publicid.Report, r.ID), "reason": r.Reason, "details": r.Details,
+			"status": r.Status, "resolution": r.Resolution, "resolveNote": r.ResolveNote,
+			"resolvedBy": formatOptionalID(publicid.User, r.ResolvedBy), "resolvedAt": r.ResolvedAt,
+			"reporter": fiber.Map{
+				"id": publicid.Format(publicid.User, r.ReporterID), "email": r.Reporter.Email,
+				"name": r.Reporter.GetDisplayName(),
+			},
+			"createdAt": r.CreatedAt,
+		}
+	}
+	return c.JSON(fiber.Map{
+		"success": true, "target": h.targetSummary(targetType, targetID), "reports": result,
+	})
+}
+
+// AdminResolveReports — POST /api/admin/reports/:targetType/:targetId/resolve
+// { "action": "dismiss|remove|suspend_app|ban_user", "note": "..." }
+// Closes every open report on the target. Dismissing shows hidden content again;
+// the other actions keep it hidden or remove it.
+func (h *ReportsHandler) AdminResolveReports(c *fiber.Ctx) error {
+	adminID := c.Locals("userID").(uint)
+	targetType, targetID, err := reportTargetParams(c)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var input struct {
+		Action string `json:"action"`
+		Note   string `json:"note"`
+	}
+	c.BodyParser(&input)
+	input.Note = strings.TrimSpace(input.Note)
+	switch input.Action {
+	case models.ReportActionDismiss, models.ReportActionRemove:
+	case models.ReportActionSuspendApp, models.ReportActionBanUser:
+		if input.Note == "" {
+			return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "note is required to suspend an app or ban a user"}})
+		}
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "action must be one of dismiss, remove, suspend_app, ban_user"}})
+	}
+
+	var resolved int64
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		// Close the reports first so removing the content doesn't take them along
+		now := time.Now()
+		res := tx.Model(&models.ContentReport{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportOpen).
+			Updates(map[string]interface{}{
+				"status": models.ReportResolved, "resolution": input.Action, "resolve_note": input.Note,
+				"resolved_by": adminID, "resolved_at": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errNoOpenReports
+		}
+		resolved = res.RowsAffected
+
+		target, err := loadReportTarget(tx, targetType, targetID)
+		if errors.Is(err, gorm.ErrRecordNotFound) && input.Action == models.ReportActionDismiss {
+			return nil // Deleted since it was reported; only the reports are left to close
+		}
+		if err != nil {
+			return err
+		}
+
+		switch input.Action {
+		case models.ReportActionDismiss:
+			return setTargetHidden(tx, target, false)
+		case models.ReportActionRemove:
+			return removeReportTarget(tx, target)
+		case models.ReportActionSuspendApp:
+			if target.Type == models.ReportTargetComment || target.Type == models.ReportTargetReview {
+				return errReportAction
+			}
+			if err := setTargetHidden(tx, target, true); err != nil {
+				return err
+			}
+			return suspendApp(tx, target.AppID, input.Note)
+		default:
+			if err := setTargetHidden(tx, target, true); err != nil {
+				return err
+			}
+			var author models.User
+			if err := tx.First(&author, target.AuthorID).Error; err != nil {
+				return err
+			}
+			return sanctionUser(tx, c, author, models.AccountBanned, input.Note, nil, true)
+		}
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Content not found"}})
+	case errors.Is(err, errReportAction):
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	case errors.Is(err, errNoOpenReports):
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_STATUS", "message": err.Error()}})
+	case errors.Is(err, errSanctionSelf), errors.Is(err, errSanctionAdmin):
+		return sanctionError(c, err)
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to resolve reports"}})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "action": input.Action, "resolvedReports": resolved})
+}
+
+// AdminReinstateApp — POST /api/admin/apps/:appId/reinstate
+// Lifts a suspension and shows the app again if reports had hidden it.
+func (h *ReportsHandler) AdminReinstateApp(c *fiber.Ctx) error {
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	res := h.db.Model(&models.MiniApp{}).Where("id = ? AND (suspended_at IS NOT NULL OR hidden_at IS NOT NULL)", appID).
+		Updates(map[string]interface{}{"suspended_at": nil, "suspension_reason": "", "hidden_at": nil})
+	if res.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to reinstate app"}})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "No suspended or hidden app with this ID"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// helpers
+
+func reportTargetParams(c *fiber.Ctx) (string, uint, error) {
+	targetType := c.Params("targetType")
+	kind, ok := reportTargetKinds[targetType]
+	if !ok {
+		return "", 0, errors.New("targetType must be one of app, post, comment, message, review")
+	}
+	id, err := paramID(c, "targetId", kind)
+	return targetType, id, err
+}
+
+// loadReportTarget loads the reported content. Content that no longer exists
+// comes back as gorm.ErrRecordNotFound.
+func loadReportTarget(db *gorm.DB, targetType string, id uint) (reportTarget, error) {
+	t := reportTarget{Type: targetType, ID: id}
+	switch targetType {
+	case models.ReportTargetApp:
+		var app models.MiniApp
+		if err := db.First(&app, id).Error; err != nil {
+			return t, err
+		}
+		t.AuthorID, t.AppID, t.Hidden = app.CreatorID, app.ID, app.HiddenAt != nil
+		t.Preview = fiber.Map{
+			"name": app.Title, "icon": app.IconURL, "description": app.Description,
+			"suspendedAt": app.SuspendedAt, "isListed": app.UnavailableReason() == "",
+		}
+
+	case models.ReportTargetPost:
+		var post models.NewsPost
+		if err := db.Preload("App").First(&post, id).Error; err != nil {
+			return t, err
+		}
+		t.AuthorID, t.AppID, t.Hidden = post.App.CreatorID, post.AppID, post.HiddenAt != nil
+		t.Preview = fiber.Map{"text": post.Text, "imageUrl": post.ImageURL, "appName": post.App.Title}
+
+	case models.ReportTargetComment:
+		var comment models.NewsComment
+		if err := db.First(&comment, id).Error; err != nil {
+			return t, err
+		}
+		var post models.NewsPost
+		db.Unscoped().First(&post, comment.PostID)
+		t.AuthorID, t.AppID, t.Hidden = comment.UserID, post.AppID, comment.HiddenAt != nil
+		t.Preview = fiber.Map{"text": comment.Text, "postId": publicid.Format(publicid.Post, comment.PostID)}
+
+	case models.ReportTargetMessage:
+		var msg models.ChatMessage
+		if err := db.First(&msg, id).Error; err != nil {
+			return t, err
+		}
+		// Only bot messages are reportable; users' own messages stay private
+		if msg.SenderType != "bot" {
+			return t, gorm.ErrRecordNotFound
+		}
+		var conv models.Conversation
+		if err := db.Unscoped().Preload("App", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+			First(&conv, msg.ConversationID).Error; err != nil {
+			return t, err
+		}
+		t.AuthorID, t.AppID, t.VisibleTo, t.Hidden = conv.App.CreatorID, msg.AppID, conv.UserID, msg.HiddenAt != nil
+		t.Preview = fiber.Map{"content": msg.Content, "appName": conv.App.Title}
+
+	case models.ReportTargetReview:
+		var review models.AppReview
+		if err := db.First(&review, id).Error; err != nil {
+			return t, err
+		}
+		t.AuthorID, t.AppID, t.Hidden = review.UserID, review.AppID, review.HiddenAt != nil
+		t.Preview = fiber.Map{"rating": review.Rating, "text": review.Text}
+
+	default:
+		return t, gorm.ErrRecordNotFound
+	}
+	return t, nil
+}
+
+// fileReport records a report once per reporter and target, and hides the
+// content once enough people reported it. It reports false for a repeat report.
+func fileReport(db *gorm.DB, target reportTarget, reporterID uint, reason, details string) (bool, error) {
+	if target.AuthorID == reporterID {
+		return false, errOwnContent
+	}
+
+	filed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ContentReport{
+			TargetType: target.Type, TargetID: target.ID, ReporterID: reporterID,
+			Reason: reason, Details: strings.TrimSpace(details),
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		filed = true
+
+		if target.Type == models.ReportTargetReview {
+			if err := tx.Model(&models.AppReview{}).Where("id = ?", target.ID).
+				UpdateColumn("reports_count", gorm.Expr("reports_count + 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		if target.Hidden {
+			return nil
+		}
+		var open int64
+		tx.Model(&models.ContentReport{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", target.Type, target.ID, models.ReportOpen).
+			Count(&open)
+		if int(open) >= models.ReportAutoHide[target.Type] {
+			return setTargetHidden(tx, target, true)
+		}
+		return nil
+	})
+	return filed, err
+}
+
+// reportTargetModels maps a target type to the table holding it
+var reportTargetModels = map[string]interface{}{
+	models.ReportTargetApp:     &models.MiniApp{},
+	models.ReportTargetPost:    &models.NewsPost{},
+	models.ReportTargetComment: &models.NewsComment{},
+	models.ReportTargetMessage: &models.ChatMessage{},
+	models.ReportTargetReview:  &models.AppReview{},
+}
+
+// setTargetHidden hides content pending review, or shows it again. Hidden
+// reviews don't count towards the app's rating.
+func setTargetHidden(tx *gorm.DB, target reportTarget, hidden bool) error {
+	var hiddenAt interface{}
+	if hidden {
+		if target.Hidden {
+			return nil
+		}
+		hiddenAt = time.Now()
+	}
+	if err := tx.Model(reportTargetModels[target.Type]).Where("id = ?", target.ID).Update("hidden_at", hiddenAt).Error; err != nil {
+		return err
+	}
+	if target.Type == models.ReportTargetReview {
+		return recalcAppRating(tx, target.AppID)
+	}
+	return nil
+}
+
+// removeReportTarget deletes the reported content. An app is suspended instead,
+// since deleting it would hand the developer the restore window.
+func removeReportTarget(tx *gorm.DB, target reportTarget) error {
+	switch target.Type {
+	case models.ReportTargetApp:
+		if err := setTargetHidden(tx, target, true); err != nil {
+			return err
+		}
+		return suspendApp(tx, target.AppID, "Removed after user reports")
+	case models.ReportTargetPost:
+		return tx.Delete(&models.NewsPost{}, target.ID).Error
+	case models.ReportTargetComment:
+		var comment models.NewsComment
+		if err := tx.First(&comment, target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.NewsPost{}).Where("id = ?", comment.PostID).
+			UpdateColumn("comments_count", gorm.Expr("GREATEST(comments_count - 1, 0)")).Error
+	case models.ReportTargetMessage:
+		return tx.Delete(&models.ChatMessage{}, target.ID).Error
+	case models.ReportTargetReview:
+		var review models.AppReview
+		if err := tx.First(&review, target.ID).Error; err != nil {
+			return err
+		}
+		return deleteReview(tx, review)
+	}
+	return errReportAction
+}
+
+// suspendApp takes an app down until an admin reinstates it
+func suspendApp(tx *gorm.DB, appID uint, reason string) error {
+	return tx.Model(&models.MiniApp{}).Where("id = ?", appID).Updates(map[string]interface{}{
+		"suspended_at": time.Now(), "suspension_reason": reason,
+	}).Error
+}
+
+// targetSummary describes a target for the triage queue, including content
+// that has been deleted since it was reported
+func (h *ReportsHandler) targetSummary(targetType string, id uint) fiber.Map {
+	target, err := loadReportTarget(h.db, targetType, id)
+	if err != nil {
+		return fiber.Map{"exists": false}
+	}
+	var author models.User
+	h.db.Unscoped().First(&author, target.AuthorID)
+	return fiber.Map{
+		"exists": true, "isHidden": target.Hidden, "preview": target.Preview,
+		"appId": publicid.Format(publicid.App, target.AppID),
+		"author": fiber.Map{
+			"id": publicid.Format(publicid.User, author.ID), "email": author.Email,
+			"name": author.GetDisplayName(), "status": author.Status,
+		},
+	}
+}
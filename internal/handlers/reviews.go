@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxReviewLength = 2000
+
+// ReviewsHandler handles app reviews and ratings
+type ReviewsHandler struct {
+	db *gorm.DB
+}
+
+func NewReviewsHandler(db *gorm.DB) *ReviewsHandler {
+	return &ReviewsHandler{db: db}
+}
+
+// ListReviews — GET /api/apps/:appId/reviews?sort=newest|helpful&page=&limit=
+func (h *ReviewsHandler) ListReviews(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+
+	order := "created_at DESC, id DESC"
+	if c.Query("sort") == "helpful" {
+		order = "helpful_count DESC, created_at DESC, id DESC"
+	}
+
+	var total int64
+	h.db.Model(&models.AppReview{}).Where("app_id = ? AND hidden_at IS NULL", app.ID).Count(&total)
+
+	var reviews []models.AppReview
+	h.db.Where("app_id = ? AND hidden_at IS NULL", app.ID).Preload("User").Order(order).Offset(offset).Limit(limit).Find(&reviews)
+
+	// Which of these reviews the current user already marked helpful
+	ids := make([]uint, len(reviews))
+	for i, r := range reviews {
+		ids[i] = r.ID
+	}
+	voted := map[uint]bool{}
+	if len(ids) > 0 {
+		var votedIDs []uint
+		h.db.Model(&models.ReviewHelpfulVote{}).Where("user_id = ? AND review_id IN ?", userID, ids).Pluck("review_id", &votedIDs)
+		for _, id := range votedIDs {
+			voted[id] = true
+		}
+	}
+
+	result := make([]fiber.Map, len(reviews))
+	for i, r := range reviews {
+		result[i] = formatReview(r)
+		result[i]["isMine"] = r.UserID == userID
+		result[i]["markedHelpful"] = voted[r.ID]
+	}
+
+	var distribution []struct {
+		Rating int
+		Count  int
+	}
+	h.db.Model(&models.AppReview{}).Select("rating, COUNT(*) AS count").
+		Where("app_id = ? AND hidden_at IS NULL", app.ID).Group("rating").Scan(&distribution)
+	stars := fiber.Map{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}
+	for _, d := range distribution {
+		stars[strconv.Itoa(d.Rating)] = d.Count
+	}
+
+	var mine *fiber.Map
+	var myReview models.AppReview
+	if h.db.Where("app_id = ? AND user_id = ?", app.ID, userID).Preload("User").First(&myReview).Error == nil {
+		m := formatReview(myReview)
+		mine = &m
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"summary": fiber.Map{
+			"rating": app.Rating, "reviewsCount": app.ReviewsCount, "distribution": stars,
+		},
+		"myReview": mine,
+		"reviews":  result,
+		"pagination": fiber.Map{
+			"page": page, "limit": limit, "total": total, "hasMore": page < totalPages,
+		},
+	})
+}
+
+// GetMyReview — GET /api/apps/:appId/reviews/me (includes edit history)
+func (h *ReviewsHandler) GetMyReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var review models.AppReview
+	if err := h.db.Where("app_id = ? AND user_id = ?", appID, userID).Preload("User").First(&review).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+
+	var revisions []models.AppReviewRevision
+	h.db.Where("review_id = ?", review.ID).Order("created_at DESC").Find(&revisions)
+
+	history := make([]fiber.Map, len(revisions))
+	for i, rev := range revisions {
+		history[i] = fiber.Map{"rating": rev.Rating, "text": rev.Text, "replacedAt": rev.CreatedAt}
+	}
+
+	result := formatReview(review)
+	result["history"] = history
+	return c.JSON(fiber.Map{"success": true, "review": result})
+}
+
+// CreateReview — POST /api/apps/:appId/reviews
+func (h *ReviewsHandler) CreateReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	input, errMsg := parseReviewInput(c)
+	if errMsg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": errMsg}})
+	}
+
+	var app models.MiniApp
+	if err := h.db.First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+	if _, onTeam := appRole(h.db, app, userID); onTeam {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "You cannot review your own app"}})
+	}
+	var used int64
+	h.db.Model(&models.AppUser{}).Where("user_id = ? AND app_id = ?", userID, app.ID).Count(&used)
+	if used == 0 {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "APP_NOT_USED", "message": "Use the app before reviewing it"}})
+	}
+
+	review := models.AppReview{AppID: app.ID, UserID: userID, Rating: input.Rating, Text: input.Text}
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&review)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrDuplicatedKey
+		}
+		return recalcAppRating(tx, app.ID)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "ALREADY_REVIEWED", "message": "You already reviewed this app"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to save review"}})
+	}
+
+	h.db.Preload("User").First(&review, review.ID)
+	return c.Status(201).JSON(fiber.Map{"success": true, "review": formatReview(review)})
+}
+
+// UpdateMyReview — PUT /api/apps/:appId/reviews/me
+func (h *ReviewsHandler) UpdateMyReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	input, errMsg := parseReviewInput(c)
+	if errMsg != "" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": errMsg}})
+	}
+
+	var review models.AppReview
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("app_id = ? AND user_id = ?", appID, userID).First(&review).Error; err != nil {
+			return err
+		}
+		if review.Rating == input.Rating && review.Text == input.Text {
+			return nil
+		}
+
+		// Keep the version being replaced
+		if err := tx.Create(&models.AppReviewRevision{
+			ReviewID: review.ID, Rating: review.Rating, Text: review.Text,
+		}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&review).Updates(map[string]interface{}{
+			"rating": input.Rating, "text": input.Text, "edited_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return recalcAppRating(tx, review.AppID)
+	})
+	if err == gorm.ErrRecordNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to update review"}})
+	}
+
+	h.db.Preload("User").First(&review, review.ID)
+	return c.JSON(fiber.Map{"success": true, "review": formatReview(review)})
+}
+
+// DeleteMyReview — DELETE /api/apps/:appId/reviews/me
+func (h *ReviewsHandler) DeleteMyReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var review models.AppReview
+		if err := tx.Where("app_id = ? AND user_id = ?", appID, userID).First(&review).Error; err != nil {
+			return err
+		}
+		return deleteReview(tx, review)
+	})
+	if err == gorm.ErrRecordNotFound {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to delete review"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// MarkHelpful — POST /api/apps/:appId/reviews/:reviewId/helpful
+func (h *ReviewsHandler) MarkHelpful(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	review, err := h.findReview(c)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+	if review.UserID == userID {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "You cannot vote for your own review"}})
+	}
+
+	h.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ReviewHelpfulVote{ReviewID: review.ID, UserID: userID})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&models.AppReview{}).Where("id = ?", review.ID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+
+	h.db.First(&review, review.ID)
+	return c.JSON(fiber.Map{"success": true, "helpfulCount": review.HelpfulCount, "markedHelpful": true})
+}
+
+// UnmarkHelpful — DELETE /api/apps/:appId/reviews/:reviewId/helpful
+func (h *ReviewsHandler) UnmarkHelpful(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	review, err := h.findReview(c)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+
+	h.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("review_id = ? AND user_id = ?", review.ID, userID).Delete(&models.ReviewHelpfulVote{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&models.AppReview{}).Where("id = ?", review.ID).
+			UpdateColumn("helpful_count", gorm.Expr("GREATEST(helpful_count - 1, 0)")).Error
+	})
+
+	h.db.First(&review, review.ID)
+	return c.JSON(fiber.Map{"success": true, "helpfulCount": review.HelpfulCount, "markedHelpful": false})
+}
+
+// ReportReview — POST /api/apps/:appId/reviews/:reviewId/report
+func (h *ReviewsHandler) ReportReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	review, err := h.findReview(c)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+
+	var input struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+	if err := c.BodyParser(&input); err != nil || !models.ReportReasons[input.Reason] {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "reason must be one of spam, scam, offensive, harassment, sexual_content, violence, illegal, off_topic, other"}})
+	}
+
+	target := reportTarget{Type: models.ReportTargetReview, ID: review.ID, AuthorID: review.UserID, AppID: review.AppID, Hidden: review.HiddenAt != nil}
+	if _, err := fileReport(h.db, target, userID, input.Reason, input.Details); errors.Is(err, errOwnContent) {
+		return c.Status(403).JSON(fiber.Map{"error": fiber.Map{"code": "FORBIDDEN", "message": "You cannot report your own review"}})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to submit report"}})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Report submitted"})
+}
+
+// ReplyToReview — PUT /api/developer/apps/:appId/reviews/:reviewId/reply
+func (h *ReviewsHandler) ReplyToReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	if _, err := authorizeApp(h.db, appID, userID, models.OrgRoleDeveloper); err != nil {
+		return appAccessError(c, err)
+	}
+	review, err := h.findReview(c)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+
+	var input struct {
+		Text string `json:"text"`
+	}
+	c.BodyParser(&input)
+	input.Text = strings.TrimSpace(input.Text)
+	if input.Text == "" || len([]rune(input.Text)) > maxReviewLength {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": fmt.Sprintf("text is required, up to %d characters", maxReviewLength)}})
+	}
+
+	now := time.Now()
+	h.db.Model(&review).Updates(map[string]interface{}{"developer_reply": input.Text, "replied_at": now})
+
+	h.db.Preload("User").First(&review, review.ID)
+	return c.JSON(fiber.Map{"success": true, "review": formatReview(review)})
+}
+
+// DeleteReply — DELETE /api/developer/apps/:appId/reviews/:reviewId/reply
+func (h *ReviewsHandler) DeleteReply(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	if _, err := authorizeApp(h.db, appID, userID, models.OrgRoleDeveloper); err != nil {
+		return appAccessError(c, err)
+	}
+	review, err := h.findReview(c)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Review not found"}})
+	}
+
+	h.db.Model(&review).Updates(map[string]interface{}{"developer_reply": "", "replied_at": nil})
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// helpers
+
+// findReview loads the :reviewId review belonging to :appId
+func (h *ReviewsHandler) findReview(c *fiber.Ctx) (models.AppReview, error) {
+	var review models.AppReview
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return review, err
+	}
+	reviewID, err := paramID(c, "reviewId", publicid.Review)
+	if err != nil {
+		return review, err
+	}
+
+	err = h.db.Where("id = ? AND app_id = ?", reviewID, appID).First(&review).Error
+	return review, err
+}
+
+type reviewInput struct {
+	Rating int    `json:"rating"`
+	Text   string `json:"text"`
+}
+
+func parseReviewInput(c *fiber.Ctx) (reviewInput, string) {
+	var input reviewInput
+	if err := c.BodyParser(&input); err != nil {
+		return input, "Invalid request body"
+	}
+	input.Text = strings.TrimSpace(input.Text)
+	if input.Rating < 1 || input.Rating > 5 {
+		return input, "rating must be between 1 and 5"
+	}
+	if len([]rune(input.Text)) > maxReviewLength {
+		return input, fmt.Sprintf("text must be at most %d characters", maxReviewLength)
+	}
+	return input, ""
+}
+
+// deleteReview removes a review with its history and votes and updates the app rating.
+// Runs inside a transaction.
+func deleteReview(tx *gorm.DB, review models.AppReview) error {
+	for _, model := range []interface{}{&models.AppReviewRevision{}, &models.ReviewHelpfulVote{}} {
+		if err := tx.Where("review_id = ?", review.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	// Resolved reports stay as the record of a moderator removing the review
+	if err := tx.Where("target_type = ? AND target_id = ? AND status = ?", models.ReportTargetReview, review.ID, models.ReportOpen).
+		Delete(&models.ContentReport{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&review).Error; err != nil {
+		return err
+	}
+	return recalcAppRating(tx, review.AppID)
+}
+
+// recalcAppRating recomputes MiniApp.Rating and ReviewsCount from the visible reviews.
+// The app row is locked so concurrent review writes apply one after another.
+func recalcAppRating(tx *gorm.DB, appID uint) error {
+	var app models.MiniApp
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&app, appID).Error; err != nil {
+		return err
+	}
+
+	var stats struct {
+		Avg   float64
+		Count int
+	}
+	if err := tx.Model(&models.AppReview{}).
+		Select("COALESCE(ROUND(AVG(rating)::numeric, 1), 0) AS avg, COUNT(*) AS count").
+		Where("app_id = ? AND hidden_at IS NULL", appID).Scan(&stats).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Model(&models.MiniApp{}).Where("id = ?", appID).
+		UpdateColumns(map[string]interface{}{"rating": stats.Avg, "reviews_count": stats.Count}).Error
+}
+
+func formatReview(r models.AppReview) fiber.Map {
+	review := fiber.Map{
+		"id": publicid.Format(publicid.Review, r.ID), "appId": publicid.Format(publicid.App, r.AppID),
+		"rating": r.Rating, "text": r.Text, "helpfulCount": r.HelpfulCount,
+		"isEdited": r.EditedAt != nil, "editedAt": r.EditedAt,
+		"createdAt": r.CreatedAt,
+		"author": fiber.Map{
+			"id": publicid.Format(publicid.User, r.UserID), "displayName": r.User.GetDisplayName(),
+			"avatarUrl": r.User.GetAvatarURL(),
+		},
+	}
+	if r.DeveloperReply != "" {
+		review["developerReply"] = fiber.Map{"text": r.DeveloperReply, "repliedAt": r.RepliedAt}
+	}
+	return review
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSearchTerms = 8
+	// Minimum word_similarity for the trigram fallback to count as a match
+	fuzzyThreshold = 0.4
+
+	// ts_headline marks matches with private-use characters that are stripped from
+	// the input; highlightHits swaps them for <mark> once the text is escaped
+	markStart = "\uE000"
+	markStop  = "\uE001"
+
+	titleHeadlineOpts   = "HighlightAll=true, StartSel=\"" + markStart + "\", StopSel=\"" + markStop + "\""
+	snippetHeadlineOpts = "StartSel=\"" + markStart + "\", StopSel=\"" + markStop + "\", MaxWords=25, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+)
+
+var highlightReplacer = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+
+// appSearchHit — one ranked search result with highlighted fragments
+type appSearchHit struct {
+	ID      uint
+	Rank    float64
+	Title   string // HTML-escaped title with <mark> around matched terms
+	Snippet string // HTML-escaped description fragments with <mark> around matched terms
+	Fuzzy   bool   // matched by the trigram fallback, not full-text
+}
+
+// appSearchQuery turns free text into a prefix tsquery ("photo:* & edit:*") and picks
+// the text search config from the script: russian for Cyrillic input, english otherwise.
+func appSearchQuery(text string) (tsquery, config string) {
+	terms := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+
+	config = "english"
+	for _, r := range text {
+		if unicode.Is(unicode.Cyrillic, r) {
+			config = "russian"
+			break
+		}
+	}
+
+	for i, t := range terms {
+		terms[i] = t + ":*"
+	}
+	return strings.Join(terms, " & "), config
+}
+
+// searchApps runs a full-text search over mini_apps, ordered by relevance blended with
+// popularity. When nothing matches it falls back to trigram similarity on the title so
+// typos still find something. scope applies the caller's filters (moderation, category…).
+func searchApps(db *gorm.DB, text string, scope func(*gorm.DB) *gorm.DB, offset, limit int) ([]appSearchHit, int64, error) {
+	tsq, config := appSearchQuery(text)
+	if tsq == "" {
+		return nil, 0, nil
+	}
+
+	// Each use of query expands to two placeholders, both bound to tsq
+	query := fmt.Sprintf("(to_tsquery('simple', ?) || to_tsquery('%s', ?))", config)
+
+	base := func() *gorm.DB {
+		return scope(db.Model(&models.MiniApp{}))
+	}
+
+	var total int64
+	if err := base().Where("search_vector @@ "+query, tsq, tsq).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []appSearchHit
+	if total > 0 {
+		err := base().
+			Select(fmt.Sprintf(`id,
+				ts_rank_cd(search_vector, %[1]s, 32) * (1 + ln(1 + users_count) * 0.1) AS rank,
+				ts_headline('%[2]s', translate(title, '%[5]s', ''), %[1]s, '%[3]s') AS title,
+				ts_headline('%[2]s', translate(coalesce(description, ''), '%[5]s', ''), %[1]s, '%[4]s') AS snippet`,
+				query, config, titleHeadlineOpts, snippetHeadlineOpts, markStart+markStop),
+				tsq, tsq, tsq, tsq, tsq, tsq).
+			Where("search_vector @@ "+query, tsq, tsq).
+			Order("rank DESC, users_count DESC, id ASC").
+			Offset(offset).Limit(limit).
+			Scan(&hits).Error
+		highlightHits(hits)
+		return hits, total, err
+	}
+
+	// Fuzzy fallback; requires pg_trgm, otherwise there are simply no results
+	text = strings.TrimSpace(text)
+	fuzzy := base().Where("word_similarity(?, title) >= ?", text, fuzzyThreshold)
+	if err := fuzzy.Session(&gorm.Session{}).Count(&total).Error; err != nil || total == 0 {
+		return nil, 0, nil
+	}
+	err := fuzzy.
+		Select("id, word_similarity(?, title) * (1 + ln(1 + users_count) * 0.1) AS rank, title, left(coalesce(description, ''), 200) AS snippet, true AS fuzzy", text).
+		Order("rank DESC, users_count DESC, id ASC").
+		Offset(offset).Limit(limit).
+		Scan(&hits).Error
+	highlightHits(hits)
+	return hits, total, err
+}
+
+// highlightHits HTML-escapes the listing text of hits, which developers control,
+// and turns the match markers into <mark> tags
+func highlightHits(hits []appSearchHit) {
+	for i := range hits {
+		hits[i].Title = highlightReplacer.Replace(html.EscapeString(hits[i].Title))
+		hits[i].Snippet = highlightReplacer.Replace(html.EscapeString(hits[i].Snippet))
+	}
+}
+
+// loadSearchHits loads the apps for hits in rank order. Extra preloads can be set on db.
+func loadSearchHits(db *gorm.DB, hits []appSearchHit) []models.MiniApp {
+	if len(hits) == 0 {
+		return []models.MiniApp{}
+	}
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+
+	var apps []models.MiniApp
+	db.Preload("Category").Where("id IN ?", ids).Find(&apps)
+
+	byID := make(map[uint]models.MiniApp, len(apps))
+	for _, a := range apps {
+		byID[a.ID] = a
+	}
+	ordered := make([]models.MiniApp, 0, len(apps))
+	for _, h := range hits {
+		if a, ok := byID[h.ID]; ok {
+			ordered = append(ordered, a)
+		}
+	}
+	return ordered
+}
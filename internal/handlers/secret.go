@@ -122,6 +122,12 @@ func (h *SecretHandler) ActivateNumber(c *fiber.Ctx) error {
 			"error": "Failed to create transaction",
 		})
 	}
+	if err := recordBalanceChange(tx, c, balanceAudit(userID, mpTransaction.Description), user.ManaPoints, -number.PriceMP); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create transaction",
+		})
+	}
 
 	// Create secret access
 	secretAccess := models.SecretAccess{
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Link unfurlers fetch the landing page to build previews; those fetches are not clicks
+var crawlerPattern = regexp.MustCompile(`(?i)bot|crawl|spider|preview|facebookexternalhit|whatsapp|slack|discord|telegram|skype|vkshare`)
+
+// A link opened again by the same user or IP within this window counts once
+const shareClickWindow = 24 * time.Hour
+
+var errShareTargetGone = errors.New("share target is no longer available")
+
+// ShareHandler creates share links for apps and posts and resolves them
+type ShareHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewShareHandler(db *gorm.DB, cfg *config.Config) *ShareHandler {
+	return &ShareHandler{db: db, cfg: cfg}
+}
+
+// shareTarget is what a share link points at, with its preview data
+type shareTarget struct {
+	Type        string
+	ID          string
+	Title       string
+	Description string
+	Image       string
+	DeepLink    string
+	Target      fiber.Map
+}
+
+// ShareApp — POST /api/apps/:appId/share
+func (h *ShareHandler) ShareApp(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var app models.MiniApp
+	if err := listedApps(h.db).First(&app, appID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "App not found"}})
+	}
+	return h.respondWithLink(c, models.ShareTargetApp, app.ID, app.ID, userID)
+}
+
+// SharePost — POST /api/news/:postId/share
+// Returns the caller's share link; SharesCount grows as the link gets opened.
+func (h *ShareHandler) SharePost(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	postID, err := paramID(c, "postId", publicid.Post)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var post models.NewsPost
+	if err := h.sharedPosts().First(&post, postID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Post not found"}})
+	}
+	return h.respondWithLink(c, models.ShareTargetPost, post.ID, post.AppID, userID)
+}
+
+// ResolveShare — GET /api/share/:code
+// Public; opening a link in the app counts as a click.
+func (h *ShareHandler) ResolveShare(c *fiber.Ctx) error {
+	link, target, err := h.resolve(c.Params("code"))
+	if errors.Is(err, errShareTargetGone) {
+		return c.Status(410).JSON(fiber.Map{"error": fiber.Map{"code": "GONE", "message": "This content is no longer available"}})
+	}
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Link not found"}})
+	}
+
+	var userID *uint
+	if id, ok := c.Locals("userID").(uint); ok {
+		userID = &id
+	}
+	h.recordClick(link, userID, c.IP(), "app", c.Get("User-Agent"))
+
+	return c.JSON(fiber.Map{
+		"success": true, "type": target.Type, "targetId": target.ID,
+		"target": target.Target, "deepLink": target.DeepLink, "url": h.shareURL(link.Code),
+		"preview": fiber.Map{
+			"title": target.Title, "description": target.Description, "image": target.Image,
+		},
+	})
+}
+
+// LandingPage — GET /s/:code
+// HTML page carrying OpenGraph tags for link previews; it forwards browsers to the app.
+func (h *ShareHandler) LandingPage(c *fiber.Ctx) error {
+	link, target, err := h.resolve(c.Params("code"))
+	if err != nil {
+		status := fiber.StatusNotFound
+		if errors.Is(err, errShareTargetGone) {
+			status = fiber.StatusGone
+		}
+		c.Status(status).Type("html")
+		return c.SendString(`<!doctype html><html><head><meta charset="utf-8"><title>Solafon</title></head><body><p>This link is no longer available.</p></body></html>`)
+	}
+
+	h.recordClick(link, nil, c.IP(), "web", c.Get("User-Agent"))
+
+	var page bytes.Buffer
+	if err := landingTemplate.Execute(&page, fiber.Map{
+		"Title": target.Title, "Description": target.Description, "Image": target.Image,
+		"URL": h.shareURL(link.Code), "DeepLink": template.URL(target.DeepLink),
+	}); err != nil {
+		return c.Status(500).SendString("Failed to render page")
+	}
+	c.Type("html")
+	return c.Send(page.Bytes())
+}
+
+// helpers
+
+// respondWithLink returns the user's link for a target, creating it on first share
+func (h *ShareHandler) respondWithLink(c *fiber.Ctx, targetType string, targetID, appID, userID uint) error {
+	var link models.ShareLink
+	for attempt := 0; attempt < 3 && link.ID == 0; attempt++ {
+		h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ShareLink{
+			Code: models.GenerateShareCode(), TargetType: targetType, TargetID: targetID,
+			CreatedBy: userID, AppID: appID,
+		})
+		// On a conflict this is the existing link; a code collision leaves nothing and retries
+		h.db.Where("target_type = ? AND target_id = ? AND created_by = ?", targetType, targetID, userID).
+			Limit(1).Find(&link)
+	}
+	if link.ID == 0 {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to create share link"}})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true, "code": link.Code, "url": h.shareURL(link.Code),
+		"deepLink": shareDeepLink(targetType, targetID), "clicksCount": link.ClicksCount,
+	})
+}
+
+func (h *ShareHandler) resolve(code string) (models.ShareLink, shareTarget, error) {
+	var link models.ShareLink
+	if err := h.db.Where("code = ?", code).First(&link).Error; err != nil {
+		return link, shareTarget{}, err
+	}
+
+	switch link.TargetType {
+	case models.ShareTargetApp:
+		var app models.MiniApp
+		if err := listedApps(h.db.Preload("Category")).First(&app, link.TargetID).Error; err != nil {
+			return link, shareTarget{}, errShareTargetGone
+		}
+		description := app.Subtitle
+		if description == "" {
+			description = app.Description
+		}
+		return link, shareTarget{
+			Type: link.TargetType, ID: publicid.Format(publicid.App, app.ID),
+			Title: app.Title, Description: truncateRunes(description, 200), Image: h.absoluteURL(app.IconURL),
+			DeepLink: shareDeepLink(link.TargetType, app.ID), Target: formatMarketApp(app),
+		}, nil
+
+	case models.ShareTargetPost:
+		var post models.NewsPost
+		if err := h.sharedPosts().Preload("App").First(&post, link.TargetID).Error; err != nil {
+			return link, shareTarget{}, errShareTargetGone
+		}
+		image := post.ImageURL
+		if image == "" {
+			image = post.App.IconURL
+		}
+		return link, shareTarget{
+			Type: link.TargetType, ID: publicid.Format(publicid.Post, post.ID),
+			Title: post.App.Title, Description: truncateRunes(post.Text, 200), Image: h.absoluteURL(image),
+			DeepLink: shareDeepLink(link.TargetType, post.ID),
+			Target: fiber.Map{
+				"id": publicid.Format(publicid.Post, post.ID), "appId": publicid.Format(publicid.App, post.AppID),
+				"appName": post.App.Title, "appIcon": post.App.IconURL,
+				"text": post.Text, "imageUrl": post.ImageURL,
+				"likesCount": post.LikesCount, "commentsCount": post.CommentsCount,
+				"sharesCount": post.SharesCount, "createdAt": post.CreatedAt,
+			},
+		}, nil
+	}
+	return link, shareTarget{}, gorm.ErrRecordNotFound
+}
+
+// sharedPosts scopes to posts that can be shared: visible, from an app still listed
+func (h *ShareHandler) sharedPosts() *gorm.DB {
+	return h.db.Where("hidden_at IS NULL AND app_id IN (?)", listedApps(h.db.Model(&models.MiniApp{})).Select("id"))
+}
+
+// recordClick counts an open of the link; opens of a post link feed its SharesCount.
+// Crawlers and repeat opens within shareClickWindow are skipped.
+func (h *ShareHandler) recordClick(link models.ShareLink, userID *uint, ip, source, userAgent string) {
+	if crawlerPattern.MatchString(userAgent) {
+		return
+	}
+	h.db.Transaction(func(tx *gorm.DB) error {
+		recent := tx.Model(&models.ShareClick{}).Where("link_id = ? AND created_at > ?", link.ID, time.Now().Add(-shareClickWindow))
+		if userID != nil {
+			recent = recent.Where("user_id = ? OR ip = ?", *userID, ip)
+		} else {
+			recent = recent.Where("ip = ?", ip)
+		}
+		var seen int64
+		if err := recent.Count(&seen).Error; err != nil || seen > 0 {
+			return err
+		}
+
+		if err := tx.Create(&models.ShareClick{
+			LinkID: link.ID, AppID: link.AppID, UserID: userID, IP: ip,
+			Source: source, UserAgent: truncateRunes(userAgent, 255),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ShareLink{}).Where("id = ?", link.ID).
+			UpdateColumn("clicks_count", gorm.Expr("clicks_count + 1")).Error; err != nil {
+			return err
+		}
+		if link.TargetType == models.ShareTargetPost {
+			return tx.Model(&models.NewsPost{}).Where("id = ?", link.TargetID).
+				UpdateColumn("shares_count", gorm.Expr("shares_count + 1")).Error
+		}
+		return nil
+	})
+}
+
+func (h *ShareHandler) shareURL(code string) string {
+	return strings.TrimRight(h.cfg.BaseURL, "/") + "/s/" + code
+}
+
+// absoluteURL turns an upload path into a URL crawlers can fetch
+func (h *ShareHandler) absoluteURL(url string) string {
+	if url == "" || strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return url
+	}
+	return strings.TrimRight(h.cfg.BaseURL, "/") + "/" + strings.TrimLeft(url, "/")
+}
+
+func shareDeepLink(targetType string, id uint) string {
+	if targetType == models.ShareTargetPost {
+		return "solafon://post/" + publicid.Format(publicid.Post, id)
+	}
+	return "solafon://app/" + publicid.Format(publicid.App, id)
+}
+
+func truncateRunes(s string, max int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= max {
+		return string(r)
+	}
+	return string(r[:max-1]) + "…"
+}
+
+var landingTemplate = template.Must(template.New("share").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} — Solafon</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="Solafon">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+{{if .Image}}<meta property="og:image" content="{{.Image}}">
+<meta name="twitter:image" content="{{.Image}}">{{end}}
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<style>body{font-family:-apple-system,sans-serif;max-width:480px;margin:48px auto;padding:0 16px;text-align:center}img{width:96px;height:96px;border-radius:20px}a.button{display:inline-block;margin-top:16px;padding:12px 24px;border-radius:12px;background:#7c3aed;color:#fff;text-decoration:none}</style>
+</head>
+<body>
+{{if .Image}}<img src="{{.Image}}" alt="">{{end}}
+<h1>{{.Title}}</h1>
+<p>{{.Description}}</p>
+<a class="button" href="{{.DeepLink}}">Open in Solafon</a>
+<script>window.location.href = "{{.DeepLink}}";</script>
+</body>
+</html>
+`))
@@ -1,9 +1,8 @@
 package handlers
 
 import (
-	"fmt"
-
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -42,7 +41,7 @@ func (h *SupportHandler) GetFAQ(c *fiber.Ctx) error {
 	result := make([]fiber.Map, len(faqs))
 	for i, f := range faqs {
 		result[i] = fiber.Map{
-			"id": fmt.Sprintf("faq_%d", f.ID), "question": f.Question,
+			"id": publicid.Format(publicid.FAQ, f.ID), "question": f.Question,
 			"answer": f.Answer, "category": f.Category,
 		}
 	}
@@ -69,7 +68,7 @@ func (h *SupportHandler) CreateTicket(c *fiber.Ctx) error {
 	h.db.Create(&ticket)
 
 	return c.Status(201).JSON(fiber.Map{
-		"ticketId": fmt.Sprintf("ticket_%d", ticket.ID), "status": "open",
+		"ticketId": publicid.Format(publicid.Ticket, ticket.ID), "status": "open",
 	})
 }
 
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const appTransferTTL = 72 * time.Hour
+
+var (
+	errTransferNotOpen = errors.New("transfer is no longer pending")
+	errTransferStale   = errors.New("sender no longer owns the app")
+)
+
+// TransfersHandler hands apps over between accounts
+type TransfersHandler struct {
+	db *gorm.DB
+}
+
+func NewTransfersHandler(db *gorm.DB) *TransfersHandler {
+	return &TransfersHandler{db: db}
+}
+
+// InitiateTransfer — POST /api/developer/apps/:appId/transfer (owner)
+func (h *TransfersHandler) InitiateTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleOwner)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	var input struct {
+		Email string `json:"email"`
+	}
+	c.BodyParser(&input)
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if email == "" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "email is required"}})
+	}
+
+	var recipient models.User
+	h.db.Where("LOWER(email) = ?", email).Limit(1).Find(&recipient)
+	if recipient.ID == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "USER_NOT_FOUND", "message": "No account uses this email"}})
+	}
+	if recipient.ID == userID {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "You can't transfer an app to yourself"}})
+	}
+
+	transfer := models.AppTransfer{
+		AppID: app.ID, FromUserID: userID, FromOrganizationID: app.OrganizationID,
+		ToUserID: recipient.ID, ToEmail: email,
+		Status: models.TransferPending, ExpiresAt: time.Now().Add(appTransferTTL),
+	}
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		// Serialize transfers per app
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&app, app.ID).Error; err != nil {
+			return err
+		}
+		var open int64
+		tx.Model(&models.AppTransfer{}).
+			Where("app_id = ? AND status = ? AND expires_at > ?", app.ID, models.TransferPending, time.Now()).
+			Count(&open)
+		if open > 0 {
+			return errTransferNotOpen
+		}
+		if err := tx.Create(&transfer).Error; err != nil {
+			return err
+		}
+		return recordTransferChange(tx, c, transfer, "", models.TransferPending, &userID)
+	})
+	if errors.Is(err, errTransferNotOpen) {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "TRANSFER_PENDING", "message": "This app already has a pending transfer"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to start transfer"}})
+	}
+
+	var sender models.User
+	h.db.First(&sender, userID)
+	h.db.Create(&models.Notification{
+		UserID: recipient.ID, Type: "system",
+		Title: "App transfer",
+		Body: fmt.Sprintf("%s wants to transfer %s to you. Accept before %s.",
+			sender.GetDisplayName(), app.Title, transfer.ExpiresAt.Format("2006-01-02 15:04 MST")),
+		ActionURL: "solafon://developer/transfers",
+	})
+
+	transfer.App, transfer.FromUser, transfer.ToUser = app, sender, recipient
+	return c.Status(201).JSON(fiber.Map{"success": true, "transfer": formatTransfer(transfer)})
+}
+
+// GetAppTransfer — GET /api/developer/apps/:appId/transfer
+// The app's pending transfer, if any.
+func (h *TransfersHandler) GetAppTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	if _, err := authorizeApp(h.db, appID, userID, models.OrgRoleOwner); err != nil {
+		return appAccessError(c, err)
+	}
+
+	var transfer models.AppTransfer
+	h.db.Preload("App").Preload("FromUser").Preload("ToUser").
+		Where("app_id = ? AND status = ? AND expires_at > ?", appID, models.TransferPending, time.Now()).
+		Limit(1).Find(&transfer)
+
+	var result fiber.Map
+	if transfer.ID != 0 {
+		result = formatTransfer(transfer)
+	}
+	return c.JSON(fiber.Map{"success": true, "transfer": result})
+}
+
+// CancelTransfer — DELETE /api/developer/apps/:appId/transfer (owner)
+func (h *TransfersHandler) CancelTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	appID, err := paramID(c, "appId", publicid.App)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	app, err := authorizeApp(h.db, appID, userID, models.OrgRoleOwner)
+	if err != nil {
+		return appAccessError(c, err)
+	}
+
+	var transfer models.AppTransfer
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("app_id = ? AND status = ?", app.ID, models.TransferPending).First(&transfer).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&transfer).Update("status", models.TransferCanceled).Error; err != nil {
+			return err
+		}
+		return recordTransferChange(tx, c, transfer, models.TransferPending, models.TransferCanceled, &userID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "No pending transfer"}})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to cancel transfer"}})
+	}
+
+	h.db.Create(&models.Notification{
+		UserID: transfer.ToUserID, Type: "system",
+		Title: "App transfer canceled",
+		Body:  fmt.Sprintf("The transfer of %s was canceled by the sender.", app.Title),
+	})
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// ListTransfers — GET /api/developer/transfers
+// Pending transfers addressed to the caller and the transfers they started.
+func (h *TransfersHandler) ListTransfers(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var incoming, outgoing []models.AppTransfer
+	h.db.Preload("App").Preload("FromUser").Preload("ToUser").
+		Where("to_user_id = ? AND status = ? AND expires_at > ?", userID, models.TransferPending, time.Now()).
+		Order("created_at DESC").Find(&incoming)
+	h.db.Preload("App").Preload("FromUser").Preload("ToUser").
+		Where("from_user_id = ?", userID).Order("created_at DESC").Limit(50).Find(&outgoing)
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"incoming": formatTransfers(incoming),
+		"outgoing": formatTransfers(outgoing),
+	})
+}
+
+// AcceptTransfer — POST /api/developer/transfers/:transferId/accept
+// The app becomes the recipient's personal app. Its API token and webhook secret
+// are rotated and its webhook URL is cleared, so the previous owner's integrations
+// stop working and the new owner sets up their own webhook.
+func (h *TransfersHandler) AcceptTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	transferID, err := paramID(c, "transferId", publicid.Transfer)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var transfer models.AppTransfer
+	var app models.MiniApp
+	apiKey := models.GenerateAPIToken()
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := h.lockIncoming(tx, transferID, userID, &transfer); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&app, transfer.AppID).Error; err != nil {
+			return errTransferStale
+		}
+		// Ownership may have changed hands since the transfer was started
+		if !hasAppRole(tx, app, transfer.FromUserID, models.OrgRoleOwner) ||
+			!sameOrganization(app.OrganizationID, transfer.FromOrganizationID) {
+			return errTransferStale
+		}
+
+		before, beforeWebhook := credentialAudit(app), webhookAudit(app)
+		app.CreatorID, app.OrganizationID = userID, nil
+		app.APIToken, app.WebhookSecret = apiKey, generateWebhookSecret()
+		app.WebhookURL, app.WebhookFailures, app.WebhookDisabledAt = "", 0, nil
+		app.IsVerified = developerVerified(tx, userID)
+		if err := tx.Model(&app).Updates(map[string]interface{}{
+			"creator_id": app.CreatorID, "organization_id": nil,
+			"api_token": app.APIToken, "webhook_secret": app.WebhookSecret,
+			"api_token_expires_at": nil, "api_token_expiry_notified_at": nil,
+			"webhook_url": "", "webhook_failures": 0, "webhook_disabled_at": nil,
+			"is_verified": app.IsVerified,
+		}).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, c, models.AuditLog{
+			Action: models.AuditAPIKeyGenerated, TargetType: models.AuditTargetApp, TargetID: app.ID,
+			Note: "Rotated on ownership transfer " + publicid.Format(publicid.Transfer, transfer.ID),
+		}, before, credentialAudit(app)); err != nil {
+			return err
+		}
+		if beforeWebhook["webhookUrl"] != "" {
+			if err := recordAudit(tx, c, models.AuditLog{
+				Action: models.AuditWebhookDeleted, TargetType: models.AuditTargetApp, TargetID: app.ID,
+				Note: "Cleared on ownership transfer " + publicid.Format(publicid.Transfer, transfer.ID),
+			}, beforeWebhook, webhookAudit(app)); err != nil {
+				return err
+			}
+		}
+		return h.respond(tx, c, &transfer, models.TransferAccepted, userID)
+	})
+	if err != nil {
+		return transferError(c, err)
+	}
+
+	h.db.Create(&models.Notification{
+		UserID: transfer.FromUserID, Type: "system",
+		Title: "App transferred",
+		Body:  fmt.Sprintf("%s now belongs to %s. Its API credentials were rotated.", app.Title, transfer.ToEmail),
+	})
+	h.db.Create(&models.Notification{
+		UserID: userID, Type: "system",
+		Title:     "App received",
+		Body:      fmt.Sprintf("%s is now yours. New API credentials were issued; set up its webhook to receive messages.", app.Title),
+		ActionURL: "solafon://developer/apps/" + publicid.Format(publicid.App, app.ID),
+	})
+
+	h.db.Preload("Category").First(&app, app.ID)
+	return c.JSON(fiber.Map{
+		"success": true, "app": formatDevApp(app),
+		"apiKey": apiKey, "keyId": publicid.Format(publicid.APIKey, app.ID),
+		"webhookSecret": app.WebhookSecret,
+	})
+}
+
+// DeclineTransfer — POST /api/developer/transfers/:transferId/decline
+func (h *TransfersHandler) DeclineTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	transferID, err := paramID(c, "transferId", publicid.Transfer)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+
+	var transfer models.AppTransfer
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := h.lockIncoming(tx, transferID, userID, &transfer); err != nil {
+			return err
+		}
+		return h.respond(tx, c, &transfer, models.TransferDeclined, userID)
+	})
+	if err != nil {
+		return transferError(c, err)
+	}
+
+	var app models.MiniApp
+	h.db.First(&app, transfer.AppID)
+	h.db.Create(&models.Notification{
+		UserID: transfer.FromUserID, Type: "system",
+		Title: "App transfer declined",
+		Body:  fmt.Sprintf("%s declined the transfer of %s.", transfer.ToEmail, app.Title),
+	})
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// helpers
+
+// lockIncoming locks a pending transfer addressed to userID
+func (h *TransfersHandler) lockIncoming(tx *gorm.DB, transferID, userID uint, transfer *models.AppTransfer) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND to_user_id = ?", transferID, userID).First(transfer).Error; err != nil {
+		return err
+	}
+	if transfer.Status != models.TransferPending || !time.Now().Before(transfer.ExpiresAt) {
+		return errTransferNotOpen
+	}
+	return nil
+}
+
+func (h *TransfersHandler) respond(tx *gorm.DB, c *fiber.Ctx, transfer *models.AppTransfer, status string, userID uint) error {
+	now := time.Now()
+	if err := tx.Model(transfer).Updates(map[string]interface{}{"status": status, "responded_at": now}).Error; err != nil {
+		return err
+	}
+	return recordTransferChange(tx, c, *transfer, models.TransferPending, status, &userID)
+}
+
+// recordTransferChange appends to the transfer's audit trail
+func recordTransferChange(tx *gorm.DB, c *fiber.Ctx, transfer models.AppTransfer, from, to string, actor *uint) error {
+	return tx.Create(&models.AppTransferStatusChange{
+		TransferID: transfer.ID, FromStatus: from, ToStatus: to, ChangedBy: actor,
+		IP: c.IP(), UserAgent: truncateRunes(c.Get("User-Agent"), 255),
+	}).Error
+}
+
+func sameOrganization(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func transferError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Transfer not found"}})
+	case errors.Is(err, errTransferNotOpen):
+		return c.Status(410).JSON(fiber.Map{"error": fiber.Map{"code": "TRANSFER_EXPIRED", "message": "This transfer has expired or was withdrawn"}})
+	case errors.Is(err, errTransferStale):
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "TRANSFER_STALE", "message": "The sender no longer owns this app"}})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to complete transfer"}})
+}
+
+func formatTransfer(t models.AppTransfer) fiber.Map {
+	status := t.Status
+	if status == models.TransferPending && !time.Now().Before(t.ExpiresAt) {
+		status = models.TransferExpired
+	}
+	return fiber.Map{
+		"id":    publicid.Format(publicid.Transfer, t.ID),
+		"appId": publicid.Format(publicid.App, t.AppID), "appName": t.App.Title, "appIcon": t.App.IconURL,
+		"from": fiber.Map{
+			"id": publicid.Format(publicid.User, t.FromUserID), "displayName": t.FromUser.GetDisplayName(),
+		},
+		"to": fiber.Map{
+			"id": publicid.Format(publicid.User, t.ToUserID), "email": t.ToEmail,
+			"displayName": t.ToUser.GetDisplayName(),
+		},
+		"status": status, "expiresAt": t.ExpiresAt,
+		"respondedAt": t.RespondedAt, "createdAt": t.CreatedAt,
+	}
+}
+
+func formatTransfers(transfers []models.AppTransfer) []fiber.Map {
+	result := make([]fiber.Map, len(transfers))
+	for i, t := range transfers {
+		result[i] = formatTransfer(t)
+	}
+	return result
+}
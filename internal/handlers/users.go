@@ -1,9 +1,8 @@
 package handlers
 
 import (
-	"fmt"
-
 	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -31,10 +30,11 @@ func (h *UsersHandler) GetMe(c *fiber.Ctx) error {
 	h.db.Model(&models.MiniApp{}).Where("creator_id = ?", userID).Count(&appsCreated)
 
 	return c.JSON(fiber.Map{
-		"id":          fmt.Sprintf("user_%d", user.ID),
+		"id":          publicid.Format(publicid.User, user.ID),
 		"email":       user.Email,
 		"displayName": user.GetDisplayName(),
 		"avatarUrl":   user.GetAvatarURL(),
+		"walletAddress": user.WalletAddress,
 		"createdAt":   user.CreatedAt,
 		"stats": fiber.Map{
 			"appsUsed":    appsUsed,
@@ -63,8 +63,9 @@ func (h *UsersHandler) UpdateMe(c *fiber.Ctx) error {
 	}
 
 	var input struct {
-		DisplayName *string `json:"displayName"`
-		AvatarURL   *string `json:"avatarUrl"`
+		DisplayName   *string `json:"displayName"`
+		AvatarURL     *string `json:"avatarUrl"`
+		WalletAddress *string `json:"walletAddress"`
 	}
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
@@ -78,13 +79,17 @@ func (h *UsersHandler) UpdateMe(c *fiber.Ctx) error {
 		user.AvatarURL = *input.AvatarURL
 		user.Avatar = *input.AvatarURL
 	}
+	if input.WalletAddress != nil {
+		user.WalletAddress = *input.WalletAddress
+	}
 	h.db.Save(&user)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"user": fiber.Map{
-			"id": fmt.Sprintf("user_%d", user.ID), "email": user.Email,
+			"id": publicid.Format(publicid.User, user.ID), "email": user.Email,
 			"displayName": user.GetDisplayName(), "avatarUrl": user.GetAvatarURL(),
+			"walletAddress": user.WalletAddress,
 		},
 	})
 }
@@ -147,9 +152,27 @@ func (h *UsersHandler) UpdateSettings(c *fiber.Ctx) error {
 // DeleteMe — DELETE /api/users/me
 func (h *UsersHandler) DeleteMe(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	h.db.Where("user_id = ?", userID).Delete(&models.RefreshToken{})
-	h.db.Where("user_id = ?", userID).Delete(&models.Session{})
-	h.db.Delete(&models.User{}, userID)
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "User not found"}})
+	}
+
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditLog{
+			Action: models.AuditAccountDeleted, TargetType: models.AuditTargetUser, TargetID: userID,
+		}, map[string]interface{}{"email": user.Email, "manaPoints": user.ManaPoints}, nil)
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to delete account"}})
+	}
 	return c.JSON(fiber.Map{"success": true})
 }
 
@@ -162,7 +185,7 @@ func (h *UsersHandler) GetSessions(c *fiber.Ctx) error {
 	result := make([]fiber.Map, len(sessions))
 	for i, s := range sessions {
 		result[i] = fiber.Map{
-			"id": fmt.Sprintf("sess_%d", s.ID), "deviceType": s.DeviceType,
+			"id": publicid.Format(publicid.Session, s.ID), "deviceType": s.DeviceType,
 			"deviceName": s.DeviceName, "ipAddress": s.IPAddress,
 			"location": s.Location, "lastActive": s.LastActive, "isCurrent": false,
 		}
@@ -173,15 +196,35 @@ func (h *UsersHandler) GetSessions(c *fiber.Ctx) error {
 // RevokeSession — DELETE /api/users/me/sessions/:sessionId
 func (h *UsersHandler) RevokeSession(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	sessionID := c.Params("sessionId")
-	h.db.Where("user_id = ? AND id = ?", userID, sessionID).Delete(&models.Session{})
+	sessionID, err := paramID(c, "sessionId", publicid.Session)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var session models.Session
+	if err := h.db.Where("user_id = ? AND id = ?", userID, sessionID).First(&session).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Session not found"}})
+	}
+
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&session).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditLog{
+			Action: models.AuditSessionRevoked, TargetType: models.AuditTargetSession, TargetID: session.ID,
+		}, map[string]interface{}{"deviceType": session.DeviceType, "deviceName": session.DeviceName, "ipAddress": session.IPAddress}, nil)
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to revoke session"}})
+	}
 	return c.JSON(fiber.Map{"success": true})
 }
 
 // RevokeAllSessions — DELETE /api/users/me/sessions
 func (h *UsersHandler) RevokeAllSessions(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	h.db.Where("user_id = ?", userID).Delete(&models.Session{})
-	h.db.Where("user_id = ?", userID).Delete(&models.RefreshToken{})
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		return revokeAllSessions(tx, c, userID, "")
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to revoke sessions"}})
+	}
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/domainproof"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Requests a developer can still act on
+var openVerificationStatuses = []string{models.VerificationAwaitingProof, models.VerificationPendingReview}
+
+var errVerificationStatus = errors.New("verification request is in another status")
+
+// VerificationHandler runs the developer verification program
+type VerificationHandler struct {
+	db       *gorm.DB
+	resolver domainproof.Resolver
+}
+
+func NewVerificationHandler(db *gorm.DB, resolver domainproof.Resolver) *VerificationHandler {
+	return &VerificationHandler{db: db, resolver: resolver}
+}
+
+// GetMyVerification — GET /api/developer/verification
+func (h *VerificationHandler) GetMyVerification(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var user models.User
+	h.db.First(&user, userID)
+	var latest models.DeveloperVerification
+	h.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(1).Find(&latest)
+
+	var request fiber.Map
+	if latest.ID != 0 {
+		request = formatVerification(latest)
+	}
+	return c.JSON(fiber.Map{
+		"success": true, "isVerified": user.IsVerifiedDeveloper,
+		"orgName": user.VerifiedOrgName, "request": request,
+	})
+}
+
+// RequestVerification — POST /api/developer/verification
+func (h *VerificationHandler) RequestVerification(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input struct {
+		OrgName string `json:"orgName"`
+		Website string `json:"website"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "Invalid request body"}})
+	}
+	orgName := strings.TrimSpace(input.OrgName)
+	if orgName == "" || len([]rune(orgName)) > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "orgName must be 1-100 characters"}})
+	}
+	website, domain, err := parseVerificationWebsite(input.Website)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": err.Error()}})
+	}
+
+	var user models.User
+	h.db.First(&user, userID)
+	if user.IsVerifiedDeveloper {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "ALREADY_VERIFIED", "message": "You are already a verified developer"}})
+	}
+	var open int64
+	h.db.Model(&models.DeveloperVerification{}).Where("user_id = ? AND status IN ?", userID, openVerificationStatuses).Count(&open)
+	if open > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "REQUEST_OPEN", "message": "You already have an open verification request"}})
+	}
+
+	request := models.DeveloperVerification{
+		UserID: userID, OrgName: orgName, Website: website, Domain: domain,
+		Token: models.GenerateVerificationToken(), Status: models.VerificationAwaitingProof,
+	}
+	if err := h.db.Create(&request).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to create verification request"}})
+	}
+	return c.Status(201).JSON(fiber.Map{"success": true, "request": formatVerification(request)})
+}
+
+// CheckDomain — POST /api/developer/verification/check
+// Looks for the token on the domain; once found the request goes to admin review.
+func (h *VerificationHandler) CheckDomain(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var input struct {
+		Method string `json:"method"` // dns_txt, well_known
+	}
+	c.BodyParser(&input)
+	if input.Method != models.ProofDNSTXT && input.Method != models.ProofWellKnown {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "method must be dns_txt or well_known"}})
+	}
+
+	var request models.DeveloperVerification
+	if err := h.db.Where("user_id = ? AND status = ?", userID, models.VerificationAwaitingProof).
+		First(&request).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "No request is waiting for domain proof"}})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 15*time.Second)
+	defer cancel()
+	var err error
+	if input.Method == models.ProofDNSTXT {
+		err = domainproof.CheckTXT(ctx, h.resolver, request.Domain, request.Token)
+	} else {
+		err = domainproof.CheckWellKnown(ctx, h.resolver, request.Domain, request.Token)
+	}
+
+	if err != nil {
+		message := "Verification token not found on " + request.Domain
+		if !errors.Is(err, domainproof.ErrNotFound) {
+			message = "Could not check " + request.Domain + "; make sure it is reachable and try again"
+		}
+		h.db.Model(&request).Update("last_check_error", message)
+		return c.Status(422).JSON(fiber.Map{"error": fiber.Map{"code": "DOMAIN_NOT_VERIFIED", "message": message}})
+	}
+
+	now := time.Now()
+	h.db.Model(&request).Updates(map[string]interface{}{
+		"proof_method": input.Method, "domain_verified_at": now,
+		"last_check_error": "", "status": models.VerificationPendingReview,
+	})
+	h.db.First(&request, request.ID)
+	return c.JSON(fiber.Map{"success": true, "request": formatVerification(request)})
+}
+
+// CancelVerification — DELETE /api/developer/verification
+func (h *VerificationHandler) CancelVerification(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	res := h.db.Model(&models.DeveloperVerification{}).
+		Where("user_id = ? AND status IN ?", userID, openVerificationStatuses).
+		Update("status", models.VerificationCanceled)
+	if res.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "No open verification request"}})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// AdminListVerifications — GET /api/admin/verifications?status=pending_review
+func (h *VerificationHandler) AdminListVerifications(c *fiber.Ctx) error {
+	status := c.Query("status", models.VerificationPendingReview)
+
+	var requests []models.DeveloperVerification
+	query := h.db.Preload("User").Order("created_at ASC").Limit(100)
+	if status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	query.Find(&requests)
+
+	result := make([]fiber.Map, len(requests))
+	for i, r := range requests {
+		result[i] = formatVerification(r)
+		result[i]["developer"] = fiber.Map{
+			"id": publicid.Format(publicid.User, r.UserID), "email": r.User.Email,
+			"name": r.User.GetDisplayName(),
+		}
+	}
+	return c.JSON(fiber.Map{"success": true, "requests": result})
+}
+
+// AdminApproveVerification — POST /api/admin/verifications/:verificationId/approve
+func (h *VerificationHandler) AdminApproveVerification(c *fiber.Ctx) error {
+	return h.review(c, models.VerificationPendingReview, models.VerificationApproved)
+}
+
+// AdminRejectVerification — POST /api/admin/verifications/:verificationId/reject
+func (h *VerificationHandler) AdminRejectVerification(c *fiber.Ctx) error {
+	return h.review(c, models.VerificationPendingReview, models.VerificationRejected)
+}
+
+// AdminRevokeVerification — POST /api/admin/verifications/:verificationId/revoke
+func (h *VerificationHandler) AdminRevokeVerification(c *fiber.Ctx) error {
+	return h.review(c, models.VerificationApproved, models.VerificationRevoked)
+}
+
+// helpers
+
+// review moves a request from one status to another and updates the developer's
+// badge on approval or revocation
+func (h *VerificationHandler) review(c *fiber.Ctx, from, to string) error {
+	adminID := c.Locals("userID").(uint)
+	verificationID, err := paramID(c, "verificationId", publicid.Verification)
+	if err != nil {
+		return invalidIDError(c, err)
+	}
+	var input struct {
+		Note string `json:"note"`
+	}
+	c.BodyParser(&input)
+	if to != models.VerificationApproved && strings.TrimSpace(input.Note) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": fiber.Map{"code": "VALIDATION_ERROR", "message": "note is required"}})
+	}
+
+	var request models.DeveloperVerification
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, verificationID).Error; err != nil {
+			return err
+		}
+		if request.Status != from {
+			return errVerificationStatus
+		}
+		now := time.Now()
+		if err := tx.Model(&request).Updates(map[string]interface{}{
+			"status": to, "review_note": strings.TrimSpace(input.Note),
+			"reviewed_by": adminID, "reviewed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		switch to {
+		case models.VerificationApproved:
+			return setDeveloperVerified(tx, request.UserID, true, request.OrgName)
+		case models.VerificationRevoked:
+			return setDeveloperVerified(tx, request.UserID, false, "")
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": fiber.Map{"code": "NOT_FOUND", "message": "Verification request not found"}})
+	case errors.Is(err, errVerificationStatus):
+		return c.Status(409).JSON(fiber.Map{"error": fiber.Map{"code": "INVALID_STATUS", "message": "Request is not " + from}})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": fiber.Map{"code": "INTERNAL_ERROR", "message": "Failed to review request"}})
+	}
+
+	h.db.First(&request, request.ID)
+	return c.JSON(fiber.Map{"success": true, "request": formatVerification(request)})
+}
+
+// setDeveloperVerified sets the developer badge and propagates it to all of their apps
+func setDeveloperVerified(tx *gorm.DB, userID uint, verified bool, orgName string) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"is_verified_developer": verified, "verified_org_name": orgName,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.MiniApp{}).Unscoped().Where("creator_id = ?", userID).
+		UpdateColumn("is_verified", verified).Error
+}
+
+// developerVerified tells whether apps created by userID start out verified
+func developerVerified(db *gorm.DB, userID uint) bool {
+	var verified bool
+	db.Model(&models.User{}).Select("is_verified_developer").Where("id = ?", userID).Scan(&verified)
+	return verified
+}
+
+// parseVerificationWebsite normalizes the website URL and extracts the domain to prove
+func parseVerificationWebsite(raw string) (website, domain string, err error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return "", "", errors.New("website must be a valid http(s) URL")
+	}
+	host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") || host == "localhost" {
+		return "", "", errors.New("website must use a public domain name")
+	}
+	return u.Scheme + "://" + u.Host + u.EscapedPath(), host, nil
+}
+
+func formatVerification(r models.DeveloperVerification) fiber.Map {
+	result := fiber.Map{
+		"id": publicid.Format(publicid.Verification, r.ID), "status": r.Status,
+		"orgName": r.OrgName, "website": r.Website, "domain": r.Domain,
+		"proofMethod": r.ProofMethod, "domainVerifiedAt": r.DomainVerifiedAt,
+		"lastCheckError": r.LastCheckError, "reviewNote": r.ReviewNote,
+		"reviewedAt": r.ReviewedAt, "createdAt": r.CreatedAt,
+	}
+	if r.Status == models.VerificationAwaitingProof {
+		// Either of these proves control of the domain
+		result["instructions"] = fiber.Map{
+			models.ProofDNSTXT: fiber.Map{
+				"type": "TXT", "host": r.Domain, "value": domainproof.TXTPrefix + r.Token,
+			},
+			models.ProofWellKnown: fiber.Map{
+				"url": "https://" + r.Domain + domainproof.WellKnownPath, "content": r.Token,
+			},
+		}
+	}
+	return result
+}
@@ -202,6 +202,7 @@ func (h *WalletHandler) PurchaseMana(c *fiber.Ctx) error {
 	h.db.First(&user, userID)
 	user.ManaPoints += tariff.MPAmount
 	h.db.Save(&user)
+	recordBalanceChange(h.db, c, balanceAudit(userID, fmt.Sprintf("Purchased %d MP", tariff.MPAmount)), user.ManaPoints, tariff.MPAmount)
 
 	h.db.Create(&models.ManaTransaction{
 		UserID: userID, Amount: tariff.MPAmount, Type: "purchase",
@@ -233,6 +234,7 @@ func (h *WalletHandler) GiftMana(c *fiber.Ctx) error {
 
 	user.ManaPoints -= input.Amount
 	h.db.Save(&user)
+	recordBalanceChange(h.db, c, balanceAudit(userID, fmt.Sprintf("Gift sent: %d MP", input.Amount)), user.ManaPoints, -input.Amount)
 
 	h.db.Create(&models.ManaTransaction{
 		UserID: userID, Amount: -input.Amount, Type: "gift_sent",
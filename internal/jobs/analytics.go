@@ -0,0 +1,272 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Days re-aggregated on every run; late messages may land in yesterday's bucket
+	rollupDays = 2
+	// Days aggregated on the first run, when the stats table is empty
+	backfillDays = 90
+	// Weekly cohorts kept up to date
+	retentionWeeks = 12
+)
+
+// activitySQL selects (app_id, user_id, created_at) for every user action in [@from, @to):
+// launches and messages sent by the user in both chat systems.
+const activitySQL = `
+	SELECT app_id, user_id, created_at FROM app_launches
+	WHERE created_at >= @from AND created_at < @to
+	UNION ALL
+	SELECT c.app_id, c.user_id, m.created_at FROM chat_messages m
+	JOIN conversations c ON c.id = m.conversation_id
+	WHERE m.sender_type = 'user' AND m.created_at >= @from AND m.created_at < @to
+	UNION ALL
+	SELECT app_id, user_id, created_at FROM app_messages
+	WHERE is_from_bot = false AND created_at >= @from AND created_at < @to`
+
+type appCount struct {
+	AppID uint
+	N     int
+}
+
+// RollupAnalytics recomputes daily stats, command usage and retention cohorts
+func RollupAnalytics(db *gorm.DB, now time.Time) error {
+	today := truncateDay(now)
+
+	days := rollupDays
+	var existing int64
+	db.Model(&models.AppDailyStats{}).Count(&existing)
+	if existing == 0 {
+		days = backfillDays
+	}
+
+	for i := days - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i)
+		if err := rollupDay(db, day); err != nil {
+			return err
+		}
+		if err := rollupCommands(db, day); err != nil {
+			return err
+		}
+	}
+
+	return rollupRetention(db, today)
+}
+
+func rollupDay(db *gorm.DB, day time.Time) error {
+	dayRange := map[string]interface{}{"from": day, "to": day.AddDate(0, 0, 1)}
+	monthRange := map[string]interface{}{"from": day.AddDate(0, 0, -29), "to": day.AddDate(0, 0, 1)}
+
+	stats := map[uint]*models.AppDailyStats{}
+	row := func(appID uint) *models.AppDailyStats {
+		if stats[appID] == nil {
+			stats[appID] = &models.AppDailyStats{AppID: appID, Date: day}
+		}
+		return stats[appID]
+	}
+
+	var counts []appCount
+	if err := db.Raw(`SELECT app_id, COUNT(DISTINCT user_id) AS n FROM (`+activitySQL+`) a GROUP BY app_id`, dayRange).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	for _, c := range counts {
+		row(c.AppID).ActiveUsers = c.N
+	}
+
+	counts = nil
+	if err := db.Raw(`SELECT app_id, COUNT(DISTINCT user_id) AS n FROM (`+activitySQL+`) a GROUP BY app_id`, monthRange).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	for _, c := range counts {
+		row(c.AppID).MonthlyActiveUsers = c.N
+	}
+
+	counts = nil
+	if err := db.Raw(`SELECT app_id, COUNT(*) AS n FROM app_users
+		WHERE created_at >= @from AND created_at < @to GROUP BY app_id`, dayRange).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	for _, c := range counts {
+		row(c.AppID).NewUsers = c.N
+	}
+
+	// Returning — active today, first used the app before today
+	counts = nil
+	if err := db.Raw(`SELECT a.app_id, COUNT(DISTINCT a.user_id) AS n FROM (`+activitySQL+`) a
+		JOIN app_users au ON au.app_id = a.app_id AND au.user_id = a.user_id
+		WHERE au.created_at < @from GROUP BY a.app_id`, dayRange).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	for _, c := range counts {
+		row(c.AppID).ReturningUsers = c.N
+	}
+
+	counts = nil
+	if err := db.Raw(`SELECT app_id, COUNT(*) AS n FROM app_launches
+		WHERE created_at >= @from AND created_at < @to GROUP BY app_id`, dayRange).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	for _, c := range counts {
+		row(c.AppID).Launches = c.N
+	}
+
+	var messages []struct {
+		AppID  uint
+		MsgIn  int
+		MsgOut int
+	}
+	if err := db.Raw(`SELECT app_id, SUM(msg_in) AS msg_in, SUM(msg_out) AS msg_out FROM (
+			SELECT app_id,
+				COUNT(*) FILTER (WHERE sender_type = 'user') AS msg_in,
+				COUNT(*) FILTER (WHERE sender_type = 'bot') AS msg_out
+			FROM chat_messages WHERE created_at >= @from AND created_at < @to GROUP BY app_id
+			UNION ALL
+			SELECT app_id,
+				COUNT(*) FILTER (WHERE is_from_bot = false) AS msg_in,
+				COUNT(*) FILTER (WHERE is_from_bot = true) AS msg_out
+			FROM app_messages WHERE created_at >= @from AND created_at < @to GROUP BY app_id
+		) m GROUP BY app_id`, dayRange).
+		Scan(&messages).Error; err != nil {
+		return err
+	}
+	for _, m := range messages {
+		row(m.AppID).MessagesIn = m.MsgIn
+		row(m.AppID).MessagesOut = m.MsgOut
+	}
+
+	counts = nil
+	if err := db.Raw(`SELECT app_id, COUNT(*) AS n FROM share_clicks
+		WHERE created_at >= @from AND created_at < @to GROUP BY app_id`, dayRange).
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	for _, c := range counts {
+		row(c.AppID).ShareClicks = c.N
+	}
+
+	if len(stats) == 0 {
+		return nil
+	}
+	rows := make([]models.AppDailyStats, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, *s)
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "app_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"active_users", "monthly_active_users", "new_users", "returning_users",
+			"launches", "messages_in", "messages_out", "share_clicks", "updated_at",
+		}),
+	}).Create(&rows).Error
+}
+
+// rollupCommands counts user messages starting with "/" per command, ignoring arguments
+// and the @botname suffix.
+func rollupCommands(db *gorm.DB, day time.Time) error {
+	var rows []models.AppCommandStats
+	if err := db.Raw(`SELECT app_id, split_part(cmd, '@', 1) AS command, COUNT(*) AS count FROM (
+			SELECT app_id, split_part(content->>'text', ' ', 1) AS cmd FROM chat_messages
+			WHERE sender_type = 'user' AND content->>'text' LIKE '/%'
+				AND created_at >= @from AND created_at < @to
+			UNION ALL
+			SELECT app_id, split_part(content, ' ', 1) AS cmd FROM app_messages
+			WHERE is_from_bot = false AND content LIKE '/%'
+				AND created_at >= @from AND created_at < @to
+		) c GROUP BY app_id, command`,
+		map[string]interface{}{"from": day, "to": day.AddDate(0, 0, 1)}).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	for i := range rows {
+		rows[i].Date = day
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "app_id"}, {Name: "date"}, {Name: "command"}},
+		DoUpdates: clause.AssignmentColumns([]string{"count", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+// rollupRetention groups users by the week they first used an app and counts how many
+// of them were active in each following week.
+func rollupRetention(db *gorm.DB, today time.Time) error {
+	from := truncateWeek(today).AddDate(0, 0, -7*(retentionWeeks-1))
+	params := map[string]interface{}{"from": from, "to": today.AddDate(0, 0, 1)}
+
+	var sizes []struct {
+		AppID      uint
+		CohortWeek time.Time
+		N          int
+	}
+	if err := db.Raw(`SELECT app_id, date_trunc('week', created_at)::date AS cohort_week, COUNT(*) AS n
+		FROM app_users WHERE created_at >= @from AND created_at < @to
+		GROUP BY app_id, cohort_week`, params).
+		Scan(&sizes).Error; err != nil {
+		return err
+	}
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	var rows []models.AppRetentionCohort
+	if err := db.Raw(`WITH activity AS (`+activitySQL+`),
+		cohorts AS (
+			SELECT app_id, user_id, date_trunc('week', created_at)::date AS cohort_week
+			FROM app_users WHERE created_at >= @from AND created_at < @to
+		)
+		SELECT co.app_id, co.cohort_week,
+			(date_trunc('week', a.created_at)::date - co.cohort_week) / 7 AS week_offset,
+			COUNT(DISTINCT co.user_id) AS users
+		FROM cohorts co
+		JOIN activity a ON a.app_id = co.app_id AND a.user_id = co.user_id AND a.created_at >= co.cohort_week
+		GROUP BY co.app_id, co.cohort_week, week_offset`, params).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	type cohortKey struct {
+		appID uint
+		week  string
+	}
+	sizeOf := map[cohortKey]int{}
+	for _, s := range sizes {
+		sizeOf[cohortKey{s.AppID, s.CohortWeek.Format("2006-01-02")}] = s.N
+	}
+	for i := range rows {
+		rows[i].CohortSize = sizeOf[cohortKey{rows[i].AppID, rows[i].CohortWeek.Format("2006-01-02")}]
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "app_id"}, {Name: "cohort_week"}, {Name: "week_offset"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cohort_size", "users", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// truncateWeek returns the Monday of t's week, matching Postgres date_trunc('week')
+func truncateWeek(t time.Time) time.Time {
+	day := truncateDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,72 @@
+// Package jobs runs periodic background work such as analytics rollups,
+// trending scores, recommendations, app transfer expiry, purging deleted apps,
+// the automated checks on submitted releases, API key expiry notices and push
+// delivery of notifications.
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/precheck"
+	"github.com/fasad/solanafon-back/internal/push"
+	"gorm.io/gorm"
+)
+
+// Start launches the background jobs. Each job runs once right away and then on its interval.
+func Start(db *gorm.DB, cfg *config.Config) {
+	if !cfg.JobsEnabled {
+		log.Println("Background jobs disabled")
+		return
+	}
+
+	go every("analytics rollup", time.Hour, func() error {
+		return RollupAnalytics(db, time.Now())
+	})
+	go every("trending", 15*time.Minute, func() error {
+		return ComputeTrending(db, time.Now())
+	})
+	go every("recommendations", 6*time.Hour, func() error {
+		return ComputeRecommendations(db, time.Now())
+	})
+	go every("app transfer expiry", 15*time.Minute, func() error {
+		return ExpireAppTransfers(db, time.Now())
+	})
+	go every("deleted app purge", 24*time.Hour, func() error {
+		return PurgeDeletedApps(db, time.Now())
+	})
+	checker := precheck.New(precheck.NewHTTPClient(), cfg.BannedTerms)
+	go every("release prechecks", time.Minute, func() error {
+		return PrecheckReleases(db, checker, time.Now())
+	})
+	go every("API key expiry notices", time.Hour, func() error {
+		return NotifyExpiringAPIKeys(db, time.Now())
+	})
+	sender, err := push.New(cfg.FCMCredentialsFile)
+	if err != nil {
+		log.Printf("Push notifications disabled: %v", err)
+	} else if sender != nil {
+		go every("push notifications", 30*time.Second, func() error {
+			return PushNotifications(db, sender, time.Now())
+		})
+	}
+}
+
+func every(name string, interval time.Duration, fn func() error) {
+	run := func() {
+		start := time.Now()
+		if err := fn(); err != nil {
+			log.Printf("Job %s failed: %v", name, err)
+			return
+		}
+		log.Printf("Job %s finished in %s", name, time.Since(start).Round(time.Millisecond))
+	}
+
+	run()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		run()
+	}
+}
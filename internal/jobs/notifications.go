@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/notify"
+	"github.com/fasad/solanafon-back/internal/push"
+	"gorm.io/gorm"
+)
+
+// pushMaxAge — notifications older than this are no longer worth a push, e.g.
+// the backlog from before push was configured
+const pushMaxAge = time.Hour
+
+// NotifyExpiringAPIKeys warns app teams once when their API key is about to expire
+func NotifyExpiringAPIKeys(db *gorm.DB, now time.Time) error {
+	var apps []models.MiniApp
+	if err := db.Where("api_token_expires_at > ? AND api_token_expires_at <= ? AND api_token_expiry_notified_at IS NULL",
+		now, now.Add(models.APITokenExpiryNotice)).Find(&apps).Error; err != nil {
+		return err
+	}
+
+	for _, app := range apps {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&models.MiniApp{}).
+				Where("id = ? AND api_token_expiry_notified_at IS NULL", app.ID).
+				Update("api_token_expiry_notified_at", now)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			return notify.APIKeyExpiring(tx, app)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PushNotifications sends new notifications to the devices of users who keep
+// push enabled, and drops device tokens FCM no longer accepts
+func PushNotifications(db *gorm.DB, sender push.Sender, now time.Time) error {
+	var notifs []models.Notification
+	if err := db.Preload("User").
+		Where("pushed_at IS NULL AND created_at > ?", now.Add(-pushMaxAge)).
+		Order("created_at ASC").Limit(500).Find(&notifs).Error; err != nil {
+		return err
+	}
+
+	for _, n := range notifs {
+		if n.User.NotificationsEnabled && n.User.PushNotifications {
+			var tokens []models.PushToken
+			db.Where("user_id = ?", n.UserID).Find(&tokens)
+			for _, t := range tokens {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				err := sender.Send(ctx, t.FCMToken, push.Message{Title: n.Title, Body: n.Body, ActionURL: n.ActionURL})
+				cancel()
+				if errors.Is(err, push.ErrInvalidToken) {
+					db.Delete(&t)
+				} else if err != nil {
+					log.Printf("Push to user %d failed: %v", n.UserID, err)
+				}
+			}
+		}
+		if err := db.Model(&n).Update("pushed_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/handlers"
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/precheck"
+	"gorm.io/gorm"
+)
+
+// PrecheckReleases runs the automated checks on submitted releases that haven't
+// been checked yet, attaches the findings as risk flags and rejects releases
+// with a blocking finding before a moderator picks them up.
+func PrecheckReleases(db *gorm.DB, checker *precheck.Checker, now time.Time) error {
+	var releases []models.AppRelease
+	if err := db.Preload("App").
+		Where("status = ? AND checked_at IS NULL", models.ReleasePending).
+		Order("submitted_at ASC").Limit(50).Find(&releases).Error; err != nil {
+		return err
+	}
+
+	for _, r := range releases {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		flags := checker.Check(ctx, precheck.Submission{
+			Title: r.Title, Subtitle: r.Subtitle, Description: r.Description,
+			LongDescription: r.LongDescription, URL: r.URL, WebhookURL: r.App.WebhookURL,
+		})
+		cancel()
+		flags = append(flags, listingFlags(db, r)...)
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return applyPrecheck(tx, r, flags, now)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listingFlags checks the release against our own data: the icon must be one
+// of our uploads and the name shouldn't copy another app's
+func listingFlags(db *gorm.DB, r models.AppRelease) []models.RiskFlag {
+	var flags []models.RiskFlag
+	if r.IconURL != "" {
+		var uploaded int64
+		db.Model(&models.Upload{}).Where("url = ?", r.IconURL).Count(&uploaded)
+		if uploaded == 0 {
+			flags = append(flags, models.RiskFlag{
+				Code: "icon_not_uploaded", Severity: models.RiskWarning, Field: "iconUrl",
+				Message: "iconUrl doesn't point to a file uploaded through /api/upload", Reason: "low_quality",
+			})
+		}
+	}
+
+	var duplicates int64
+	db.Model(&models.MiniApp{}).Where("LOWER(title) = ? AND id <> ?", strings.ToLower(strings.TrimSpace(r.Title)), r.AppID).Count(&duplicates)
+	if duplicates > 0 {
+		flags = append(flags, models.RiskFlag{
+			Code: "duplicate_title", Severity: models.RiskWarning, Field: "name",
+			Message: "Another app already uses this name", Reason: "intellectual_property",
+		})
+	}
+	return flags
+}
+
+// applyPrecheck stores the findings on the release and rejects it when any is
+// blocking. A release resubmitted while the checks ran is left for the next run.
+func applyPrecheck(tx *gorm.DB, r models.AppRelease, flags []models.RiskFlag, now time.Time) error {
+	riskFlags := ""
+	if len(flags) > 0 {
+		encoded, _ := json.Marshal(flags)
+		riskFlags = string(encoded)
+	}
+	res := tx.Model(&models.AppRelease{}).
+		Where("id = ? AND status = ? AND checked_at IS NULL AND submitted_at = ?", r.ID, models.ReleasePending, r.SubmittedAt).
+		Updates(map[string]interface{}{"risk_flags": riskFlags, "checked_at": now})
+	blocking := precheck.Blocking(flags)
+	if res.Error != nil || res.RowsAffected == 0 || len(blocking) == 0 {
+		return res.Error
+	}
+
+	messages := make([]string, len(blocking))
+	var reasons []string
+	seen := map[string]bool{}
+	for i, f := range blocking {
+		messages[i] = f.Message
+		if !seen[f.Reason] {
+			seen[f.Reason] = true
+			reasons = append(reasons, f.Reason)
+		}
+	}
+	note := "Rejected by automated checks: " + strings.Join(messages, "; ")
+
+	var release models.AppRelease
+	if err := tx.First(&release, r.ID).Error; err != nil {
+		return err
+	}
+	var app models.MiniApp
+	if err := tx.First(&app, r.AppID).Error; err != nil {
+		return err
+	}
+	return handlers.DecideRelease(tx, &app, &release, nil, models.ModerationActionRejected, note, reasons)
+}
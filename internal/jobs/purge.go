@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"gorm.io/gorm"
+)
+
+// PurgeDeletedApps permanently removes apps deleted more than
+// models.AppRestoreWindow ago, together with everything users and developers
+// created around them. Invoices are financial records, and transfers and
+// moderation decisions are audit records; they stay, and an app that has them
+// is reduced to an anonymous placeholder row instead of being deleted.
+func PurgeDeletedApps(db *gorm.DB, now time.Time) error {
+	var apps []models.MiniApp
+	if err := db.Unscoped().
+		Where("deleted_at < ? AND purged_at IS NULL", now.Add(-models.AppRestoreWindow)).
+		Find(&apps).Error; err != nil {
+		return err
+	}
+
+	for _, app := range apps {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return purgeApp(tx.Unscoped(), app, now)
+		}); err != nil {
+			return fmt.Errorf("purge app %d: %w", app.ID, err)
+		}
+	}
+	return nil
+}
+
+func purgeApp(tx *gorm.DB, app models.MiniApp, now time.Time) error {
+	reviews := tx.Model(&models.AppReview{}).Select("id").Where("app_id = ?", app.ID)
+	posts := tx.Model(&models.NewsPost{}).Select("id").Where("app_id = ?", app.ID)
+	convs := tx.Model(&models.Conversation{}).Select("id").Where("app_id = ?", app.ID)
+	comments := tx.Model(&models.NewsComment{}).Select("id").Where("post_id IN (?)", posts)
+	messages := tx.Model(&models.ChatMessage{}).Select("id").Where("app_id = ?", app.ID)
+	moderated := tx.Model(&models.ModerationAction{}).Select("release_id").Where("app_id = ?", app.ID)
+	// Open reports go with the content; resolved ones record triage decisions and stay
+	reported := "target_type = ? AND target_id IN (?) AND status = ?"
+
+	// Children first, then everything keyed by the app
+	byApp := []interface{}{app.ID}
+	steps := []struct {
+		model interface{}
+		where string
+		args  []interface{}
+	}{
+		{&models.AppReviewRevision{}, "review_id IN (?)", []interface{}{reviews}},
+		{&models.ReviewHelpfulVote{}, "review_id IN (?)", []interface{}{reviews}},
+		{&models.ContentReport{}, "target_type = ? AND target_id = ? AND status = ?", []interface{}{models.ReportTargetApp, app.ID, models.ReportOpen}},
+		{&models.ContentReport{}, reported, []interface{}{models.ReportTargetReview, reviews, models.ReportOpen}},
+		{&models.ContentReport{}, reported, []interface{}{models.ReportTargetPost, posts, models.ReportOpen}},
+		{&models.ContentReport{}, reported, []interface{}{models.ReportTargetComment, comments, models.ReportOpen}},
+		{&models.ContentReport{}, reported, []interface{}{models.ReportTargetMessage, messages, models.ReportOpen}},
+		{&models.NewsLike{}, "post_id IN (?)", []interface{}{posts}},
+		{&models.NewsComment{}, "post_id IN (?)", []interface{}{posts}},
+		{&models.ChatMessage{}, "conversation_id IN (?)", []interface{}{convs}},
+		{&models.AppReview{}, "app_id = ?", byApp},
+		{&models.NewsPost{}, "app_id = ?", byApp},
+		{&models.Conversation{}, "app_id = ?", byApp},
+		{&models.AppMessage{}, "app_id = ?", byApp},
+		{&models.AppUser{}, "app_id = ?", byApp},
+		{&models.AppFavorite{}, "app_id = ?", byApp},
+		{&models.BotCommand{}, "app_id = ?", byApp},
+		{&models.BotEvent{}, "app_id = ?", byApp},
+		{&models.WebhookLog{}, "app_id = ?", byApp},
+		{&models.AppPermissionGrant{}, "app_id = ?", byApp},
+		{&models.AppRelease{}, "app_id = ? AND id NOT IN (?)", []interface{}{app.ID, moderated}},
+		{&models.AppLaunch{}, "app_id = ?", byApp},
+		{&models.AppDailyStats{}, "app_id = ?", byApp},
+		{&models.AppRetentionCohort{}, "app_id = ?", byApp},
+		{&models.AppCommandStats{}, "app_id = ?", byApp},
+		{&models.AppBlock{}, "app_id = ?", byApp},
+		{&models.AppRecommendation{}, "app_id = ? OR source_app_id = ?", []interface{}{app.ID, app.ID}},
+		{&models.ShareClick{}, "app_id = ?", byApp},
+		{&models.ShareLink{}, "app_id = ?", byApp},
+	}
+	for _, step := range steps {
+		if err := tx.Where(step.where, step.args...).Delete(step.model).Error; err != nil {
+			return err
+		}
+	}
+
+	// Releases the moderation history points at keep only their review outcome
+	if err := tx.Model(&models.AppRelease{}).Where("app_id = ?", app.ID).Updates(map[string]interface{}{
+		"title": "Deleted app", "subtitle": "", "description": "", "long_description": "",
+		"icon": "", "icon_url": "", "url": "", "tags": "", "screenshots": "", "permissions": "",
+		"changelog": "", "risk_flags": "",
+	}).Error; err != nil {
+		return err
+	}
+
+	var kept int64
+	for _, model := range []interface{}{&models.Invoice{}, &models.AppTransfer{}, &models.AppRelease{}} {
+		var n int64
+		tx.Model(model).Where("app_id = ?", app.ID).Count(&n)
+		kept += n
+	}
+	if kept == 0 {
+		return tx.Delete(&app).Error
+	}
+
+	placeholder := fmt.Sprintf("purged_%d", app.ID)
+	return tx.Model(&app).Updates(map[string]interface{}{
+		"title": "Deleted app", "subtitle": "", "description": "", "long_description": "",
+		"icon": "", "icon_url": "", "url": "", "tags": nil, "screenshots": nil,
+		"welcome_message": "", "welcome_banner_url": "", "webhook_url": "",
+		"api_token": placeholder, "webhook_secret": "", "bot_username": placeholder,
+		"purged_at": now,
+	}).Error
+}
@@ -0,0 +1,295 @@
+package jobs
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// Users active within this period get personal recommendations
+	recommendActiveWindow = 90 * 24 * time.Hour
+	recommendPerUser      = 30
+	recommendBatchSize    = 500
+	// Similar apps kept per app, and the minimum shared users for a pair to count
+	similarPerApp  = 50
+	minSharedUsers = 2
+	// Co-usage looks at each user's most recently used apps only, and pairs up
+	// this many apps per query
+	coUsageAppsPerUser = 50
+	similarBatchSize   = 200
+
+	// Score weights
+	weightCoUsage  = 1.0
+	weightCategory = 0.3
+	weightLanguage = 0.2
+	weightPopular  = 0.1
+)
+
+type similarApp struct {
+	AppID uint
+	Score float64
+}
+
+type candidateApp struct {
+	ID         uint
+	CategoryID uint
+	UsersCount int
+}
+
+type recCandidate struct {
+	score      float64
+	reason     string
+	reasonPart float64
+	sourceApp  *uint
+}
+
+// ComputeRecommendations rebuilds app_recommendations for recently active users.
+// Scores combine item-to-item co-usage from app_users, the categories the user already
+// uses, popularity among users of the same language and overall popularity.
+func ComputeRecommendations(db *gorm.DB, now time.Time) error {
+	var apps []candidateApp
+	if err := db.Model(&models.MiniApp{}).Select("id, category_id, users_count").
+		Where("moderation_status = ? AND unpublished_at IS NULL AND hidden_at IS NULL AND suspended_at IS NULL AND is_secret = false", models.ModerationApproved).
+		Scan(&apps).Error; err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+
+	maxUsers := 1
+	for _, a := range apps {
+		if a.UsersCount > maxUsers {
+			maxUsers = a.UsersCount
+		}
+	}
+
+	similar, err := loadSimilarApps(db)
+	if err != nil {
+		return err
+	}
+	// Co-usage pairs cover every app people used; keep only the ones that can be recommended
+	listed := make(map[uint]bool, len(apps))
+	for _, a := range apps {
+		listed[a.ID] = true
+	}
+	for id, list := range similar {
+		kept := list[:0]
+		for _, s := range list {
+			if listed[s.AppID] {
+				kept = append(kept, s)
+			}
+		}
+		similar[id] = kept
+	}
+	langShare, err := loadLanguageShare(db)
+	if err != nil {
+		return err
+	}
+
+	since := now.Add(-recommendActiveWindow)
+	var lastID uint
+	for {
+		var users []models.User
+		if err := db.Select("id, language").
+			Where("id > ? AND id IN (SELECT user_id FROM app_users WHERE last_used >= ? OR created_at >= ?)", lastID, since, since).
+			Order("id ASC").Limit(recommendBatchSize).Find(&users).Error; err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		lastID = users[len(users)-1].ID
+
+		for _, u := range users {
+			if err := recommendForUser(db, u, apps, maxUsers, similar, langShare); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func recommendForUser(db *gorm.DB, user models.User, apps []candidateApp, maxUsers int,
+	similar map[uint][]similarApp, langShare map[uint]map[string]float64) error {
+
+	var used []candidateApp
+	db.Table("app_users").Select("mini_apps.id, mini_apps.category_id").
+		Joins("JOIN mini_apps ON mini_apps.id = app_users.app_id").
+		Where("app_users.user_id = ?", user.ID).Scan(&used)
+
+	var blocked []uint
+	db.Model(&models.AppBlock{}).Where("user_id = ?", user.ID).Pluck("app_id", &blocked)
+
+	exclude := map[uint]bool{}
+	for _, id := range blocked {
+		exclude[id] = true
+	}
+	categoryAffinity := map[uint]float64{}
+	for _, a := range used {
+		exclude[a.ID] = true
+		categoryAffinity[a.CategoryID] += 1 / float64(len(used))
+	}
+
+	candidates := map[uint]*recCandidate{}
+	add := func(appID uint, part float64, reason string, source *uint) {
+		c := candidates[appID]
+		if c == nil {
+			c = &recCandidate{}
+			candidates[appID] = c
+		}
+		c.score += part
+		if part > c.reasonPart {
+			c.reasonPart, c.reason, c.sourceApp = part, reason, source
+		}
+	}
+
+	for _, a := range used {
+		source := a.ID
+		for _, s := range similar[a.ID] {
+			if !exclude[s.AppID] {
+				add(s.AppID, weightCoUsage*s.Score, models.RecommendCoUsage, &source)
+			}
+		}
+	}
+	for _, a := range apps {
+		if exclude[a.ID] {
+			continue
+		}
+		if aff := categoryAffinity[a.CategoryID]; aff > 0 {
+			add(a.ID, weightCategory*aff, models.RecommendCategory, nil)
+		}
+		if share := langShare[a.ID][user.Language]; share > 0 {
+			add(a.ID, weightLanguage*share, models.RecommendLanguage, nil)
+		}
+		popularity := math.Log1p(float64(a.UsersCount)) / math.Log1p(float64(maxUsers))
+		if popularity > 0 {
+			add(a.ID, weightPopular*popularity, models.RecommendPopular, nil)
+		}
+	}
+
+	recs := make([]models.AppRecommendation, 0, len(candidates))
+	for appID, c := range candidates {
+		recs = append(recs, models.AppRecommendation{
+			UserID: user.ID, AppID: appID, Score: c.score, Reason: c.reason, SourceAppID: c.sourceApp,
+		})
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].Score != recs[j].Score {
+			return recs[i].Score > recs[j].Score
+		}
+		return recs[i].AppID < recs[j].AppID
+	})
+	if len(recs) > recommendPerUser {
+		recs = recs[:recommendPerUser]
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.AppRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(recs) == 0 {
+			return nil
+		}
+		return tx.Create(&recs).Error
+	})
+}
+
+// loadSimilarApps returns, per app, the apps most often used by the same people,
+// scored by cosine similarity of their user sets. Each user contributes only their
+// most recently used apps, so the self-join stays linear in the number of users.
+// Those are materialized once into temp tables, and the pairs are built and ranked
+// in SQL for a batch of apps at a time.
+func loadSimilarApps(db *gorm.DB) (map[uint][]similarApp, error) {
+	similar := map[uint][]similarApp{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`CREATE TEMP TABLE recent_app_users ON COMMIT DROP AS
+			SELECT user_id, app_id FROM (
+				SELECT user_id, app_id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY last_used DESC, app_id) AS n
+				FROM app_users
+			) r WHERE n <= ?`, coUsageAppsPerUser).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`CREATE INDEX ON recent_app_users (user_id)`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`CREATE TEMP TABLE recent_app_counts ON COMMIT DROP AS
+			SELECT app_id, COUNT(*)::float AS n FROM recent_app_users GROUP BY app_id`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`ANALYZE recent_app_users, recent_app_counts`).Error; err != nil {
+			return err
+		}
+
+		var appIDs []uint
+		if err := tx.Raw(`SELECT app_id FROM recent_app_counts ORDER BY app_id`).Scan(&appIDs).Error; err != nil {
+			return err
+		}
+		for start := 0; start < len(appIDs); start += similarBatchSize {
+			end := start + similarBatchSize
+			if end > len(appIDs) {
+				end = len(appIDs)
+			}
+
+			var pairs []struct {
+				X     uint
+				Y     uint
+				Score float64
+			}
+			if err := tx.Raw(`SELECT x, y, score FROM (
+					SELECT p.x, p.y, p.together / sqrt(cx.n * cy.n) AS score,
+						ROW_NUMBER() OVER (PARTITION BY p.x ORDER BY p.together / sqrt(cx.n * cy.n) DESC, p.y) AS rank
+					FROM (
+						SELECT a.app_id AS x, b.app_id AS y, COUNT(*)::float AS together
+						FROM recent_app_users a JOIN recent_app_users b ON a.user_id = b.user_id AND a.app_id <> b.app_id
+						WHERE a.app_id IN ?
+						GROUP BY a.app_id, b.app_id
+						HAVING COUNT(*) >= ?
+					) p
+					JOIN recent_app_counts cx ON cx.app_id = p.x
+					JOIN recent_app_counts cy ON cy.app_id = p.y
+				) ranked
+				WHERE rank <= ?
+				ORDER BY x, score DESC`, appIDs[start:end], minSharedUsers, similarPerApp).
+				Scan(&pairs).Error; err != nil {
+				return err
+			}
+			for _, p := range pairs {
+				similar[p.X] = append(similar[p.X], similarApp{AppID: p.Y, Score: p.Score})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return similar, nil
+}
+
+// loadLanguageShare returns, per app, the share of its users speaking each language
+func loadLanguageShare(db *gorm.DB) (map[uint]map[string]float64, error) {
+	var rows []struct {
+		AppID    uint
+		Language string
+		Share    float64
+	}
+	if err := db.Raw(`SELECT au.app_id, u.language,
+			COUNT(*)::float / SUM(COUNT(*)) OVER (PARTITION BY au.app_id) AS share
+		FROM app_users au JOIN users u ON u.id = au.user_id
+		GROUP BY au.app_id, u.language`).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	share := map[uint]map[string]float64{}
+	for _, r := range rows {
+		if share[r.AppID] == nil {
+			share[r.AppID] = map[string]float64{}
+		}
+		share[r.AppID][r.Language] = r.Share
+	}
+	return share, nil
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"gorm.io/gorm"
+)
+
+// ExpireAppTransfers closes app transfers the recipient didn't accept in time
+// and lets the senders know.
+func ExpireAppTransfers(db *gorm.DB, now time.Time) error {
+	var transfers []models.AppTransfer
+	if err := db.Preload("App").
+		Where("status = ? AND expires_at <= ?", models.TransferPending, now).
+		Find(&transfers).Error; err != nil {
+		return err
+	}
+
+	for _, t := range transfers {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&models.AppTransfer{}).
+				Where("id = ? AND status = ?", t.ID, models.TransferPending).
+				Update("status", models.TransferExpired)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			if err := tx.Create(&models.AppTransferStatusChange{
+				TransferID: t.ID, FromStatus: models.TransferPending, ToStatus: models.TransferExpired,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.Notification{
+				UserID: t.FromUserID, Type: "system",
+				Title: "App transfer expired",
+				Body:  fmt.Sprintf("%s didn't accept the transfer of %s in time. The app stays yours.", t.ToEmail, t.App.Title),
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
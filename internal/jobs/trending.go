@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Activity older than the window does not count towards trending
+	trendingWindow = 7 * 24 * time.Hour
+	// Weight of an event halves every trendingHalfLife
+	trendingHalfLife = 24 * time.Hour
+	// Number of apps flagged IsTrending and the minimum score to qualify
+	trendingSize     = 20
+	trendingMinScore = 1.0
+)
+
+// trendingEventsSQL lists weighted activity events since @from: a new user counts
+// more than a launch, a launch more than a single message.
+const trendingEventsSQL = `
+	SELECT app_id, created_at, 3.0 AS weight FROM app_users WHERE created_at >= @from
+	UNION ALL
+	SELECT app_id, created_at, 1.0 AS weight FROM app_launches WHERE created_at >= @from
+	UNION ALL
+	SELECT app_id, created_at, 0.25 AS weight FROM chat_messages
+	WHERE sender_type = 'user' AND created_at >= @from
+	UNION ALL
+	SELECT app_id, created_at, 0.25 AS weight FROM app_messages
+	WHERE is_from_bot = false AND created_at >= @from`
+
+// ComputeTrending scores every app by its recent activity with exponential decay and
+// flags the top apps as trending. Apps without activity in the window drop to zero.
+func ComputeTrending(db *gorm.DB, now time.Time) error {
+	params := map[string]interface{}{
+		"from": now.Add(-trendingWindow),
+		"now":  now,
+		"tau":  trendingHalfLife.Hours() / math.Ln2,
+		"min":  trendingMinScore,
+		"size": trendingSize,
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE mini_apps SET trending_score = 0 WHERE trending_score <> 0`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`UPDATE mini_apps m SET trending_score = s.score
+			FROM (
+				SELECT app_id, SUM(weight * exp(-extract(epoch FROM (@now - created_at)) / 3600 / @tau)) AS score
+				FROM (`+trendingEventsSQL+`) e
+				GROUP BY app_id
+			) s
+			WHERE m.id = s.app_id`, params).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`UPDATE mini_apps SET is_trending = id IN (
+				SELECT id FROM mini_apps
+				WHERE deleted_at IS NULL AND moderation_status = 'approved' AND unpublished_at IS NULL
+					AND hidden_at IS NULL AND suspended_at IS NULL
+					AND trending_score >= @min
+				ORDER BY trending_score DESC LIMIT @size
+			)`, params).Error
+	})
+}
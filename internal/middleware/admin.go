@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AdminRequired allows the request only for users with the admin role. Accounts
+// listed in adminEmails are admins regardless of their role, which bootstraps
+// the first admins before anyone can grant the role. Must be mounted after
+// AuthRequired.
+func AdminRequired(db *gorm.DB, adminEmails []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if isAdmin(db, adminEmails, c) {
+			return c.Next()
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": fiber.Map{"code": "FORBIDDEN", "message": "Admin access required"},
+		})
+	}
+}
+
+// isAdmin reports whether the authenticated user is an admin
+func isAdmin(db *gorm.DB, adminEmails []string, c *fiber.Ctx) bool {
+	email, _ := c.Locals("email").(string)
+	for _, admin := range adminEmails {
+		if strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return false
+	}
+	var count int64
+	db.Model(&models.User{}).Where("id = ? AND role = ?", userID, models.UserRoleAdmin).Count(&count)
+	return count > 0
+}
@@ -2,12 +2,18 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
+	"github.com/fasad/solanafon-back/internal/models"
 	"github.com/fasad/solanafon-back/internal/utils"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
-func AuthRequired(jwtSecret string) fiber.Handler {
+// AuthRequired validates the bearer token and rejects deleted, suspended and
+// banned accounts, so a sanction applies to tokens issued before it. Tokens
+// issued before the user's sessions were revoked are rejected too.
+func AuthRequired(db *gorm.DB, jwtSecret string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -32,6 +38,21 @@ func AuthRequired(jwtSecret string) fiber.Handler {
 			})
 		}
 
+		var user models.User
+		if err := db.Select("id", "status", "status_reason", "suspended_until", "sessions_revoked_at").First(&user, claims.UserID).Error; err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+		if user.SessionsRevokedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*user.SessionsRevokedAt)) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+		if user.Sanction(time.Now()) != "" {
+			return AccountSanctionError(c, user)
+		}
+
 		// Store user ID in context
 		c.Locals("userID", claims.UserID)
 		c.Locals("email", claims.Email)
@@ -39,3 +60,31 @@ func AuthRequired(jwtSecret string) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// AuthOptional sets userID when a valid bearer token is sent and lets anonymous
+// requests through (public pages that personalize for signed-in users)
+func AuthOptional(jwtSecret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parts := strings.Split(c.Get("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := utils.ValidateJWT(parts[1], jwtSecret); err == nil {
+				c.Locals("userID", claims.UserID)
+				c.Locals("email", claims.Email)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// AccountSanctionError responds 403 with ACCOUNT_SUSPENDED or ACCOUNT_BANNED, the
+// reason and, for a suspension, when it ends. Token issuance uses it too, so
+// clients see one error for a sanctioned account wherever they hit it.
+func AccountSanctionError(c *fiber.Ctx, user models.User) error {
+	code, message := "ACCOUNT_BANNED", "This account has been banned"
+	if user.Sanction(time.Now()) == models.AccountSuspended {
+		code, message = "ACCOUNT_SUSPENDED", "This account is suspended"
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": fiber.Map{
+		"code": code, "message": message, "reason": user.StatusReason, "suspendedUntil": user.SuspendedUntil,
+	}})
+}
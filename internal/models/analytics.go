@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// AppLaunch — raw launch event, source data for the analytics rollup
+type AppLaunch struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	AppID     uint      `gorm:"not null;index:idx_launch_app_time" json:"appId"`
+	UserID    uint      `gorm:"not null;index" json:"userId"`
+	CreatedAt time.Time `gorm:"index:idx_launch_app_time" json:"createdAt"`
+}
+
+// AppDailyStats — per-app daily aggregate written by the analytics rollup job
+type AppDailyStats struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	AppID              uint      `gorm:"not null;uniqueIndex:idx_daily_app_date" json:"appId"`
+	Date               time.Time `gorm:"type:date;not null;uniqueIndex:idx_daily_app_date" json:"date"`
+	ActiveUsers        int       `gorm:"default:0" json:"activeUsers"`        // DAU
+	MonthlyActiveUsers int       `gorm:"default:0" json:"monthlyActiveUsers"` // MAU, 30-day window ending on Date
+	NewUsers           int       `gorm:"default:0" json:"newUsers"`
+	ReturningUsers     int       `gorm:"default:0" json:"returningUsers"`
+	Launches           int       `gorm:"default:0" json:"launches"`
+	MessagesIn         int       `gorm:"default:0" json:"messagesIn"`  // user → bot
+	MessagesOut        int       `gorm:"default:0" json:"messagesOut"` // bot → user
+	ShareClicks        int       `gorm:"default:0" json:"shareClicks"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// AppRetentionCohort — users of a weekly cohort still active WeekOffset weeks later
+type AppRetentionCohort struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	AppID      uint      `gorm:"not null;uniqueIndex:idx_cohort_app_week_offset" json:"appId"`
+	CohortWeek time.Time `gorm:"type:date;not null;uniqueIndex:idx_cohort_app_week_offset" json:"cohortWeek"`
+	WeekOffset int       `gorm:"not null;uniqueIndex:idx_cohort_app_week_offset" json:"weekOffset"`
+	CohortSize int       `gorm:"default:0" json:"cohortSize"`
+	Users      int       `gorm:"default:0" json:"users"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// AppCommandStats — daily usage count of a bot command
+type AppCommandStats struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	AppID     uint      `gorm:"not null;uniqueIndex:idx_cmd_app_date_cmd" json:"appId"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex:idx_cmd_app_date_cmd" json:"date"`
+	Command   string    `gorm:"not null;uniqueIndex:idx_cmd_app_date_cmd" json:"command"`
+	Count     int       `gorm:"default:0" json:"count"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actor types
+const (
+	AuditActorUser   = "user"   // The signed-in user acting on their own account or apps
+	AuditActorAdmin  = "admin"  // An admin acting through the admin API
+	AuditActorApp    = "app"    // A bot authenticated with its API token
+	AuditActorSystem = "system" // Automatic, e.g. a webhook disabled after failures
+)
+
+// Audited actions
+const (
+	AuditAPIKeyGenerated  = "api_key.generated"
+	AuditAPIKeyRevoked    = "api_key.revoked"
+	AuditWebhookUpdated   = "webhook.updated"
+	AuditWebhookDeleted   = "webhook.deleted"
+	AuditWebhookDisabled  = "webhook.disabled"
+	AuditSessionRevoked   = "session.revoked"
+	AuditSessionsRevoked  = "sessions.revoked" // Every session and refresh token of the account
+	AuditAccountDeleted   = "account.deleted"
+	AuditAccountSanction  = "account.sanctioned"
+	AuditAccountReinstate = "account.sanction_lifted"
+	AuditRoleChanged      = "account.role_changed"
+	AuditBalanceChanged   = "balance.changed"
+)
+
+// Audit target types
+const (
+	AuditTargetUser    = "user"
+	AuditTargetApp     = "app"
+	AuditTargetSession = "session"
+)
+
+// AuditChange — one field's value before and after an audited action
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditLog — a security-relevant or admin action. Rows are only ever inserted;
+// the database rejects updates and deletes (see database.SetupAuditLog).
+type AuditLog struct {
+	ID         uint   `gorm:"primarykey" json:"id"`
+	ActorType  string `gorm:"not null" json:"actorType"`
+	ActorID    *uint  `gorm:"index" json:"actorId,omitempty"` // User or app by ActorType; nil for system
+	Action     string `gorm:"not null;index" json:"action"`
+	TargetType string `gorm:"index:idx_audit_target" json:"targetType"`
+	TargetID   uint   `gorm:"index:idx_audit_target" json:"targetId"`
+	// Account the action concerns; the owner sees it in their security activity
+	UserID    *uint     `gorm:"index" json:"userId,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	Changes   string    `gorm:"type:text" json:"-"` // JSON map of field to AuditChange
+	Note      string    `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+// ChangeSet decodes Changes
+func (a *AuditLog) ChangeSet() map[string]AuditChange {
+	changes := map[string]AuditChange{}
+	if a.Changes != "" {
+		json.Unmarshal([]byte(a.Changes), &changes)
+	}
+	return changes
+}
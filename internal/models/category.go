@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CategoryTranslation — category name and description in one language.
+// Category.Name/Description are the default (English) texts.
+type CategoryTranslation struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CategoryID  uint      `gorm:"not null;uniqueIndex:idx_category_lang" json:"categoryId"`
+	Language    string    `gorm:"not null;uniqueIndex:idx_category_lang" json:"language"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Localized returns the category name and description in lang, falling back to
+// the default texts. Translations must be preloaded.
+func (c *Category) Localized(lang string) (name, description string) {
+	name, description = c.Name, c.Description
+	for _, t := range c.Translations {
+		if t.Language != lang {
+			continue
+		}
+		name = t.Name
+		if t.Description != "" {
+			description = t.Description
+		}
+		break
+	}
+	return name, description
+}
@@ -32,5 +32,6 @@ type ChatMessage struct {
 	Status         string    `gorm:"default:sent" json:"status"` // sending, sent, delivered, read, failed
 	ReplyToID      *uint     `json:"replyToId"`
 	Metadata       string    `gorm:"type:jsonb" json:"metadata,omitempty"`
+	HiddenAt       *time.Time `gorm:"index" json:"-"` // Hidden pending review after too many reports
 	CreatedAt      time.Time `json:"createdAt"`
 }
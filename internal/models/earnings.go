@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Earning entry types
+const (
+	EarningSale           = "sale"
+	EarningRefund         = "refund"
+	EarningPayout         = "payout"
+	EarningPayoutReversal = "payout_reversal"
+)
+
+// Payout statuses
+const (
+	PayoutRequested = "requested"
+	PayoutApproved  = "approved"
+	PayoutRejected  = "rejected"
+	PayoutPaid      = "paid"
+)
+
+// EarningEntry — developer earnings ledger row (append-only).
+// Amount is the signed net effect on the developer balance; entries become
+// part of the available balance once AvailableAt has passed.
+type EarningEntry struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	DeveloperID uint      `gorm:"not null;index" json:"developerId"`
+	AppID       *uint     `gorm:"index" json:"appId,omitempty"`
+	InvoiceID   *uint     `gorm:"index" json:"invoiceId,omitempty"`
+	PayoutID    *uint     `gorm:"index" json:"payoutId,omitempty"`
+	Type        string    `gorm:"not null" json:"type"` // sale, refund, payout, payout_reversal
+	GrossAmount int       `gorm:"default:0" json:"grossAmount"`
+	FeeAmount   int       `gorm:"default:0" json:"feeAmount"`
+	Amount      int       `gorm:"not null" json:"amount"`
+	AvailableAt time.Time `gorm:"not null;index" json:"availableAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// PayoutRequest — developer request to withdraw available earnings
+type PayoutRequest struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	DeveloperID uint       `gorm:"not null;index" json:"developerId"`
+	Developer   User       `gorm:"foreignKey:DeveloperID" json:"-"`
+	Amount      int        `gorm:"not null" json:"amount"`                // Mana Points
+	Destination string     `gorm:"not null" json:"destination"`           // Wallet address
+	Status      string     `gorm:"default:requested;index" json:"status"` // requested, approved, rejected, paid
+	Note        string     `gorm:"type:text" json:"note,omitempty"`
+	Reference   string     `json:"reference,omitempty"` // External payment reference (tx signature)
+	ProcessedBy *uint      `json:"processedBy,omitempty"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// PayoutStatusChange — status history of a payout request
+type PayoutStatusChange struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	PayoutID   uint      `gorm:"not null;index" json:"payoutId"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `gorm:"not null" json:"toStatus"`
+	ChangedBy  uint      `json:"changedBy"`
+	Note       string    `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AppFavorite — app saved by a user. Pinned favorites carry a PinPosition and are
+// shown on the home screen in that order.
+type AppFavorite struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_favorite_user_app" json:"userId"`
+	AppID       uint      `gorm:"not null;uniqueIndex:idx_favorite_user_app" json:"appId"`
+	App         MiniApp   `gorm:"foreignKey:AppID" json:"-"`
+	PinPosition *int      `json:"pinPosition,omitempty"` // nil = not pinned; 0 is the first slot
+	CreatedAt   time.Time `json:"createdAt"`
+}
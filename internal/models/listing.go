@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Upload — file stored by the upload endpoint; screenshots must reference one
+type Upload struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	UserID      uint      `gorm:"not null;index" json:"userId"`
+	Filename    string    `gorm:"not null;uniqueIndex" json:"filename"`
+	URL         string    `gorm:"not null;index" json:"url"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Tag — normalized app tag vocabulary. Apps store tag slugs in MiniApp.Tags.
+type Tag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Slug      string    `gorm:"not null;uniqueIndex" json:"slug"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TagList returns the app's tag slugs
+func (a *MiniApp) TagList() []string {
+	return decodeStringList(a.Tags)
+}
+
+// ScreenshotList returns the app's screenshot URLs in display order
+func (a *MiniApp) ScreenshotList() []string {
+	return decodeStringList(a.Screenshots)
+}
+
+func decodeStringList(value string) []string {
+	list := []string{}
+	if value == "" {
+		return list
+	}
+	json.Unmarshal([]byte(value), &list)
+	return list
+}
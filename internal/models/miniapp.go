@@ -4,21 +4,23 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Category struct {
-	ID          uint           `gorm:"primarykey" json:"id"`
-	Name        string         `gorm:"unique;not null" json:"name"`
-	Slug        string         `gorm:"unique;not null" json:"slug"`
-	Description string         `json:"description"`
-	Icon        string         `json:"icon"`
-	Order       int            `gorm:"default:0" json:"order"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint                  `gorm:"primarykey" json:"id"`
+	Name         string                `gorm:"unique;not null" json:"name"`
+	Slug         string                `gorm:"unique;not null" json:"slug"`
+	Description  string                `json:"description"`
+	Icon         string                `json:"icon"`
+	Order        int                   `gorm:"default:0" json:"order"`
+	Translations []CategoryTranslation `gorm:"foreignKey:CategoryID" json:"translations,omitempty"`
+	CreatedAt    time.Time             `json:"createdAt"`
+	UpdatedAt    time.Time             `json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt        `gorm:"index" json:"-"`
 }
 
 // ModerationStatus - статус модерации приложения
@@ -41,7 +43,7 @@ type MiniApp struct {
 	IsSecret    bool           `gorm:"default:false" json:"isSecret"`
 	UsersCount  int            `gorm:"default:0" json:"usersCount"`
 	IsVerified  bool           `gorm:"default:false" json:"isVerified"`
-	IsTrending  bool           `gorm:"default:false" json:"isTrending"`
+	IsTrending  bool           `gorm:"default:false" json:"isTrending"` // Top apps by TrendingScore
 	Description string         `gorm:"type:text" json:"description"`
 	LongDescription string    `gorm:"type:text" json:"longDescription,omitempty"`
 
@@ -52,12 +54,24 @@ type MiniApp struct {
 	CreatorID uint  `gorm:"index" json:"creatorId"`
 	Creator   *User `gorm:"foreignKey:CreatorID" json:"creator,omitempty"`
 
+	// Owning organization; nil for personal apps, managed by the creator alone
+	OrganizationID *uint         `gorm:"index" json:"organizationId,omitempty"`
+	Organization   *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+
 	// Developer API credentials (for bot functionality)
 	APIToken      string `gorm:"unique" json:"-"`
 	WebhookURL    string `json:"webhookUrl,omitempty"`
 	WebhookSecret string `json:"-"`
 	BotUsername   string `gorm:"unique" json:"botUsername,omitempty"`
 
+	// Optional API token expiry; nil means the token doesn't expire
+	APITokenExpiresAt        *time.Time `gorm:"index" json:"-"`
+	APITokenExpiryNotifiedAt *time.Time `json:"-"`
+
+	// Consecutive failed webhook deliveries; the webhook is disabled after WebhookMaxFailures
+	WebhookFailures   int        `gorm:"default:0" json:"-"`
+	WebhookDisabledAt *time.Time `json:"webhookDisabledAt,omitempty"`
+
 	// Bot welcome message (shown on /start)
 	WelcomeMessage    string `gorm:"type:text" json:"welcomeMessage,omitempty"`
 	WelcomeBannerURL  string `json:"welcomeBannerUrl,omitempty"`
@@ -70,14 +84,71 @@ type MiniApp struct {
 	Permissions  string  `gorm:"type:jsonb" json:"permissions,omitempty"`
 	Version      string  `gorm:"default:1.0.0" json:"version"`
 
+	// Release currently live in the marketplace, see AppRelease
+	LiveReleaseID *uint `json:"liveReleaseId,omitempty"`
+
+	// Trending — decayed recent activity, recomputed by jobs.ComputeTrending
+	TrendingScore float64 `gorm:"default:0;index" json:"trendingScore"`
+
 	// Moderation
 	ModerationStatus ModerationStatus `gorm:"default:pending" json:"moderationStatus"`
 	ModerationNote   string           `json:"moderationNote,omitempty"`
 	ModeratedAt      *time.Time       `json:"moderatedAt,omitempty"`
 
+	// Set while the developer has taken the app off the marketplace; independent of moderation
+	UnpublishedAt *time.Time `gorm:"index" json:"unpublishedAt,omitempty"`
+
+	// Abuse handling, see ContentReport. Hidden: too many reports, pending review;
+	// suspended: taken down by an admin until reinstated.
+	HiddenAt         *time.Time `gorm:"index" json:"hiddenAt,omitempty"`
+	SuspendedAt      *time.Time `gorm:"index" json:"suspendedAt,omitempty"`
+	SuspensionReason string     `json:"suspensionReason,omitempty"`
+
+	// search_vector (tsvector) is maintained by a trigger, see database.SetupSearch
+
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	PurgedAt  *time.Time     `json:"-"` // Content purged; the row is kept only for invoices referencing it
+}
+
+// WebhookMaxFailures — consecutive failed deliveries after which a webhook is
+// disabled; bots then receive events through getUpdates until it is set again
+const WebhookMaxFailures = 20
+
+// APITokenExpiryNotice — how long before an API token expires its app team is notified
+const APITokenExpiryNotice = 7 * 24 * time.Hour
+
+// WebhookActive reports whether events are pushed to the app's webhook
+func (a *MiniApp) WebhookActive() bool {
+	return a.WebhookURL != "" && a.WebhookDisabledAt == nil
+}
+
+// Deleted apps can be restored for this long, then jobs.PurgeDeletedApps removes them
+const AppRestoreWindow = 30 * 24 * time.Hour
+
+// Reasons an app is unavailable to its existing users
+const (
+	AppUnpublished = "unpublished"  // Taken off the marketplace by the developer
+	AppDeleted     = "deleted"      // Deleted by the developer, restorable within AppRestoreWindow
+	AppSuspended   = "suspended"    // Taken down by an admin
+	AppUnderReview = "under_review" // Hidden after user reports until an admin reviews them
+)
+
+// UnavailableReason returns why users can't open the app, or "" when they can.
+// Load deleted apps with Unscoped to tell them apart from missing ones.
+func (a *MiniApp) UnavailableReason() string {
+	switch {
+	case a.DeletedAt.Valid:
+		return AppDeleted
+	case a.SuspendedAt != nil:
+		return AppSuspended
+	case a.HiddenAt != nil:
+		return AppUnderReview
+	case a.UnpublishedAt != nil:
+		return AppUnpublished
+	}
+	return ""
 }
 
 // FormatUsersCount returns human-readable user count
@@ -98,15 +169,29 @@ func GenerateAPIToken() string {
 	return hex.EncodeToString(bytes)
 }
 
+// revokedAPITokenPrefix marks a revoked key; api_token is unique, so it can't be left empty
+const revokedAPITokenPrefix = "revoked_"
+
+// RevokedAPIToken returns the placeholder stored in place of a revoked key
+func RevokedAPIToken(appID uint) string {
+	return fmt.Sprintf("%s%d", revokedAPITokenPrefix, appID)
+}
+
+// HasAPIToken reports whether the app has a usable API key (expiry aside)
+func (a *MiniApp) HasAPIToken() bool {
+	return a.APIToken != "" && !strings.HasPrefix(a.APIToken, revokedAPITokenPrefix)
+}
+
 // AppUser - tracks which users use which apps (conversations)
 type AppUser struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_app" json:"userId"`
-	User      User      `gorm:"foreignKey:UserID" json:"-"`
-	AppID     uint      `gorm:"not null;uniqueIndex:idx_user_app" json:"appId"`
-	App       MiniApp   `gorm:"foreignKey:AppID" json:"app,omitempty"`
-	LastUsed  time.Time `json:"lastUsed"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;uniqueIndex:idx_user_app" json:"userId"`
+	User      User       `gorm:"foreignKey:UserID" json:"-"`
+	AppID     uint       `gorm:"not null;uniqueIndex:idx_user_app" json:"appId"`
+	App       MiniApp    `gorm:"foreignKey:AppID" json:"app,omitempty"`
+	LastUsed  time.Time  `json:"lastUsed"`
+	HiddenAt  *time.Time `json:"hiddenAt,omitempty"` // Removed from recents; cleared on next use
+	CreatedAt time.Time  `json:"createdAt"`
 }
 
 // AppMessage - messages in app chat
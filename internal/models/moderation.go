@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// ModerationClaimTTL — how long a moderator's claim on a release keeps other
+// moderators off it. Stale claims can be taken over.
+const ModerationClaimTTL = 30 * time.Minute
+
+// Moderation history actions
+const (
+	ModerationActionClaimed   = "claimed"
+	ModerationActionUnclaimed = "unclaimed"
+	ModerationActionApproved  = "approved"
+	ModerationActionRejected  = "rejected"
+)
+
+// ModerationReasons — reason codes a rejection is tagged with, and their labels
+var ModerationReasons = map[string]string{
+	"spam":                  "Spam or misleading promotion",
+	"misleading":            "Listing doesn't match what the app does",
+	"broken":                "App doesn't load or is broken",
+	"inappropriate_content": "Inappropriate or offensive content",
+	"intellectual_property": "Uses someone else's name, brand or content",
+	"malware":               "Malicious or deceptive behavior",
+	"privacy":               "Requests permissions or data it doesn't need",
+	"low_quality":           "Incomplete listing or low quality",
+	"other":                 "Other, see the note",
+}
+
+// Risk flag severities. A blocking flag gets the release rejected automatically.
+const (
+	RiskWarning  = "warning"
+	RiskBlocking = "blocking"
+)
+
+// RiskFlag — finding of the automated pre-moderation checks on a release
+type RiskFlag struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+	Reason   string `json:"reason,omitempty"` // ModerationReasons code used when auto-rejecting
+}
+
+// ModerationAction — moderation history of app releases: every claim and decision
+type ModerationAction struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	ReleaseID   uint       `gorm:"not null;index" json:"releaseId"`
+	Release     AppRelease `gorm:"foreignKey:ReleaseID" json:"-"`
+	AppID       uint       `gorm:"not null;index" json:"appId"`
+	ModeratorID *uint      `gorm:"index" json:"moderatorId,omitempty"` // Nil for automated checks
+	Moderator   *User      `gorm:"foreignKey:ModeratorID" json:"-"`
+	Action      string     `gorm:"not null;index" json:"action"`
+	Note        string     `gorm:"type:text" json:"note,omitempty"`
+	Reasons     string     `gorm:"type:text" json:"-"` // JSON list of reason codes
+	CreatedAt   time.Time  `gorm:"index" json:"createdAt"`
+}
+
+// ReasonList returns the action's reason codes
+func (a *ModerationAction) ReasonList() []string {
+	return decodeStringList(a.Reasons)
+}
@@ -16,6 +16,7 @@ type NewsPost struct {
 	CommentsCount int            `gorm:"default:0" json:"commentsCount"`
 	LikesCount    int            `gorm:"default:0" json:"likesCount"`
 	SharesCount   int            `gorm:"default:0" json:"sharesCount"`
+	HiddenAt      *time.Time     `gorm:"index" json:"-"` // Hidden pending review after too many reports
 	CreatedAt     time.Time      `json:"createdAt"`
 	UpdatedAt     time.Time      `json:"updatedAt"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
@@ -30,10 +31,11 @@ type NewsLike struct {
 
 // NewsComment — post comment
 type NewsComment struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	PostID    uint      `gorm:"not null;index" json:"postId"`
-	UserID    uint      `gorm:"not null;index" json:"userId"`
-	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Text      string    `gorm:"type:text;not null" json:"text"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        uint       `gorm:"primarykey" json:"id"`
+	PostID    uint       `gorm:"not null;index" json:"postId"`
+	UserID    uint       `gorm:"not null;index" json:"userId"`
+	User      User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Text      string     `gorm:"type:text;not null" json:"text"`
+	HiddenAt  *time.Time `gorm:"index" json:"-"` // Hidden pending review after too many reports
+	CreatedAt time.Time  `json:"createdAt"`
 }
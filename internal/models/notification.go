@@ -4,15 +4,16 @@ import "time"
 
 // Notification — user notification
 type Notification struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	UserID    uint      `gorm:"not null;index" json:"userId"`
-	User      User      `gorm:"foreignKey:UserID" json:"-"`
-	Title     string    `gorm:"not null" json:"title"`
-	Body      string    `gorm:"type:text;not null" json:"body"`
-	Type      string    `gorm:"not null" json:"type"` // app_moderation, security, system, transaction, promotion
-	IsRead    bool      `gorm:"default:false" json:"isRead"`
-	ActionURL string    `json:"actionUrl,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"userId"`
+	User      User       `gorm:"foreignKey:UserID" json:"-"`
+	Title     string     `gorm:"not null" json:"title"`
+	Body      string     `gorm:"type:text;not null" json:"body"`
+	Type      string     `gorm:"not null" json:"type"` // app_moderation, app_message, security, system, transaction, promotion
+	IsRead    bool       `gorm:"default:false" json:"isRead"`
+	ActionURL string     `json:"actionUrl,omitempty"`
+	PushedAt  *time.Time `gorm:"index" json:"-"` // Set once jobs.PushNotifications handled it
+	CreatedAt time.Time  `json:"createdAt"`
 }
 
 // PushToken — FCM/APNS push token registration
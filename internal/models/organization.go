@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrgRole — role of a member within an organization. What each role may do on
+// the organization's apps:
+//
+//	analyst   — view apps, analytics, invoices, releases and reviews
+//	developer — edit listings, releases, bot settings, webhooks, posts and review replies
+//	admin     — API credentials, refunds, adding apps, managing members and invites
+//	owner     — deleting apps and the organization, managing other owners
+type OrgRole string
+
+const (
+	OrgRoleOwner     OrgRole = "owner"
+	OrgRoleAdmin     OrgRole = "admin"
+	OrgRoleDeveloper OrgRole = "developer"
+	OrgRoleAnalyst   OrgRole = "analyst"
+)
+
+// Roles from the least to the most privileged
+var orgRoleOrder = []OrgRole{OrgRoleAnalyst, OrgRoleDeveloper, OrgRoleAdmin, OrgRoleOwner}
+
+func (r OrgRole) rank() int {
+	for i, role := range orgRoleOrder {
+		if role == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Valid reports whether r is a known role
+func (r OrgRole) Valid() bool {
+	return r.rank() >= 0
+}
+
+// AtLeast reports whether r grants everything min does
+func (r OrgRole) AtLeast(min OrgRole) bool {
+	return r.Valid() && r.rank() >= min.rank()
+}
+
+// OrgRolesAtLeast lists the roles that grant everything min does
+func OrgRolesAtLeast(min OrgRole) []OrgRole {
+	var roles []OrgRole
+	for _, role := range orgRoleOrder {
+		if role.AtLeast(min) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Organization — developer team that owns apps (MiniApp.OrganizationID)
+type Organization struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	Name      string         `gorm:"not null" json:"name"`
+	CreatedBy uint           `gorm:"not null" json:"createdBy"`
+	Members   []OrgMember    `gorm:"foreignKey:OrganizationID" json:"members,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// OrgMember — user's membership and role in an organization
+type OrgMember struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_org_member" json:"organizationId"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_org_member;index" json:"userId"`
+	User           User      `gorm:"foreignKey:UserID" json:"-"`
+	Role           OrgRole   `gorm:"not null" json:"role"`
+	InvitedBy      *uint     `json:"invitedBy,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Organization invite statuses
+const (
+	OrgInvitePending  = "pending"
+	OrgInviteAccepted = "accepted"
+	OrgInviteDeclined = "declined"
+	OrgInviteRevoked  = "revoked"
+)
+
+// OrgInvite — invitation to join an organization, addressed to an email. The
+// account signed in with that email accepts it.
+type OrgInvite struct {
+	ID             uint         `gorm:"primarykey" json:"id"`
+	OrganizationID uint         `gorm:"not null;index" json:"organizationId"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID" json:"-"`
+	Email          string       `gorm:"not null;index" json:"email"`
+	Role           OrgRole      `gorm:"not null" json:"role"`
+	Status         string       `gorm:"default:pending;index" json:"status"`
+	InvitedBy      uint         `gorm:"not null" json:"invitedBy"`
+	ExpiresAt      time.Time    `json:"expiresAt"`
+	RespondedAt    *time.Time   `json:"respondedAt,omitempty"`
+	CreatedAt      time.Time    `json:"createdAt"`
+}
+
+// IsOpen reports whether the invite can still be accepted
+func (i OrgInvite) IsOpen() bool {
+	return i.Status == OrgInvitePending && time.Now().Before(i.ExpiresAt)
+}
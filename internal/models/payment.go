@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Invoice statuses
+const (
+	InvoicePending  = "pending"
+	InvoicePaid     = "paid"
+	InvoiceRefunded = "refunded"
+	InvoiceCanceled = "canceled"
+)
+
+// Invoice — payment request sent by a bot to a user, priced in Mana Points
+type Invoice struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	AppID         uint       `gorm:"not null;index" json:"appId"`
+	App           MiniApp    `gorm:"foreignKey:AppID" json:"-"`
+	UserID        uint       `gorm:"not null;index" json:"userId"`
+	User          User       `gorm:"foreignKey:UserID" json:"-"`
+	MessageID     uint       `json:"messageId"` // AppMessage carrying the invoice
+	Title         string     `gorm:"not null" json:"title"`
+	Description   string     `gorm:"type:text" json:"description"`
+	Amount        int        `gorm:"not null" json:"amount"`              // Mana Points
+	Payload       string     `gorm:"type:text" json:"payload,omitempty"`  // Opaque developer data
+	Status        string     `gorm:"default:pending;index" json:"status"` // pending, paid, refunded, canceled
+	TransactionID *uint      `json:"transactionId,omitempty"`             // ManaTransaction debiting the user
+	PaidAt        *time.Time `json:"paidAt,omitempty"`
+	RefundedAt    *time.Time `json:"refundedAt,omitempty"`
+	RefundReason  string     `json:"refundReason,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// BotEvent — event queued for delivery to a bot (webhook or getUpdates)
+type BotEvent struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	AppID       uint       `gorm:"not null;index" json:"appId"`
+	Event       string     `gorm:"not null" json:"event"` // payment.succeeded, payment.refunded
+	Payload     string     `gorm:"type:jsonb" json:"payload"`
+	Delivered   bool       `gorm:"default:false;index" json:"delivered"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
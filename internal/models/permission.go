@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Mini-app permission keys
+const (
+	PermissionProfile       = "profile"
+	PermissionEmail         = "email"
+	PermissionWalletAddress = "wallet_address"
+	PermissionNotifications = "notifications"
+	PermissionPayments      = "payments"
+)
+
+// PermissionInfo — entry of the permission catalogue shown to users
+type PermissionInfo struct {
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// PermissionCatalogue lists every permission a mini-app can request
+var PermissionCatalogue = []PermissionInfo{
+	{Key: PermissionProfile, Title: "Profile", Description: "Display name, avatar and language"},
+	{Key: PermissionEmail, Title: "Email", Description: "Email address of the account"},
+	{Key: PermissionWalletAddress, Title: "Wallet address", Description: "Public Solana wallet address"},
+	{Key: PermissionNotifications, Title: "Notifications", Description: "Send push notifications"},
+	{Key: PermissionPayments, Title: "Payments", Description: "Request payments in Mana Points"},
+}
+
+// IsValidPermission reports whether key is part of the catalogue
+func IsValidPermission(key string) bool {
+	for _, p := range PermissionCatalogue {
+		if p.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AppPermissionGrant — permission granted by a user to a mini-app
+type AppPermissionGrant struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_grant_user_app_perm" json:"userId"`
+	User       User      `gorm:"foreignKey:UserID" json:"-"`
+	AppID      uint      `gorm:"not null;uniqueIndex:idx_grant_user_app_perm;index" json:"appId"`
+	App        MiniApp   `gorm:"foreignKey:AppID" json:"-"`
+	Permission string    `gorm:"not null;uniqueIndex:idx_grant_user_app_perm" json:"permission"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// DeclaredPermissions returns the permissions declared by the app developer
+func (a *MiniApp) DeclaredPermissions() []string {
+	perms := []string{}
+	if a.Permissions == "" {
+		return perms
+	}
+	var raw []string
+	if err := json.Unmarshal([]byte(a.Permissions), &raw); err != nil {
+		return perms
+	}
+	for _, p := range raw {
+		if IsValidPermission(p) {
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}
+
+// DeclaresPermission reports whether the app has declared the given permission
+func (a *MiniApp) DeclaresPermission(key string) bool {
+	for _, p := range a.DeclaredPermissions() {
+		if p == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Recommendation reasons
+const (
+	RecommendCoUsage  = "co_usage" // Users who use SourceAppID also use this app
+	RecommendCategory = "category" // Category the user already uses
+	RecommendLanguage = "language" // Popular among users with the same language
+	RecommendPopular  = "popular"  // Popularity fallback
+)
+
+// AppBlock — app hidden by a user from recommendations
+type AppBlock struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_block_user_app" json:"userId"`
+	AppID     uint      `gorm:"not null;uniqueIndex:idx_block_user_app" json:"appId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AppRecommendation — precomputed recommendation, rebuilt by jobs.ComputeRecommendations
+type AppRecommendation struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_rec_user_app" json:"userId"`
+	AppID       uint      `gorm:"not null;uniqueIndex:idx_rec_user_app" json:"appId"`
+	App         MiniApp   `gorm:"foreignKey:AppID" json:"-"`
+	Score       float64   `gorm:"not null" json:"score"`
+	Reason      string    `gorm:"not null" json:"reason"` // co_usage, category, language, popular
+	SourceAppID *uint     `json:"sourceAppId,omitempty"`  // App behind a co_usage recommendation
+	CreatedAt   time.Time `json:"createdAt"`
+}
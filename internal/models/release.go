@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Release statuses
+const (
+	ReleaseDraft    = "draft"
+	ReleasePending  = "pending" // Submitted, waiting for moderation
+	ReleaseApproved = "approved"
+	ReleaseRejected = "rejected"
+)
+
+// AppRelease — a version of an app's listing. Developers edit a draft, submit it
+// for review, and the approved release replaces the live listing; until then the
+// previous approved release stays published.
+type AppRelease struct {
+	ID      uint    `gorm:"primarykey" json:"id"`
+	AppID   uint    `gorm:"not null;index;uniqueIndex:idx_release_app_version" json:"appId"`
+	App     MiniApp `gorm:"foreignKey:AppID" json:"-"`
+	Version string  `gorm:"not null;uniqueIndex:idx_release_app_version" json:"version"`
+	Status  string  `gorm:"default:draft;index" json:"status"` // draft, pending, approved, rejected
+
+	// Listing snapshot
+	Title           string `gorm:"not null" json:"title"`
+	Subtitle        string `json:"subtitle"`
+	Description     string `gorm:"type:text" json:"description"`
+	LongDescription string `gorm:"type:text" json:"longDescription,omitempty"`
+	Icon            string `json:"icon"`
+	IconURL         string `json:"iconUrl,omitempty"`
+	URL             string `json:"url,omitempty"`
+	CategoryID      uint   `json:"categoryId"`
+	Tags            string `gorm:"type:text" json:"tags,omitempty"`        // JSON, as in MiniApp
+	Screenshots     string `gorm:"type:text" json:"screenshots,omitempty"` // JSON, as in MiniApp
+	Permissions     string `gorm:"type:text" json:"permissions,omitempty"` // JSON, as in MiniApp
+
+	Changelog     string     `gorm:"type:text" json:"changelog"`
+	ReviewNote    string     `gorm:"type:text" json:"reviewNote,omitempty"`
+	ReviewReasons string     `gorm:"type:text" json:"-"` // Rejection reason codes, see ModerationReasons
+	CreatedBy     uint       `json:"createdBy"`
+	ReviewedBy    *uint      `json:"reviewedBy,omitempty"`
+	ClaimedBy     *uint      `gorm:"index" json:"claimedBy,omitempty"` // Moderator working on the review
+	ClaimedAt     *time.Time `json:"claimedAt,omitempty"`
+	RiskFlags     string     `gorm:"type:text" json:"-"`               // JSON list of RiskFlag from the automated checks
+	CheckedAt     *time.Time `gorm:"index" json:"checkedAt,omitempty"` // Nil until the automated checks ran
+	SubmittedAt   *time.Time `json:"submittedAt,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	PublishedAt   *time.Time `json:"publishedAt,omitempty"` // Last time this release went live
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// ReasonList returns the reason codes of the last rejection
+func (r *AppRelease) ReasonList() []string {
+	return decodeStringList(r.ReviewReasons)
+}
+
+// FlagList returns the findings of the automated checks
+func (r *AppRelease) FlagList() []RiskFlag {
+	flags := []RiskFlag{}
+	if r.RiskFlags != "" {
+		json.Unmarshal([]byte(r.RiskFlags), &flags)
+	}
+	return flags
+}
+
+// ClaimedByOther reports whether another moderator holds a live claim on the release
+func (r *AppRelease) ClaimedByOther(userID uint, now time.Time) bool {
+	return r.ClaimedBy != nil && *r.ClaimedBy != userID && r.ClaimedAt != nil &&
+		now.Before(r.ClaimedAt.Add(ModerationClaimTTL))
+}
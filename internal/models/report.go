@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Report target types
+const (
+	ReportTargetApp     = "app"
+	ReportTargetPost    = "post"    // NewsPost
+	ReportTargetComment = "comment" // NewsComment
+	ReportTargetMessage = "message" // ChatMessage sent by a bot
+	ReportTargetReview  = "review"  // AppReview
+)
+
+// ReportReasons — reasons a user can pick when reporting content
+var ReportReasons = map[string]bool{
+	"spam": true, "scam": true, "offensive": true, "harassment": true,
+	"sexual_content": true, "violence": true, "illegal": true, "off_topic": true, "other": true,
+}
+
+// ReportAutoHide — distinct reporters after which content is hidden until an
+// admin reviews the reports
+var ReportAutoHide = map[string]int{
+	ReportTargetApp:     10,
+	ReportTargetPost:    5,
+	ReportTargetComment: 3,
+	ReportTargetMessage: 3,
+	ReportTargetReview:  3,
+}
+
+// Report statuses
+const (
+	ReportOpen     = "open"
+	ReportResolved = "resolved"
+)
+
+// Triage actions an admin resolves the reports on a target with
+const (
+	ReportActionDismiss    = "dismiss"     // Nothing wrong; hidden content is shown again
+	ReportActionRemove     = "remove"      // Delete the content
+	ReportActionSuspendApp = "suspend_app" // Take down the app the content belongs to
+	ReportActionBanUser    = "ban_user"    // Ban the content's author
+)
+
+// ContentReport — a user's abuse report on an app, post, comment, bot message or
+// review; one per reporter and target
+type ContentReport struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	TargetType  string     `gorm:"not null;uniqueIndex:idx_content_report;index:idx_report_target" json:"targetType"`
+	TargetID    uint       `gorm:"not null;uniqueIndex:idx_content_report;index:idx_report_target" json:"targetId"`
+	ReporterID  uint       `gorm:"not null;uniqueIndex:idx_content_report" json:"reporterId"`
+	Reporter    User       `gorm:"foreignKey:ReporterID" json:"-"`
+	Reason      string     `gorm:"not null" json:"reason"`
+	Details     string     `gorm:"type:text" json:"details,omitempty"`
+	Status      string     `gorm:"default:open;index" json:"status"`
+	Resolution  string     `json:"resolution,omitempty"` // Triage action
+	ResolveNote string     `gorm:"type:text" json:"resolveNote,omitempty"`
+	ResolvedBy  *uint      `json:"resolvedBy,omitempty"`
+	ResolvedAt  *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
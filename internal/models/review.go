@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// AppReview — a user's rating and review of an app, one per user per app
+type AppReview struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	AppID          uint       `gorm:"not null;uniqueIndex:idx_review_app_user" json:"appId"`
+	App            MiniApp    `gorm:"foreignKey:AppID" json:"-"`
+	UserID         uint       `gorm:"not null;uniqueIndex:idx_review_app_user;index" json:"userId"`
+	User           User       `gorm:"foreignKey:UserID" json:"-"`
+	Rating         int        `gorm:"not null" json:"rating"` // 1–5
+	Text           string     `gorm:"type:text" json:"text,omitempty"`
+	HelpfulCount   int        `gorm:"default:0" json:"helpfulCount"`
+	ReportsCount   int        `gorm:"default:0" json:"reportsCount"` // Reports filed, see ContentReport
+	HiddenAt       *time.Time `gorm:"index" json:"-"`                // Hidden pending review after too many reports
+	EditedAt       *time.Time `json:"editedAt,omitempty"`
+	DeveloperReply string     `gorm:"type:text" json:"developerReply,omitempty"`
+	RepliedAt      *time.Time `json:"repliedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// AppReviewRevision — previous version of an edited review
+type AppReviewRevision struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ReviewID  uint      `gorm:"not null;index" json:"reviewId"`
+	Rating    int       `gorm:"not null" json:"rating"`
+	Text      string    `gorm:"type:text" json:"text,omitempty"`
+	CreatedAt time.Time `json:"createdAt"` // When this version was replaced
+}
+
+// ReviewHelpfulVote — a user marking a review as helpful
+type ReviewHelpfulVote struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ReviewID  uint      `gorm:"not null;uniqueIndex:idx_helpful_review_user" json:"reviewId"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_helpful_review_user" json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
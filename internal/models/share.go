@@ -0,0 +1,50 @@
+package models
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// Share link targets
+const (
+	ShareTargetApp  = "app"
+	ShareTargetPost = "post"
+)
+
+// ShareLink — short code pointing at an app or a news post. Each user gets their
+// own code per target so clicks can be attributed to the sharer.
+type ShareLink struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Code        string    `gorm:"uniqueIndex;not null" json:"code"`
+	TargetType  string    `gorm:"not null;uniqueIndex:idx_share_target_user" json:"targetType"` // app, post
+	TargetID    uint      `gorm:"not null;uniqueIndex:idx_share_target_user" json:"targetId"`
+	CreatedBy   uint      `gorm:"not null;uniqueIndex:idx_share_target_user" json:"createdBy"`
+	AppID       uint      `gorm:"not null;index" json:"appId"` // The app itself, or the app that posted
+	ClicksCount int       `gorm:"default:0" json:"clicksCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ShareClick — one open of a share link. Crawlers fetching previews and repeat
+// opens by the same user or IP within a day are not counted.
+type ShareClick struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	LinkID    uint      `gorm:"not null;index:idx_share_click_link_date" json:"linkId"`
+	AppID     uint      `gorm:"not null;index:idx_share_click_app_date" json:"appId"`
+	UserID    *uint     `json:"userId,omitempty"` // Set when opened from the app by a signed-in user
+	IP        string    `json:"ip,omitempty"`
+	Source    string    `json:"source"` // web, app
+	UserAgent string    `json:"userAgent,omitempty"`
+	CreatedAt time.Time `gorm:"index:idx_share_click_app_date;index:idx_share_click_link_date" json:"createdAt"`
+}
+
+const shareCodeAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GenerateShareCode creates a random 8-character code without look-alike characters
+func GenerateShareCode() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	for i := range b {
+		b[i] = shareCodeAlphabet[int(b[i])%len(shareCodeAlphabet)]
+	}
+	return string(b)
+}
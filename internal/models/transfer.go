@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// App transfer statuses
+const (
+	TransferPending  = "pending"
+	TransferAccepted = "accepted"
+	TransferDeclined = "declined"
+	TransferCanceled = "canceled"
+	TransferExpired  = "expired"
+)
+
+// AppTransfer — handover of an app to another account. The recipient accepts it
+// before ExpiresAt; the app then becomes their personal app with new credentials.
+type AppTransfer struct {
+	ID                 uint       `gorm:"primarykey" json:"id"`
+	AppID              uint       `gorm:"not null;index" json:"appId"`
+	App                MiniApp    `gorm:"foreignKey:AppID" json:"-"`
+	FromUserID         uint       `gorm:"not null;index" json:"fromUserId"`
+	FromUser           User       `gorm:"foreignKey:FromUserID" json:"-"`
+	FromOrganizationID *uint      `json:"fromOrganizationId,omitempty"` // Owning organization when initiated
+	ToUserID           uint       `gorm:"not null;index" json:"toUserId"`
+	ToUser             User       `gorm:"foreignKey:ToUserID" json:"-"`
+	ToEmail            string     `gorm:"not null" json:"toEmail"`
+	Status             string     `gorm:"default:pending;index" json:"status"`
+	ExpiresAt          time.Time  `gorm:"index" json:"expiresAt"`
+	RespondedAt        *time.Time `json:"respondedAt,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+}
+
+// AppTransferStatusChange — audit trail of an app transfer
+type AppTransferStatusChange struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	TransferID uint      `gorm:"not null;index" json:"transferId"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `gorm:"not null" json:"toStatus"`
+	ChangedBy  *uint     `json:"changedBy,omitempty"` // Nil when the transfer expired
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
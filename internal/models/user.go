@@ -17,6 +17,7 @@ type User struct {
 	HasSecretAccess bool           `gorm:"default:false" json:"hasSecretAccess"`
 	Language        string         `gorm:"default:en" json:"language"`
 	ReferralCode    string         `gorm:"uniqueIndex" json:"referralCode"`
+	WalletAddress   string         `json:"walletAddress,omitempty"`
 
 	// Settings
 	NotificationsEnabled bool `gorm:"default:true" json:"notificationsEnabled"`
@@ -27,11 +28,53 @@ type User struct {
 	MarketingEmails      bool `gorm:"default:false" json:"marketingEmails"`
 	BiometricEnabled     bool `gorm:"default:false" json:"biometricEnabled"`
 
+	// Platform role; admins moderate apps and manage the catalog
+	Role string `gorm:"default:user;index" json:"role"`
+
+	// Account standing; suspended and banned accounts can't sign in or use the API
+	Status         string     `gorm:"default:active;index" json:"status"`
+	StatusReason   string     `json:"statusReason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"` // Set while Status is suspended
+	// Access tokens issued before this are rejected (signed out everywhere)
+	SessionsRevokedAt *time.Time `json:"-"`
+
+	// Developer verification (see DeveloperVerification)
+	IsVerifiedDeveloper bool   `gorm:"default:false" json:"isVerifiedDeveloper"`
+	VerifiedOrgName     string `json:"verifiedOrgName,omitempty"`
+
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// User roles
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+// Account statuses
+const (
+	AccountActive    = "active"
+	AccountSuspended = "suspended"
+	AccountBanned    = "banned"
+)
+
+// Sanction returns the status keeping the account out at now: AccountSuspended,
+// AccountBanned, or "" when it may sign in. A suspension lapses on its own once
+// SuspendedUntil passes.
+func (u *User) Sanction(now time.Time) string {
+	switch u.Status {
+	case AccountBanned:
+		return AccountBanned
+	case AccountSuspended:
+		if u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil) {
+			return AccountSuspended
+		}
+	}
+	return ""
+}
+
 // GetDisplayName returns DisplayName or Name
 func (u *User) GetDisplayName() string {
 	if u.DisplayName != "" {
@@ -70,7 +113,7 @@ type ManaTransaction struct {
 	UserID      uint      `gorm:"not null;index" json:"userId"`
 	User        User      `gorm:"foreignKey:UserID" json:"-"`
 	Amount      int       `gorm:"not null" json:"amount"` // positive = credit, negative = debit
-	Type        string    `gorm:"not null" json:"type"`   // topup, purchase, reward, refund
+	Type        string    `gorm:"not null" json:"type"`   // topup, purchase, reward, refund, app_payment
 	Description string    `json:"description"`
 	ReferenceID *uint     `json:"referenceId,omitempty"` // Related entity ID (app, secret number, etc.)
 	CreatedAt   time.Time `json:"createdAt"`
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Developer verification statuses
+const (
+	VerificationAwaitingProof = "awaiting_proof" // Domain token not found yet
+	VerificationPendingReview = "pending_review" // Domain proven, waiting for an admin
+	VerificationApproved      = "approved"
+	VerificationRejected      = "rejected"
+	VerificationRevoked       = "revoked"
+	VerificationCanceled      = "canceled"
+)
+
+// Domain proof methods
+const (
+	ProofDNSTXT    = "dns_txt"
+	ProofWellKnown = "well_known"
+)
+
+// DeveloperVerification — request by a developer to get the verified badge
+type DeveloperVerification struct {
+	ID               uint       `gorm:"primarykey" json:"id"`
+	UserID           uint       `gorm:"not null;index" json:"userId"`
+	User             User       `gorm:"foreignKey:UserID" json:"-"`
+	OrgName          string     `gorm:"not null" json:"orgName"`
+	Website          string     `gorm:"not null" json:"website"`
+	Domain           string     `gorm:"not null;index" json:"domain"`
+	Token            string     `gorm:"not null" json:"-"`
+	ProofMethod      string     `json:"proofMethod,omitempty"` // dns_txt, well_known
+	DomainVerifiedAt *time.Time `json:"domainVerifiedAt,omitempty"`
+	LastCheckError   string     `json:"lastCheckError,omitempty"`
+	Status           string     `gorm:"default:awaiting_proof;index" json:"status"`
+	ReviewNote       string     `gorm:"type:text" json:"reviewNote,omitempty"`
+	ReviewedBy       *uint      `json:"reviewedBy,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+// GenerateVerificationToken creates the token the developer publishes on their domain
+func GenerateVerificationToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,121 @@
+// Package notify creates the in-app notifications about a developer's apps.
+// Notifications are stored rows; jobs.PushNotifications delivers them as push
+// messages where push is configured.
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fasad/solanafon-back/internal/models"
+	"github.com/fasad/solanafon-back/internal/publicid"
+	"gorm.io/gorm"
+)
+
+// Notification types
+const (
+	TypeAppModeration = "app_moderation"
+	TypeSecurity      = "security"
+	TypeSystem        = "system"
+)
+
+// AppTeam notifies everyone holding at least min on the app: the creator of a
+// personal app, or the organization's members with that role
+func AppTeam(db *gorm.DB, app models.MiniApp, min models.OrgRole, n models.Notification) error {
+	userIDs := []uint{app.CreatorID}
+	if app.OrganizationID != nil {
+		userIDs = nil
+		if err := db.Model(&models.OrgMember{}).
+			Where("organization_id = ? AND role IN ?", *app.OrganizationID, models.OrgRolesAtLeast(min)).
+			Pluck("user_id", &userIDs).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, userID := range userIDs {
+		n.ID, n.UserID = 0, userID
+		if err := db.Create(&n).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseDecision tells the app's developers that a release was approved or
+// rejected, with the moderator's note and reasons. firstRelease is set when the
+// app wasn't live before the decision.
+func ReleaseDecision(db *gorm.DB, app models.MiniApp, release models.AppRelease, firstRelease bool) error {
+	n := models.Notification{
+		Type:      TypeAppModeration,
+		ActionURL: AppURL(app.ID) + "/releases/" + publicid.Format(publicid.Release, release.ID),
+	}
+
+	if release.Status == models.ReleaseApproved {
+		n.Title = fmt.Sprintf("%s %s is live", app.Title, release.Version)
+		n.Body = "Your release was approved and is now in the marketplace."
+		if firstRelease {
+			n.Title = fmt.Sprintf("%s was approved", app.Title)
+			n.Body = "Your app was approved and is now listed in the marketplace."
+		}
+		if release.ReviewNote != "" {
+			n.Body += "\n\nModerator note: " + release.ReviewNote
+		}
+		return AppTeam(db, app, models.OrgRoleDeveloper, n)
+	}
+
+	n.Title = fmt.Sprintf("%s %s was rejected", app.Title, release.Version)
+	if firstRelease {
+		n.Body = "Your app was rejected and isn't listed in the marketplace."
+	} else {
+		n.Body = "Your release was rejected. The current version stays live."
+	}
+	if labels := reasonLabels(release.ReasonList()); labels != "" {
+		n.Body += "\n\nReasons: " + labels
+	}
+	if release.ReviewNote != "" {
+		n.Body += "\n\n" + release.ReviewNote
+	}
+	n.Body += "\n\nUpdate the listing and submit it again."
+	return AppTeam(db, app, models.OrgRoleDeveloper, n)
+}
+
+// WebhookDisabled tells the app's developers that deliveries to their webhook
+// kept failing and it was turned off
+func WebhookDisabled(db *gorm.DB, app models.MiniApp) error {
+	return AppTeam(db, app, models.OrgRoleDeveloper, models.Notification{
+		Type:  TypeSystem,
+		Title: fmt.Sprintf("Webhook disabled for %s", app.Title),
+		Body: fmt.Sprintf("The last %d deliveries to %s failed, so we stopped sending events to it. "+
+			"Your bot can still receive events through getUpdates. Set the webhook again once it is fixed.",
+			models.WebhookMaxFailures, app.WebhookURL),
+		ActionURL: AppURL(app.ID) + "/webhook",
+	})
+}
+
+// APIKeyExpiring warns the app's admins that its API key expires soon
+func APIKeyExpiring(db *gorm.DB, app models.MiniApp) error {
+	return AppTeam(db, app, models.OrgRoleAdmin, models.Notification{
+		Type:  TypeSecurity,
+		Title: fmt.Sprintf("API key for %s expires soon", app.Title),
+		Body: fmt.Sprintf("The API key expires on %s. Generate a new one before then to keep your bot running.",
+			app.APITokenExpiresAt.Format("2006-01-02 15:04 MST")),
+		ActionURL: AppURL(app.ID) + "/api-key",
+	})
+}
+
+// AppURL is the deep link to an app in the developer section
+func AppURL(appID uint) string {
+	return "solafon://developer/apps/" + publicid.Format(publicid.App, appID)
+}
+
+func reasonLabels(codes []string) string {
+	labels := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if label, ok := models.ModerationReasons[code]; ok {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, "; ")
+}
@@ -0,0 +1,193 @@
+// Package precheck runs the automated checks on a submitted app listing before
+// a moderator sees it: URL schemes, reachability and TLS, and banned terms.
+package precheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fasad/solanafon-back/internal/models"
+)
+
+// errPrivateAddress is returned by the default client's dialer for addresses
+// inside our network, so submissions can't make us probe internal services
+var errPrivateAddress = errors.New("address is not publicly routable")
+
+// Submission — the listing fields the checks look at
+type Submission struct {
+	Title           string
+	Subtitle        string
+	Description     string
+	LongDescription string
+	URL             string
+	WebhookURL      string
+}
+
+// Checker runs the checks. The HTTP client is injectable so tests and
+// development setups can stub out the network.
+type Checker struct {
+	client *http.Client
+	banned *regexp.Regexp // Nil when no terms are configured
+}
+
+// New returns a checker using client for reachability checks and matching
+// bannedTerms as whole words, case-insensitively
+func New(client *http.Client, bannedTerms []string) *Checker {
+	c := &Checker{client: client}
+	var quoted []string
+	for _, term := range bannedTerms {
+		if term = strings.TrimSpace(term); term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+	if len(quoted) > 0 {
+		c.banned = regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(` + strings.Join(quoted, "|") + `)(?:$|[^\p{L}\p{N}])`)
+	}
+	return c
+}
+
+// NewHTTPClient returns a client with a short timeout that refuses to connect to
+// loopback, private and link-local addresses, including after redirects
+func NewHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// Check runs every check on the submission and returns the findings
+func (c *Checker) Check(ctx context.Context, s Submission) []models.RiskFlag {
+	var flags []models.RiskFlag
+	if s.URL != "" {
+		flags = append(flags, c.checkURL(ctx, "url", s.URL, http.MethodGet)...)
+	}
+	if s.WebhookURL != "" {
+		flags = append(flags, c.checkURL(ctx, "webhookUrl", s.WebhookURL, http.MethodHead)...)
+	}
+	flags = append(flags, c.checkTerms(map[string]string{
+		"name": s.Title, "subtitle": s.Subtitle,
+		"description": s.Description, "longDescription": s.LongDescription,
+	})...)
+	return flags
+}
+
+// checkURL validates the scheme and host, then requests the URL. The app URL
+// must answer successfully over valid TLS since clients open it in a webview; a
+// webhook only has to accept connections, as it expects signed POSTs.
+func (c *Checker) checkURL(ctx context.Context, field, raw, method string) []models.RiskFlag {
+	prefix := "url"
+	if field == "webhookUrl" {
+		prefix = "webhook"
+	}
+	flag := func(code, severity, reason, format string, args ...interface{}) []models.RiskFlag {
+		return []models.RiskFlag{{
+			Code: prefix + "_" + code, Severity: severity, Field: field,
+			Message: fmt.Sprintf(format, args...), Reason: reason,
+		}}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return flag("scheme", models.RiskBlocking, "broken", "%s must be an http(s) URL with a host", field)
+	}
+	host := strings.ToLower(u.Hostname())
+	if ip := net.ParseIP(host); host == "localhost" || strings.HasSuffix(host, ".localhost") || (ip != nil && !isPublic(ip)) {
+		return flag("private_host", models.RiskBlocking, "malware", "%s points to a private or local address", field)
+	}
+
+	var flags []models.RiskFlag
+	if u.Scheme == "http" {
+		flags = append(flags, flag("insecure", models.RiskWarning, "privacy", "%s doesn't use HTTPS", field)...)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return append(flags, flag("scheme", models.RiskBlocking, "broken", "%s is not a valid URL", field)...)
+	}
+	resp, err := c.client.Do(req)
+	switch {
+	case errors.Is(err, errPrivateAddress):
+		return append(flags, flag("private_host", models.RiskBlocking, "malware", "%s resolves to a private or local address", field)...)
+	case isTLSError(err):
+		severity := models.RiskWarning
+		if prefix == "url" {
+			severity = models.RiskBlocking
+		}
+		return append(flags, flag("tls", severity, "broken", "%s has an invalid TLS certificate: %v", field, err)...)
+	case err != nil:
+		return append(flags, flag("unreachable", models.RiskWarning, "broken", "%s could not be reached: %v", field, err)...)
+	}
+	resp.Body.Close()
+	if prefix == "url" && resp.StatusCode >= 400 {
+		flags = append(flags, flag("unreachable", models.RiskWarning, "broken", "%s returned HTTP %d", field, resp.StatusCode)...)
+	}
+	return flags
+}
+
+// checkTerms flags each field containing a banned term
+func (c *Checker) checkTerms(fields map[string]string) []models.RiskFlag {
+	if c.banned == nil {
+		return nil
+	}
+	var flags []models.RiskFlag
+	for _, field := range []string{"name", "subtitle", "description", "longDescription"} {
+		if m := c.banned.FindStringSubmatch(fields[field]); m != nil {
+			flags = append(flags, models.RiskFlag{
+				Code: "banned_term", Severity: models.RiskBlocking, Field: field,
+				Message: fmt.Sprintf("%s contains the banned term %q", field, m[1]), Reason: "inappropriate_content",
+			})
+		}
+	}
+	return flags
+}
+
+// Blocking returns the flags that get a release rejected automatically
+func Blocking(flags []models.RiskFlag) []models.RiskFlag {
+	var blocking []models.RiskFlag
+	for _, f := range flags {
+		if f.Severity == models.RiskBlocking {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
+}
+
+func isPublic(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast())
+}
+
+func isTLSError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var verifyErr *tls.CertificateVerificationError
+	var hostErr x509.HostnameError
+	var authErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &verifyErr) || errors.As(err, &hostErr) ||
+		errors.As(err, &authErr) || errors.As(err, &invalidErr)
+}
@@ -0,0 +1,66 @@
+// Package publicid converts between database IDs and the typed public IDs used
+// by the v2 API ("app_12", "conv_5", "msg_9").
+package publicid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Public ID kinds (the prefix before the underscore)
+const (
+	App            = "app"
+	Developer      = "dev"
+	User           = "user"
+	Conversation   = "conv"
+	Message        = "msg"
+	Post           = "post"
+	Comment        = "comment"
+	Notification   = "notif"
+	Session        = "sess"
+	Room           = "room"
+	Participant    = "part"
+	Invoice        = "inv"
+	Payout         = "payout"
+	Earning        = "earn"
+	Release        = "rel"
+	Review         = "rev"
+	APIKey         = "key"
+	WelcomeMessage = "wm"
+	Category       = "cat"
+	Verification   = "ver"
+	Organization   = "org"
+	OrgInvite      = "orginv"
+	Transfer       = "xfer"
+	Ticket         = "ticket"
+	FAQ            = "faq"
+	Crash          = "crash"
+	Transaction    = "tx"
+	Report         = "rpt"
+	Audit          = "audit"
+)
+
+// Format returns the public form of id, e.g. Format(App, 12) == "app_12"
+func Format(kind string, id uint) string {
+	return kind + "_" + strconv.FormatUint(uint64(id), 10)
+}
+
+// Parse returns the database ID behind a public ID of the given kind. An ID of
+// another kind ("conv_5" where an app is expected) is rejected. Bare numbers are
+// still accepted for clients that predate public IDs.
+func Parse(raw, kind string) (uint, error) {
+	raw = strings.TrimSpace(raw)
+	digits := raw
+	if prefix, rest, ok := strings.Cut(raw, "_"); ok {
+		if prefix != kind {
+			return 0, fmt.Errorf("expected a %s_ id, got %q", kind, raw)
+		}
+		digits = rest
+	}
+	id, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil || id == 0 || id > uint64(^uint(0)>>1) {
+		return 0, fmt.Errorf("invalid %s id %q", kind, raw)
+	}
+	return uint(id), nil
+}
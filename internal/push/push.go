@@ -0,0 +1,204 @@
+// Package push delivers push messages to devices registered through
+// /api/notifications/push-token.
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken means the device token is no longer valid and should be dropped
+var ErrInvalidToken = errors.New("push token is no longer registered")
+
+const messagingScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// Message — what a device shows
+type Message struct {
+	Title     string
+	Body      string
+	ActionURL string // Opened when the user taps the message
+}
+
+// Sender delivers a message to one device token
+type Sender interface {
+	Send(ctx context.Context, token string, m Message) error
+}
+
+// New returns the FCM sender for the service account key in credentialsFile, or
+// nil when push isn't configured
+func New(credentialsFile string) (Sender, error) {
+	if credentialsFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var account ServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", credentialsFile, err)
+	}
+	sender, err := NewFCMSender(&http.Client{Timeout: 10 * time.Second}, account)
+	if err != nil {
+		return nil, err
+	}
+	return sender, nil
+}
+
+// ServiceAccount — the fields of a Google service account key file that the
+// sender needs
+type ServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMSender sends through the Firebase Cloud Messaging HTTP v1 API, which also
+// reaches iOS devices registered with FCM. It authenticates as a service account
+// and reuses its OAuth access token until shortly before it expires.
+type FCMSender struct {
+	client   *http.Client
+	account  ServiceAccount
+	endpoint string
+	key      *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewFCMSender(client *http.Client, account ServiceAccount) (*FCMSender, error) {
+	if account.ProjectID == "" || account.ClientEmail == "" {
+		return nil, errors.New("service account key is missing project_id or client_email")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("service account private key: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &FCMSender{
+		client: client, account: account, key: key,
+		endpoint: "https://fcm.googleapis.com/v1/projects/" + url.PathEscape(account.ProjectID) + "/messages:send",
+	}, nil
+}
+
+func (s *FCMSender) Send(ctx context.Context, token string, m Message) error {
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": m.Title, "body": m.Body},
+			"data":         map[string]string{"actionUrl": m.ActionURL},
+		},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var result struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	for _, d := range result.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" || d.ErrorCode == "INVALID_ARGUMENT" {
+			return ErrInvalidToken
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		s.mu.Lock()
+		s.accessToken = ""
+		s.mu.Unlock()
+	}
+	return fmt.Errorf("fcm returned %d: %s %s", resp.StatusCode, result.Error.Status, result.Error.Message)
+}
+
+// token returns a cached access token, exchanging a freshly signed assertion for
+// a new one when it is about to expire
+func (s *FCMSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.accessToken != "" && now.Before(s.expiresAt.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.account.ClientEmail,
+		"scope": messagingScope,
+		"aud":   s.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token exchange returned %d", resp.StatusCode)
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("fcm token exchange returned no access token")
+	}
+	s.accessToken = result.AccessToken
+	s.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/domainproof"
 	"github.com/fasad/solanafon-back/internal/handlers"
 	"github.com/fasad/solanafon-back/internal/middleware"
 	"github.com/gofiber/fiber/v2"
@@ -13,18 +14,36 @@ func SetupAPI(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	// Initialize handlers
 	authV2 := handlers.NewAuthV2Handler(db, cfg)
 	users := handlers.NewUsersHandler(db)
-	convs := handlers.NewConversationsHandler(db)
+	convs := handlers.NewConversationsHandler(db, cfg)
 	wallet := handlers.NewWalletHandler(db, cfg)
-	notifications := handlers.NewNotificationsHandler(db)
-	news := handlers.NewNewsHandler(db)
+	notifications := handlers.NewNotificationsHandler(db, cfg)
+	news := handlers.NewNewsHandler(db, cfg)
 	calls := handlers.NewCallsHandler(db)
 	referral := handlers.NewReferralHandler(db)
 	support := handlers.NewSupportHandler(db)
 	crash := handlers.NewCrashHandler(db)
 	developer := handlers.NewDeveloperHandler(db, cfg)
+	permissions := handlers.NewPermissionsHandler(db)
+	payments := handlers.NewPaymentsHandler(db, cfg)
+	earnings := handlers.NewEarningsHandler(db, cfg)
+	analytics := handlers.NewAnalyticsHandler(db)
+	reviews := handlers.NewReviewsHandler(db)
+	recommendations := handlers.NewRecommendationsHandler(db)
+	releases := handlers.NewReleasesHandler(db)
+	library := handlers.NewLibraryHandler(db)
+	categories := handlers.NewCategoriesHandler(db)
+	share := handlers.NewShareHandler(db, cfg)
+	orgs := handlers.NewOrganizationsHandler(db, cfg)
+	transfers := handlers.NewTransfersHandler(db)
+	moderation := handlers.NewModerationHandler(db, cfg)
+	adminUsers := handlers.NewAdminHandler(db)
+	reports := handlers.NewReportsHandler(db)
+	audit := handlers.NewAuditHandler(db, cfg)
+	verification := handlers.NewVerificationHandler(db, domainproof.New(cfg.DomainResolver, cfg.DomainRecordsFile))
 
 	// Auth middleware
-	auth := middleware.AuthRequired(cfg.JWTSecret)
+	auth := middleware.AuthRequired(db, cfg.JWTSecret)
+	admin := middleware.AdminRequired(db, cfg.AdminEmails)
 
 	// ==================== AUTH (public) ====================
 	authGroup := api.Group("/auth")
@@ -41,7 +60,9 @@ func SetupAPI(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	usersGroup.Delete("/me", users.DeleteMe)
 	usersGroup.Get("/me/sessions", users.GetSessions)
 	usersGroup.Delete("/me/sessions/:sessionId", users.RevokeSession)
+	usersGroup.Get("/me/security-activity", audit.SecurityActivity)
 	usersGroup.Delete("/me/sessions", users.RevokeAllSessions)
+	usersGroup.Get("/me/app-permissions", permissions.ListMyGrants)
 
 	// ==================== CONVERSATIONS (protected) ====================
 	convsGroup := api.Group("/conversations", auth)
@@ -56,23 +77,99 @@ func SetupAPI(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	// ==================== APPS MARKETPLACE (protected) ====================
 	appsGroup := api.Group("/apps", auth)
 	appsGroup.Get("/", developer.ListApps)
-	appsGroup.Get("/categories", developer.GetCategories)
+	appsGroup.Get("/categories", categories.GetCategories)
+	appsGroup.Get("/permissions", permissions.GetCatalogue)
+	appsGroup.Get("/recommended", recommendations.GetRecommended)
+	appsGroup.Get("/tags", developer.ListTags)
+	appsGroup.Get("/recent", library.ListRecent)
+	appsGroup.Delete("/recent/:appId", library.HideRecent)
+	appsGroup.Get("/favorites", library.ListFavorites)
+	appsGroup.Get("/pinned", library.ListPinned)
+	appsGroup.Put("/pinned", library.SetPinned)
 	appsGroup.Get("/:appId", developer.GetAppDetail)
 	appsGroup.Post("/:appId/launch", developer.LaunchApp)
+	appsGroup.Post("/:appId/conversations", convs.StartConversation)
+	appsGroup.Get("/:appId/changelog", releases.GetChangelog)
+	appsGroup.Post("/:appId/share", share.ShareApp)
+	appsGroup.Post("/:appId/favorite", library.AddFavorite)
+	appsGroup.Delete("/:appId/favorite", library.RemoveFavorite)
+	appsGroup.Post("/:appId/block", recommendations.BlockApp)
+	appsGroup.Delete("/:appId/block", recommendations.UnblockApp)
+	appsGroup.Get("/:appId/reviews", reviews.ListReviews)
+	appsGroup.Post("/:appId/reviews", reviews.CreateReview)
+	appsGroup.Get("/:appId/reviews/me", reviews.GetMyReview)
+	appsGroup.Put("/:appId/reviews/me", reviews.UpdateMyReview)
+	appsGroup.Delete("/:appId/reviews/me", reviews.DeleteMyReview)
+	appsGroup.Post("/:appId/reviews/:reviewId/helpful", reviews.MarkHelpful)
+	appsGroup.Delete("/:appId/reviews/:reviewId/helpful", reviews.UnmarkHelpful)
+	appsGroup.Post("/:appId/reviews/:reviewId/report", reviews.ReportReview)
+	appsGroup.Get("/:appId/permissions", permissions.GetAppPermissions)
+	appsGroup.Post("/:appId/permissions", permissions.GrantPermissions)
+	appsGroup.Delete("/:appId/permissions", permissions.RevokeAllPermissions)
+	appsGroup.Delete("/:appId/permissions/:permission", permissions.RevokePermission)
 
 	// ==================== DEVELOPER (protected) ====================
 	devGroup := api.Group("/developer", auth)
 	devGroup.Get("/apps", developer.ListMyApps)
 	devGroup.Post("/apps", developer.CreateApp)
+	devGroup.Get("/apps/deleted", developer.ListDeletedApps)
 	devGroup.Get("/apps/:appId", developer.GetApp)
 	devGroup.Put("/apps/:appId", developer.UpdateApp)
 	devGroup.Delete("/apps/:appId", developer.DeleteApp)
+	devGroup.Post("/apps/:appId/restore", developer.RestoreApp)
+	devGroup.Post("/apps/:appId/unpublish", developer.UnpublishApp)
+	devGroup.Post("/apps/:appId/republish", developer.RepublishApp)
 	devGroup.Post("/apps/:appId/api-keys", developer.GenerateAPIKey)
 	devGroup.Get("/apps/:appId/api-keys", developer.ListAPICredentials)
 	devGroup.Delete("/apps/:appId/api-keys/:keyId", developer.RevokeAPIKey)
 	devGroup.Put("/apps/:appId/webhook", developer.UpdateWebhook)
 	devGroup.Get("/apps/:appId/welcome-message", developer.GetWelcomeMessage)
 	devGroup.Put("/apps/:appId/welcome-message", developer.UpdateWelcomeMessage)
+	devGroup.Get("/apps/:appId/invoices", payments.ListAppInvoices)
+	devGroup.Post("/apps/:appId/invoices/:invoiceId/refund", payments.RefundInvoice)
+	devGroup.Get("/apps/:appId/releases", releases.ListReleases)
+	devGroup.Post("/apps/:appId/releases", releases.CreateRelease)
+	devGroup.Get("/apps/:appId/releases/:releaseId", releases.GetRelease)
+	devGroup.Put("/apps/:appId/releases/:releaseId", releases.UpdateRelease)
+	devGroup.Delete("/apps/:appId/releases/:releaseId", releases.DeleteRelease)
+	devGroup.Post("/apps/:appId/releases/:releaseId/submit", releases.SubmitRelease)
+	devGroup.Post("/apps/:appId/releases/:releaseId/rollback", releases.RollbackRelease)
+	devGroup.Get("/apps/:appId/analytics", analytics.GetAppAnalytics)
+	devGroup.Post("/apps/:appId/news", news.CreatePost)
+	devGroup.Put("/apps/:appId/reviews/:reviewId/reply", reviews.ReplyToReview)
+	devGroup.Delete("/apps/:appId/reviews/:reviewId/reply", reviews.DeleteReply)
+	devGroup.Get("/apps/:appId/transfer", transfers.GetAppTransfer)
+	devGroup.Post("/apps/:appId/transfer", transfers.InitiateTransfer)
+	devGroup.Delete("/apps/:appId/transfer", transfers.CancelTransfer)
+	devGroup.Get("/transfers", transfers.ListTransfers)
+	devGroup.Post("/transfers/:transferId/accept", transfers.AcceptTransfer)
+	devGroup.Post("/transfers/:transferId/decline", transfers.DeclineTransfer)
+	devGroup.Get("/verification", verification.GetMyVerification)
+	devGroup.Post("/verification", verification.RequestVerification)
+	devGroup.Post("/verification/check", verification.CheckDomain)
+	devGroup.Delete("/verification", verification.CancelVerification)
+	devGroup.Get("/earnings", earnings.GetEarnings)
+	devGroup.Get("/earnings/ledger", earnings.GetLedger)
+	devGroup.Get("/payouts", earnings.ListPayouts)
+	devGroup.Post("/payouts", earnings.RequestPayout)
+	devGroup.Get("/payouts/:payoutId", earnings.GetPayout)
+
+	// ==================== ORGANIZATIONS (protected) ====================
+	orgsGroup := api.Group("/orgs", auth)
+	orgsGroup.Get("/", orgs.ListMyOrganizations)
+	orgsGroup.Post("/", orgs.CreateOrganization)
+	orgsGroup.Get("/invites", orgs.ListMyInvites)
+	orgsGroup.Post("/invites/:inviteId/accept", orgs.AcceptInvite)
+	orgsGroup.Post("/invites/:inviteId/decline", orgs.DeclineInvite)
+	orgsGroup.Get("/:orgId", orgs.GetOrganization)
+	orgsGroup.Patch("/:orgId", orgs.UpdateOrganization)
+	orgsGroup.Delete("/:orgId", orgs.DeleteOrganization)
+	orgsGroup.Post("/:orgId/apps", orgs.AddApp)
+	orgsGroup.Put("/:orgId/members/:userId", orgs.UpdateMember)
+	orgsGroup.Delete("/:orgId/members/:userId", orgs.RemoveMember)
+	orgsGroup.Get("/:orgId/invites", orgs.ListInvites)
+	orgsGroup.Post("/:orgId/invites", orgs.InviteMember)
+	orgsGroup.Delete("/:orgId/invites/:inviteId", orgs.RevokeInvite)
 
 	// ==================== UPLOAD (protected) ====================
 	api.Post("/upload", auth, developer.Upload)
@@ -94,6 +191,12 @@ func SetupAPI(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	manaGroup.Post("/gift", wallet.GiftMana)
 	manaGroup.Get("/networks", wallet.GetNetworks)
 
+	// ==================== PAYMENTS (protected) ====================
+	paymentsGroup := api.Group("/payments", auth)
+	paymentsGroup.Get("/invoices", payments.ListMyInvoices)
+	paymentsGroup.Get("/invoices/:invoiceId", payments.GetInvoice)
+	paymentsGroup.Post("/invoices/:invoiceId/pay", payments.PayInvoice)
+
 	// ==================== NOTIFICATIONS (protected) ====================
 	notifGroup := api.Group("/notifications", auth)
 	notifGroup.Post("/push-token", notifications.RegisterPushToken)
@@ -107,7 +210,7 @@ func SetupAPI(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	newsGroup := api.Group("/news", auth)
 	newsGroup.Get("/feed", news.GetFeed)
 	newsGroup.Post("/:postId/like", news.LikePost)
-	newsGroup.Post("/:postId/share", news.SharePost)
+	newsGroup.Post("/:postId/share", share.SharePost)
 	newsGroup.Get("/:postId/comments", news.GetComments)
 	newsGroup.Post("/:postId/comments", news.PostComment)
 	newsGroup.Post("/", news.CreatePost)
@@ -135,6 +238,50 @@ func SetupAPI(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	legalGroup.Get("/terms", support.GetTerms)
 	legalGroup.Get("/privacy", support.GetPrivacy)
 
+	// ==================== REPORTS (protected) ====================
+	api.Post("/reports", auth, reports.CreateReport)
+
+	// ==================== ADMIN ====================
+	adminGroup := api.Group("/admin", auth, admin)
+	adminGroup.Get("/categories", categories.AdminListCategories)
+	adminGroup.Post("/categories", categories.AdminCreateCategory)
+	adminGroup.Put("/categories/order", categories.AdminReorderCategories)
+	adminGroup.Put("/categories/:categoryId", categories.AdminUpdateCategory)
+	adminGroup.Delete("/categories/:categoryId", categories.AdminDeleteCategory)
+	adminGroup.Post("/categories/:categoryId/restore", categories.AdminRestoreCategory)
+	adminGroup.Post("/categories/:categoryId/merge", categories.AdminMergeCategory)
+	adminGroup.Get("/verifications", verification.AdminListVerifications)
+	adminGroup.Post("/verifications/:verificationId/approve", verification.AdminApproveVerification)
+	adminGroup.Post("/verifications/:verificationId/reject", verification.AdminRejectVerification)
+	adminGroup.Post("/verifications/:verificationId/revoke", verification.AdminRevokeVerification)
+	adminGroup.Get("/releases", releases.AdminListReleases)
+	adminGroup.Post("/releases/:releaseId/approve", releases.AdminApproveRelease)
+	adminGroup.Post("/releases/:releaseId/reject", releases.AdminRejectRelease)
+	adminGroup.Get("/moderation/queue", moderation.AdminModerationQueue)
+	adminGroup.Get("/moderation/reasons", moderation.AdminModerationReasons)
+	adminGroup.Get("/moderation/history", moderation.AdminModerationHistory)
+	adminGroup.Post("/moderation/:releaseId/claim", moderation.AdminClaimRelease)
+	adminGroup.Delete("/moderation/:releaseId/claim", moderation.AdminUnclaimRelease)
+	adminGroup.Post("/moderation/:releaseId/recheck", moderation.AdminRecheckRelease)
+	adminGroup.Get("/admins", adminUsers.AdminListAdmins)
+	adminGroup.Put("/users/:userId/role", adminUsers.AdminSetUserRole)
+	adminGroup.Get("/users/sanctioned", adminUsers.AdminListSanctionedUsers)
+	adminGroup.Post("/users/:userId/sanction", adminUsers.AdminSanctionUser)
+	adminGroup.Delete("/users/:userId/sanction", adminUsers.AdminLiftSanction)
+	adminGroup.Delete("/users/:userId/sessions", adminUsers.AdminRevokeUserSessions)
+	adminGroup.Get("/audit", audit.AdminListAuditLogs)
+	adminGroup.Get("/reports", reports.AdminListReports)
+	adminGroup.Get("/reports/:targetType/:targetId", reports.AdminGetReports)
+	adminGroup.Post("/reports/:targetType/:targetId/resolve", reports.AdminResolveReports)
+	adminGroup.Post("/apps/:appId/reinstate", reports.AdminReinstateApp)
+	adminGroup.Get("/payouts", earnings.AdminListPayouts)
+	adminGroup.Post("/payouts/:payoutId/approve", earnings.AdminApprovePayout)
+	adminGroup.Post("/payouts/:payoutId/reject", earnings.AdminRejectPayout)
+	adminGroup.Post("/payouts/:payoutId/paid", earnings.AdminMarkPayoutPaid)
+
+	// ==================== SHARE LINKS (public) ====================
+	api.Get("/share/:code", middleware.AuthOptional(cfg.JWTSecret), share.ResolveShare)
+
 	// ==================== I18N (public) ====================
 	api.Get("/i18n/languages", support.GetLanguages)
 
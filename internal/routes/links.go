@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/fasad/solanafon-back/internal/config"
+	"github.com/fasad/solanafon-back/internal/handlers"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// SetupLinks registers short public links served outside /api
+func SetupLinks(app fiber.Router, db *gorm.DB, cfg *config.Config) {
+	share := handlers.NewShareHandler(db, cfg)
+	app.Get("/s/:code", share.LandingPage)
+}
@@ -18,7 +18,7 @@ func Setup(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	devStudioHandler := handlers.NewDevStudioHandler(db)
 
 	// Auth middleware
-	authMiddleware := middleware.AuthRequired(cfg.JWTSecret)
+	authMiddleware := middleware.AuthRequired(db, cfg.JWTSecret)
 
 	// ==================== PUBLIC ROUTES ====================
 
@@ -94,4 +94,6 @@ func Setup(api fiber.Router, db *gorm.DB, cfg *config.Config) {
 	bot.Get("/getWebhookInfo", botHandler.GetWebhookInfo)  // Get webhook info
 	bot.Post("/setMyCommands", botHandler.SetCommands)     // Set bot commands
 	bot.Get("/getMyCommands", botHandler.GetCommands)      // Get bot commands
+	bot.Post("/sendInvoice", botHandler.SendInvoice)       // Send Mana Points invoice to user
+	bot.Post("/refundPayment", botHandler.RefundPayment)   // Refund a paid invoice
 }
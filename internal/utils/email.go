@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"mime"
 	"net/smtp"
 )
 
@@ -39,3 +40,32 @@ Solafon Team
 
 	return smtp.SendMail(addr, auth, from, []string{to}, message)
 }
+
+func SendOrgInviteEmail(to, orgName, role, inviter string, config EmailConfig) error {
+	from := config.User
+	subject := mime.QEncoding.Encode("utf-8", fmt.Sprintf("Join %s on Solafon", orgName))
+	body := fmt.Sprintf(`
+Hello,
+
+%s invited you to join the %s team on Solafon as %s.
+
+Sign in to Solafon with this email address and open Developer > Invitations to accept.
+The invitation expires in 7 days.
+
+If you don't know what this is about, you can ignore this email.
+
+Best regards,
+Solafon Team
+`, inviter, orgName, role)
+
+	message := []byte(fmt.Sprintf("From: %s\r\n"+
+		"To: %s\r\n"+
+		"Subject: %s\r\n"+
+		"\r\n"+
+		"%s\r\n", from, to, subject, body))
+
+	auth := smtp.PlainAuth("", config.User, config.Password, config.Host)
+	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+
+	return smtp.SendMail(addr, auth, from, []string{to}, message)
+}